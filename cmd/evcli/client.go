@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/exograd/eventline/pkg/eventline"
 	"go.n16f.net/uuid"
@@ -190,6 +191,17 @@ func (c *Client) CreateProject(newProject *eventline.NewProject) (*eventline.Pro
 	return &project, nil
 }
 
+func (c *Client) CloneProject(id uuid.UUID, data *eventline.ProjectCloningData) (*eventline.Project, error) {
+	uri := NewURL("projects", "id", id.String(), "clone")
+
+	var project eventline.Project
+	if err := c.SendRequest("POST", uri, data, &project); err != nil {
+		return nil, err
+	}
+
+	return &project, nil
+}
+
 func (c *Client) DeleteProject(id uuid.UUID) error {
 	uri := NewURL("projects", "id", id.String())
 
@@ -209,6 +221,76 @@ func (c *Client) ReplayEvent(id string) (*eventline.Event, error) {
 	return &event, nil
 }
 
+func (c *Client) FetchEvents(query url.Values, limit int) (eventline.Events, error) {
+	var events eventline.Events
+
+	cursor := eventline.Cursor{Size: 20, Order: eventline.OrderDesc}
+
+	for {
+		var page Page[*eventline.Event]
+
+		pageQuery := cursor.Query()
+		for name, values := range query {
+			pageQuery[name] = values
+		}
+
+		uri := NewURL("events")
+		uri.RawQuery = pageQuery.Encode()
+
+		err := c.SendRequest("GET", uri, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, page.Elements...)
+
+		if limit > 0 && len(events) >= limit {
+			events = events[:limit]
+			break
+		}
+
+		if page.Next == nil {
+			break
+		}
+
+		cursor = *page.Next
+	}
+
+	return events, nil
+}
+
+func (c *Client) ReplayEvents(selection *eventline.EventReplaySelection, dryRun bool) (*eventline.EventReplayResult, error) {
+	uri := NewURL("events", "replay")
+
+	query := url.Values{}
+	if dryRun {
+		query.Add("dry-run", "")
+	}
+	uri.RawQuery = query.Encode()
+
+	var result eventline.EventReplayResult
+
+	err := c.SendRequest("POST", uri, selection, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) SendEvent(ne *eventline.NewCustomEvent) (eventline.Events, error) {
+	var events eventline.Events
+
+	uri := NewURL("events")
+
+	err := c.SendRequest("POST", uri, ne, &events)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
 func (c *Client) FetchJobByName(name string) (*eventline.Job, error) {
 	uri := NewURL("jobs", "name", name)
 
@@ -250,6 +332,47 @@ func (c *Client) FetchJobs() (eventline.Jobs, error) {
 	return jobs, nil
 }
 
+func (c *Client) FetchJobVersions(id string) (eventline.JobVersions, error) {
+	var versions eventline.JobVersions
+
+	cursor := eventline.Cursor{Size: 20}
+
+	for {
+		var page Page[*eventline.JobVersion]
+
+		uri := NewURL("jobs", "id", id, "versions")
+		uri.RawQuery = cursor.Query().Encode()
+
+		err := c.SendRequest("GET", uri, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, page.Elements...)
+
+		if page.Next == nil {
+			break
+		}
+
+		cursor = *page.Next
+	}
+
+	return versions, nil
+}
+
+func (c *Client) RollbackJob(id string, version int) (*eventline.Job, error) {
+	uri := NewURL("jobs", "id", id, "versions", strconv.Itoa(version),
+		"rollback")
+
+	var job eventline.Job
+
+	if err := c.SendRequest("POST", uri, nil, &job); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
 func (c *Client) DeployJob(spec *eventline.JobSpec, dryRun bool) (*eventline.Job, error) {
 	uri := NewURL("jobs", "name", spec.Name)
 
@@ -303,6 +426,98 @@ func (c *Client) DeployJobs(specs []*eventline.JobSpec, dryRun bool) ([]*eventli
 	}
 }
 
+func (c *Client) DeployStepTemplate(spec *eventline.StepTemplateSpec, dryRun bool) (*eventline.StepTemplate, error) {
+	uri := NewURL("step_templates", "name", spec.Name)
+
+	query := url.Values{}
+	if dryRun {
+		query.Add("dry-run", "")
+	}
+	uri.RawQuery = query.Encode()
+
+	if dryRun {
+		if err := c.SendRequest("PUT", uri, spec, nil); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+
+	var template eventline.StepTemplate
+
+	if err := c.SendRequest("PUT", uri, spec, &template); err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+func (c *Client) SyncProject(spec *eventline.ProjectSyncSpec, dryRun bool) (*eventline.ProjectSyncPlan, error) {
+	uri := NewURL("sync")
+
+	query := url.Values{}
+	if dryRun {
+		query.Add("dry-run", "")
+	}
+	uri.RawQuery = query.Encode()
+
+	var plan eventline.ProjectSyncPlan
+
+	if err := c.SendRequest("POST", uri, spec, &plan); err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+func (c *Client) ExportProject(options *eventline.ProjectExportOptions) (*eventline.ProjectArchive, error) {
+	uri := NewURL("export")
+
+	var archive eventline.ProjectArchive
+
+	if err := c.SendRequest("POST", uri, options, &archive); err != nil {
+		return nil, err
+	}
+
+	return &archive, nil
+}
+
+func (c *Client) ImportProject(options *eventline.ProjectImportOptions, dryRun bool) (*eventline.ProjectSyncPlan, error) {
+	uri := NewURL("import")
+
+	query := url.Values{}
+	if dryRun {
+		query.Add("dry-run", "")
+	}
+	uri.RawQuery = query.Encode()
+
+	var plan eventline.ProjectSyncPlan
+
+	if err := c.SendRequest("POST", uri, options, &plan); err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+func (c *Client) PromoteJobs(data *eventline.JobPromotionData, dryRun bool) (*eventline.ProjectSyncPlan, error) {
+	uri := NewURL("promote")
+
+	query := url.Values{}
+	if dryRun {
+		query.Add("dry-run", "")
+	}
+	uri.RawQuery = query.Encode()
+
+	var plan eventline.ProjectSyncPlan
+
+	if err := c.SendRequest("POST", uri, data, &plan); err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
 func (c *Client) RenameJob(id string, data *eventline.JobRenamingData) error {
 	uri := NewURL("jobs", "id", id, "rename")
 
@@ -327,6 +542,19 @@ func (c *Client) ExecuteJob(id string, input *eventline.JobExecutionInput) (*eve
 	return &jobExecution, nil
 }
 
+func (c *Client) FetchMatrixExecution(id uuid.UUID) (*eventline.MatrixExecution, error) {
+	uri := NewURL("matrix_executions", "id", id.String())
+
+	var me eventline.MatrixExecution
+
+	err := c.SendRequest("GET", uri, nil, &me)
+	if err != nil {
+		return nil, err
+	}
+
+	return &me, nil
+}
+
 func (c *Client) FetchJobExecution(id uuid.UUID) (*eventline.JobExecution, error) {
 	uri := NewURL("job_executions", "id", id.String())
 
@@ -346,10 +574,49 @@ func (c *Client) AbortJobExecution(id uuid.UUID) error {
 	return c.SendRequest("POST", uri, nil, nil)
 }
 
-func (c *Client) RestartJobExecution(id uuid.UUID) error {
+func (c *Client) FetchDelayedJobExecutions(jobId *uuid.UUID) (eventline.JobExecutions, error) {
+	var jes eventline.JobExecutions
+
+	cursor := eventline.Cursor{Size: 20, Sort: "scheduled_time"}
+
+	for {
+		var page Page[*eventline.JobExecution]
+
+		query := cursor.Query()
+		query.Set("delayed", "")
+		if jobId != nil {
+			query.Set("job_id", jobId.String())
+		}
+
+		uri := NewURL("job_executions")
+		uri.RawQuery = query.Encode()
+
+		err := c.SendRequest("GET", uri, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		jes = append(jes, page.Elements...)
+
+		if page.Next == nil {
+			break
+		}
+
+		cursor = *page.Next
+	}
+
+	return jes, nil
+}
+
+func (c *Client) RestartJobExecution(id uuid.UUID, input *eventline.JobExecutionInput) error {
 	uri := NewURL("job_executions", "id", id.String(), "restart")
 
-	return c.SendRequest("POST", uri, nil, nil)
+	var body interface{}
+	if input != nil {
+		body = input
+	}
+
+	return c.SendRequest("POST", uri, body, nil)
 }
 
 func (c *Client) FetchIdentityByName(name string) (*eventline.RawIdentity, error) {
@@ -1,19 +1,193 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/exograd/eventline/pkg/eventline"
 	"go.n16f.net/program"
+	"go.n16f.net/uuid"
 )
 
 func addEventCommands() {
 	var c *program.Command
 
+	// list-events
+	c = p.AddCommand("list-events", "list and search events",
+		cmdListEvents)
+
+	c.AddOption("c", "connector", "name", "",
+		"only list events of a specific connector")
+	c.AddOption("e", "event", "name", "",
+		"only list events with a specific name")
+	c.AddOption("j", "job", "name", "",
+		"only list events of a specific job")
+	c.AddFlag("", "processed", "only list processed events")
+	c.AddFlag("", "pending", "only list events which have not been processed")
+	c.AddOption("", "start", "datetime", "",
+		"only list events which happened at or after a date or datetime")
+	c.AddOption("", "end", "datetime", "",
+		"only list events which happened before a date or datetime")
+	c.AddOption("l", "limit", "n", "50",
+		"the maximum number of events to list, 0 for no limit")
+
+	c.AddTrailingArgument("filter",
+		"a filter on event data passed as <path>=<value>, <path>!=<value>, "+
+			"<path>=~<regexp> or <path>!~<regexp>")
+
 	// replay-event
 	c = p.AddCommand("replay-event", "replay an existing event",
 		cmdReplayEvent)
 
 	c.AddArgument("event-id", "the identifier of the event")
+
+	// replay-events
+	c = p.AddCommand("replay-events", "replay all events matching criteria",
+		cmdReplayEvents)
+
+	c.AddFlag("n", "dry-run", "count matching events but do not replay them")
+	c.AddOption("c", "connector", "name", "",
+		"only replay events of a specific connector")
+	c.AddOption("e", "event", "name", "",
+		"only replay events with a specific name")
+	c.AddOption("j", "job", "name", "",
+		"only replay events of a specific job")
+	c.AddOption("", "start", "datetime", "",
+		"only replay events which happened at or after a date or datetime")
+	c.AddOption("", "end", "datetime", "",
+		"only replay events which happened before a date or datetime")
+	c.AddFlag("", "failed",
+		"only replay failed events which have not been replayed yet")
+
+	c.AddTrailingArgument("filter",
+		"a filter on event data passed as <path>=<value>, <path>!=<value>, "+
+			"<path>=~<regexp> or <path>!~<regexp>")
+
+	// send-event
+	c = p.AddCommand("send-event",
+		"send a custom event to all jobs subscribed to it",
+		cmdSendEvent)
+
+	c.AddArgument("name", "the name of the event")
+	c.AddTrailingArgument("field",
+		"a data field passed as <key>=<string> or <key>:=<json-value>")
+}
+
+func cmdListEvents(p *program.Program) {
+	app.IdentifyCurrentProject()
+
+	query := make(url.Values)
+
+	if connector := p.OptionValue("connector"); connector != "" {
+		query.Set("connector", connector)
+	}
+
+	if name := p.OptionValue("event"); name != "" {
+		query.Set("name", name)
+	}
+
+	if jobName := p.OptionValue("job"); jobName != "" {
+		job, err := app.Client.FetchJobByName(jobName)
+		if err != nil {
+			p.Fatal("cannot fetch job: %v", err)
+		}
+
+		query.Set("job_id", job.Id.String())
+	}
+
+	if p.IsOptionSet("processed") && p.IsOptionSet("pending") {
+		p.Fatal("the --processed and --pending options cannot be used " +
+			"together")
+	}
+
+	if p.IsOptionSet("processed") {
+		query.Set("processed", "true")
+	} else if p.IsOptionSet("pending") {
+		query.Set("processed", "false")
+	}
+
+	for _, name := range []string{"start", "end"} {
+		if s := p.OptionValue(name); s != "" {
+			t, err := parseEventSearchTime(s)
+			if err != nil {
+				p.Fatal("invalid --%s value: %v", name, err)
+			}
+
+			query.Set(name, t.Format(time.RFC3339))
+		}
+	}
+
+	for _, s := range p.TrailingArgumentValues("filter") {
+		var filter eventline.Filter
+		if err := filter.Parse(s); err != nil {
+			p.Fatal("invalid filter %q: %v", s, err)
+		}
+
+		query.Add("filter", s)
+	}
+
+	limitString := p.OptionValue("limit")
+	limit, err := strconv.Atoi(limitString)
+	if err != nil || limit < 0 {
+		p.Fatal("invalid limit %q", limitString)
+	}
+
+	events, err := app.Client.FetchEvents(query, limit)
+	if err != nil {
+		p.Fatal("cannot fetch events: %v", err)
+	}
+
+	jobs, err := app.Client.FetchJobs()
+	if err != nil {
+		p.Fatal("cannot fetch jobs: %v", err)
+	}
+
+	jobNames := make(map[uuid.UUID]string)
+	for _, job := range jobs {
+		jobNames[job.Id] = job.Spec.Name
+	}
+
+	header := []string{"id", "event time", "event", "jobs", "processed"}
+	table := NewTable(header)
+
+	for _, event := range events {
+		names := make([]string, len(event.JobIds))
+		for i, id := range event.JobIds {
+			names[i] = jobNames[id]
+		}
+
+		row := []interface{}{
+			event.Id,
+			event.EventTime,
+			event.Connector + "/" + event.Name,
+			strings.Join(names, ", "),
+			event.Processed,
+		}
+
+		table.AddRow(row)
+	}
+
+	table.Write()
+}
+
+// Accept both dates and datetimes, dates being interpreted as midnight UTC
+// so that "--start 2026-01-01 --end 2026-01-02" selects a whole day.
+func parseEventSearchTime(s string) (time.Time, error) {
+	if t, err := time.Parse(eventline.ParameterDateFormat, s); err == nil {
+		return t, nil
+	}
+
+	t, err := eventline.ParseParameterDatetime(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a date nor a datetime",
+			s)
+	}
+
+	return t, nil
 }
 
 func cmdReplayEvent(p *program.Program) {
@@ -30,3 +204,131 @@ func cmdReplayEvent(p *program.Program) {
 
 	fmt.Printf("%s\n", event.Id)
 }
+
+func cmdReplayEvents(p *program.Program) {
+	app.IdentifyCurrentProject()
+
+	dryRun := p.IsOptionSet("dry-run")
+
+	selection := eventline.EventReplaySelection{
+		Connector: p.OptionValue("connector"),
+		Name:      p.OptionValue("event"),
+		Failed:    p.IsOptionSet("failed"),
+	}
+
+	if jobName := p.OptionValue("job"); jobName != "" {
+		job, err := app.Client.FetchJobByName(jobName)
+		if err != nil {
+			p.Fatal("cannot fetch job: %v", err)
+		}
+
+		selection.JobId = &job.Id
+	}
+
+	if s := p.OptionValue("start"); s != "" {
+		t, err := parseEventSearchTime(s)
+		if err != nil {
+			p.Fatal("invalid --start value: %v", err)
+		}
+
+		selection.StartTime = &t
+	}
+
+	if s := p.OptionValue("end"); s != "" {
+		t, err := parseEventSearchTime(s)
+		if err != nil {
+			p.Fatal("invalid --end value: %v", err)
+		}
+
+		selection.EndTime = &t
+	}
+
+	for _, s := range p.TrailingArgumentValues("filter") {
+		var filter eventline.Filter
+		if err := filter.Parse(s); err != nil {
+			p.Fatal("invalid filter %q: %v", s, err)
+		}
+
+		selection.Filters = append(selection.Filters, &filter)
+	}
+
+	result, err := app.Client.ReplayEvents(&selection, dryRun)
+	if err != nil {
+		p.Fatal("cannot replay events: %v", err)
+	}
+
+	if dryRun {
+		p.Info("%d events would be replayed", result.Count)
+		return
+	}
+
+	p.Info("%d events replayed", result.Count)
+
+	for _, event := range result.Events {
+		fmt.Printf("%s\n", event.Id)
+	}
+}
+
+func cmdSendEvent(p *program.Program) {
+	app.IdentifyCurrentProject()
+
+	ne := eventline.NewCustomEvent{
+		Name: p.ArgumentValue("name"),
+	}
+
+	fieldStrings := p.TrailingArgumentValues("field")
+	if len(fieldStrings) > 0 {
+		ne.Data = make(map[string]interface{})
+	}
+
+	for _, s := range fieldStrings {
+		key, value, err := parseEventField(s)
+		if err != nil {
+			p.Fatal("invalid field: %v", err)
+		}
+
+		ne.Data[key] = value
+	}
+
+	events, err := app.Client.SendEvent(&ne)
+	if err != nil {
+		p.Fatal("cannot send event: %v", err)
+	}
+
+	if len(events) == 0 {
+		p.Info("no job subscribed to event %q", ne.Name)
+		return
+	}
+
+	for _, event := range events {
+		p.Info("event %s created for %d job(s)", event.Id,
+			len(event.JobIds))
+
+		fmt.Printf("%s\n", event.Id)
+	}
+}
+
+func parseEventField(s string) (string, interface{}, error) {
+	idx := strings.IndexByte(s, '=')
+	if idx <= 0 {
+		return "", nil, fmt.Errorf("invalid field format %q", s)
+	}
+
+	// "<key>:=<value>" is used for JSON values
+	if s[idx-1] == ':' {
+		key := s[:idx-1]
+		if key == "" {
+			return "", nil, fmt.Errorf("invalid field format %q", s)
+		}
+
+		var value interface{}
+		if err := json.Unmarshal([]byte(s[idx+1:]), &value); err != nil {
+			return "", nil, fmt.Errorf("invalid json value for field %q: %w",
+				key, err)
+		}
+
+		return key, value, nil
+	}
+
+	return s[:idx], s[idx+1:], nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEventField(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		s     string
+		key   string
+		value interface{}
+	}{
+		{"a=b", "a", "b"},
+		{"a=", "a", ""},
+		{"a=b=c", "a", "b=c"},
+		{"a=42", "a", "42"},
+		{"a:=42", "a", 42.0},
+		{"a:=true", "a", true},
+		{`a:=["x", 1]`, "a", []interface{}{"x", 1.0}},
+		{`a:={"b": null}`, "a", map[string]interface{}{"b": nil}},
+	}
+
+	for _, test := range tests {
+		key, value, err := parseEventField(test.s)
+		if assert.NoError(err, test.s) {
+			assert.Equal(test.key, key, test.s)
+			assert.Equal(test.value, value, test.s)
+		}
+	}
+
+	for _, s := range []string{"a", "=b", ":=1", "a:=foo"} {
+		_, _, err := parseEventField(s)
+		assert.Error(err, s)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/exograd/eventline/pkg/eventline"
 	"go.n16f.net/program"
 	"go.n16f.net/uuid"
 )
@@ -8,6 +9,14 @@ import (
 func addJobExecutionCommands() {
 	var c *program.Command
 
+	// list-delayed-job-executions
+	c = p.AddCommand("list-delayed-job-executions",
+		"list job executions scheduled in the future.",
+		cmdListDelayedJobExecutions)
+
+	c.AddOption("j", "job", "name", "",
+		"only list executions of a specific job")
+
 	// abort-job-execution
 	c = p.AddCommand("abort-job-execution",
 		"abort a created or started job execution.",
@@ -21,6 +30,43 @@ func addJobExecutionCommands() {
 		cmdRestartJobExecution)
 
 	c.AddArgument("job-execution-id", "the identifier of the job execution")
+	c.AddTrailingArgument("parameter",
+		"a secret parameter passed to the command as <name>=<value>")
+}
+
+func cmdListDelayedJobExecutions(p *program.Program) {
+	app.IdentifyCurrentProject()
+
+	var jobId *uuid.UUID
+
+	if jobName := p.OptionValue("job"); jobName != "" {
+		job, err := app.Client.FetchJobByName(jobName)
+		if err != nil {
+			p.Fatal("cannot fetch job: %v", err)
+		}
+
+		jobId = &job.Id
+	}
+
+	jes, err := app.Client.FetchDelayedJobExecutions(jobId)
+	if err != nil {
+		p.Fatal("cannot fetch job executions: %v", err)
+	}
+
+	header := []string{"id", "job", "scheduled time"}
+	table := NewTable(header)
+
+	for _, je := range jes {
+		row := []interface{}{
+			je.Id,
+			je.JobSpec.Name,
+			je.ScheduledTime,
+		}
+
+		table.AddRow(row)
+	}
+
+	table.Write()
 }
 
 func cmdAbortJobExecution(p *program.Program) {
@@ -50,7 +96,34 @@ func cmdRestartJobExecution(p *program.Program) {
 		p.Fatal("invalid id %q: %w", jeIdString, err)
 	}
 
-	if err := app.Client.RestartJobExecution(jeId); err != nil {
+	paramStrings := p.TrailingArgumentValues("parameter")
+
+	// Secret parameter values are not reused when a job execution is
+	// restarted and must be provided again.
+	var input *eventline.JobExecutionInput
+
+	if len(paramStrings) > 0 {
+		je, err := app.Client.FetchJobExecution(jeId)
+		if err != nil {
+			p.Fatal("cannot fetch job execution: %v", err)
+		}
+
+		input = &eventline.JobExecutionInput{
+			Parameters: make(map[string]interface{}),
+		}
+
+		for _, s := range paramStrings {
+			name, value, err := parseCommandParameter(s,
+				je.JobSpec.Parameters)
+			if err != nil {
+				p.Fatal("%v", err)
+			}
+
+			input.Parameters[name] = value
+		}
+	}
+
+	if err := app.Client.RestartJobExecution(jeId, input); err != nil {
 		p.Fatal("cannot restart job execution: %v", err)
 	}
 
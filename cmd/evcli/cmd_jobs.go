@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -13,6 +14,7 @@ import (
 	"github.com/exograd/eventline/pkg/eventline"
 	"github.com/exograd/eventline/pkg/utils"
 	"go.n16f.net/program"
+	"go.n16f.net/uuid"
 )
 
 func addJobCommands() {
@@ -50,6 +52,17 @@ func addJobCommands() {
 	c.AddTrailingArgument("path",
 		"the path of a job specification file or directory")
 
+	// deploy-template
+	c = p.AddCommand("deploy-template",
+		"create or update a step template from a specification file",
+		cmdDeployTemplate)
+
+	c.AddFlag("n", "dry-run",
+		"validate the step template and the jobs using it but do not "+
+			"deploy it")
+
+	c.AddArgument("path", "the path of the step template specification file")
+
 	// delete-job
 	c = p.AddCommand("delete-job", "delete a job",
 		cmdDeleteJob)
@@ -66,6 +79,20 @@ func addJobCommands() {
 	c.AddOption("d", "description", "text", "",
 		"the new description of the job")
 
+	// list-job-versions
+	c = p.AddCommand("list-job-versions", "list the versions of a job",
+		cmdListJobVersions)
+
+	c.AddArgument("name", "the name of the job")
+
+	// rollback-job
+	c = p.AddCommand("rollback-job",
+		"restore the specification of a previous version of a job",
+		cmdRollbackJob)
+
+	c.AddArgument("name", "the name of the job")
+	c.AddArgument("version", "the version to restore")
+
 	// describe-job
 	c = p.AddCommand("describe-job", "print information about a job",
 		cmdDescribeJob)
@@ -83,6 +110,10 @@ func addJobCommands() {
 	c.AddFlag("w", "wait", "wait for execution to finish")
 	c.AddFlag("f", "fail",
 		"exit with status 1 if execution does not complete successfully")
+	c.AddOption("", "at", "datetime", "",
+		"the RFC 3339 datetime to execute the job at")
+	c.AddOption("", "delay", "duration", "",
+		"a delay after which the job is executed (e.g. 30m or 2h)")
 }
 
 func cmdListJobs(p *program.Program) {
@@ -179,6 +210,49 @@ func cmdDeployJob(p *program.Program) {
 	}
 }
 
+func cmdDeployTemplate(p *program.Program) {
+	app.IdentifyCurrentProject()
+
+	filePath := p.ArgumentValue("path")
+	dryRun := p.IsOptionSet("dry-run")
+
+	spec, err := LoadStepTemplateFile(filePath)
+	if err != nil {
+		p.Fatal("cannot load step template file: %v", err)
+	}
+
+	template, err := app.Client.DeployStepTemplate(spec, dryRun)
+	if err != nil {
+		isRequestBodyError, verrs := IsInvalidRequestBodyError(err)
+
+		if isRequestBodyError {
+			if dryRun {
+				p.Error("invalid step template")
+			} else {
+				p.Error("cannot deploy step template")
+			}
+
+			for _, verr := range verrs {
+				p.Error("%s: %v", filePath, verr)
+			}
+
+			os.Exit(1)
+		} else {
+			if dryRun {
+				p.Fatal("invalid step template: %v", err)
+			} else {
+				p.Fatal("cannot deploy step template: %v", err)
+			}
+		}
+	}
+
+	if dryRun {
+		p.Info("step template validated successfully")
+	} else {
+		p.Info("step template %q deployed", template.Id)
+	}
+}
+
 func cmdDeployJobs(p *program.Program) {
 	app.IdentifyCurrentProject()
 
@@ -188,7 +262,7 @@ func cmdDeployJobs(p *program.Program) {
 
 	app.IdentifyCurrentProject()
 
-	filePaths, err := FindJobFiles(fileOrDirPaths, recursive)
+	filePaths, err := eventline.FindJobFiles(fileOrDirPaths, recursive)
 	if err != nil {
 		p.Fatal("%v", err)
 	}
@@ -306,6 +380,61 @@ func cmdRenameJob(p *program.Program) {
 	p.Info("job %q renamed", job.Id)
 }
 
+func cmdListJobVersions(p *program.Program) {
+	app.IdentifyCurrentProject()
+
+	name := p.ArgumentValue("name")
+
+	job, err := app.Client.FetchJobByName(name)
+	if err != nil {
+		p.Fatal("cannot fetch job: %v", err)
+	}
+
+	versions, err := app.Client.FetchJobVersions(job.Id.String())
+	if err != nil {
+		p.Fatal("cannot fetch job versions: %v", err)
+	}
+
+	header := []string{"version", "creation time", "source", "author"}
+	table := NewTable(header)
+
+	for _, v := range versions {
+		row := []interface{}{
+			v.Version,
+			v.CreationTime,
+			v.Source,
+			v.AccountName,
+		}
+
+		table.AddRow(row)
+	}
+
+	table.Write()
+}
+
+func cmdRollbackJob(p *program.Program) {
+	app.IdentifyCurrentProject()
+
+	name := p.ArgumentValue("name")
+	versionString := p.ArgumentValue("version")
+
+	version, err := strconv.Atoi(versionString)
+	if err != nil || version < 1 {
+		p.Fatal("invalid version %q", versionString)
+	}
+
+	job, err := app.Client.FetchJobByName(name)
+	if err != nil {
+		p.Fatal("cannot fetch job: %v", err)
+	}
+
+	if _, err := app.Client.RollbackJob(job.Id.String(), version); err != nil {
+		p.Fatal("cannot rollback job: %v", err)
+	}
+
+	p.Info("job %q rolled back to version %d", job.Id, version)
+}
+
 func cmdDescribeJob(p *program.Program) {
 	app.IdentifyCurrentProject()
 
@@ -380,6 +509,31 @@ func cmdExecuteJob(p *program.Program) {
 		Parameters: params,
 	}
 
+	if p.IsOptionSet("at") && p.IsOptionSet("delay") {
+		p.Fatal("the --at and --delay options cannot be used together")
+	}
+
+	if p.IsOptionSet("at") {
+		atString := p.OptionValue("at")
+
+		t, err := eventline.ParseParameterDatetime(atString)
+		if err != nil {
+			p.Fatal("invalid datetime %q", atString)
+		}
+
+		input.ScheduledTime = &t
+	} else if p.IsOptionSet("delay") {
+		delayString := p.OptionValue("delay")
+
+		delay, err := time.ParseDuration(delayString)
+		if err != nil || delay < 0 {
+			p.Fatal("invalid delay %q", delayString)
+		}
+
+		t := time.Now().UTC().Add(delay)
+		input.ScheduledTime = &t
+	}
+
 	jobExecution, err := app.Client.ExecuteJob(job.Id.String(), &input)
 	if err != nil {
 		var apiErr *APIError
@@ -405,10 +559,25 @@ func cmdExecuteJob(p *program.Program) {
 		}
 	}
 
+	if matrixId := jobExecution.MatrixId; matrixId != nil {
+		p.Info("matrix execution %q created", *matrixId)
+
+		if wait {
+			waitMatrixExecution(p, *matrixId, fail)
+		}
+
+		return
+	}
+
 	jeId := jobExecution.Id
 
 	p.Info("job execution %q created", jeId)
 
+	if jobExecution.Delayed() {
+		p.Info("job execution scheduled for %s",
+			jobExecution.ScheduledTime.Format(time.RFC3339))
+	}
+
 	if !wait {
 		return
 	}
@@ -454,6 +623,51 @@ func cmdExecuteJob(p *program.Program) {
 	}
 }
 
+func waitMatrixExecution(p *program.Program, matrixId uuid.UUID, fail bool) {
+	var lastStatus eventline.JobExecutionStatus
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	for {
+		select {
+		case <-sigChan:
+			return
+		case <-time.After(time.Second):
+		}
+
+		me, err := app.Client.FetchMatrixExecution(matrixId)
+		if err != nil {
+			p.Fatal("cannot fetch matrix execution: %v", err)
+		}
+
+		if me.Status != lastStatus {
+			p.Info("matrix execution %s", me.Status)
+			lastStatus = me.Status
+		}
+
+		if me.Status == eventline.JobExecutionStatusCreated ||
+			me.Status == eventline.JobExecutionStatusStarted {
+			continue
+		}
+
+		for _, je := range me.JobExecutions {
+			if je.Status == eventline.JobExecutionStatusFailed {
+				p.Info("job execution %q %s: %s", je.Id, je.Status,
+					je.FailureMessage)
+			} else {
+				p.Info("job execution %q %s", je.Id, je.Status)
+			}
+		}
+
+		if me.Status == eventline.JobExecutionStatusFailed && fail {
+			os.Exit(1)
+		}
+
+		break
+	}
+}
+
 func parseCommandParameters(ss []string, params eventline.Parameters) (map[string]interface{}, error) {
 	values := make(map[string]interface{})
 
@@ -500,39 +714,100 @@ func parseCommandParameter(s string, params eventline.Parameters) (string, inter
 		return "", nil, fmt.Errorf("unknown parameter %q", name)
 	}
 
+	value, err := parseCommandParameterValue(valueString, p)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return name, value, nil
+}
+
+func parseCommandParameterValue(s string, p *eventline.Parameter) (interface{}, error) {
 	var value interface{}
 
 	switch p.Type {
-	case "number":
-		var i int64
-		i, err := strconv.ParseInt(valueString, 10, 64)
+	case eventline.ParameterTypeNumber:
+		i, err := strconv.ParseInt(s, 10, 64)
 		if err == nil {
 			value = i
 		} else {
-			f, err := strconv.ParseFloat(valueString, 64)
+			f, err := strconv.ParseFloat(s, 64)
 			if err == nil {
 				value = f
 			} else {
-				return "", nil,
-					fmt.Errorf("invalid number value %q", valueString)
+				return nil, fmt.Errorf("invalid number value %q", s)
 			}
 		}
 
-	case "string":
-		value = valueString
+	case eventline.ParameterTypeInteger:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer value %q", s)
+		}
 
-	case "boolean":
-		valueString = strings.ToLower(valueString)
+		value = i
 
-		switch valueString {
+	case eventline.ParameterTypeString, eventline.ParameterTypeSecret,
+		eventline.ParameterTypeIdentity:
+		value = s
+
+	case eventline.ParameterTypeBoolean:
+		switch strings.ToLower(s) {
 		case "true":
 			value = true
 		case "false":
 			value = false
 		default:
-			return "", nil, fmt.Errorf("invalid boolean value %q", valueString)
+			return nil, fmt.Errorf("invalid boolean value %q", s)
 		}
+
+	case eventline.ParameterTypeDate:
+		if _, err := time.Parse(eventline.ParameterDateFormat, s); err != nil {
+			return nil, fmt.Errorf("invalid date value %q", s)
+		}
+
+		value = s
+
+	case eventline.ParameterTypeDatetime:
+		t, err := eventline.ParseParameterDatetime(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid datetime value %q", s)
+		}
+
+		value = t.Format(time.RFC3339)
+
+	case eventline.ParameterTypeJSON:
+		d := json.NewDecoder(strings.NewReader(s))
+		d.UseNumber()
+
+		if err := d.Decode(&value); err != nil {
+			return nil, fmt.Errorf("invalid json value %q: %w", s, err)
+		}
+
+	case eventline.ParameterTypeArray:
+		// Array elements are separated by commas; an empty string is an
+		// empty array.
+		elements := []interface{}{}
+
+		if s != "" {
+			elementParameter := p.ElementParameter()
+
+			for _, elementString := range strings.Split(s, ",") {
+				element, err := parseCommandParameterValue(
+					strings.TrimSpace(elementString), elementParameter)
+				if err != nil {
+					return nil, err
+				}
+
+				elements = append(elements, element)
+			}
+		}
+
+		value = elements
+
+	default:
+		return nil, fmt.Errorf("unsupported parameter type %q", p.Type)
 	}
 
-	return name, value, nil
+	return value, nil
 }
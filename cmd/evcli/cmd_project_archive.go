@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/program"
+)
+
+func addProjectArchiveCommands() {
+	var c *program.Command
+
+	// export-project
+	c = p.AddCommand("export-project",
+		"export jobs, identities and settings of the current project to "+
+			"an archive file",
+		cmdExportProject)
+
+	c.AddOption("o", "output", "path", "",
+		"the file to write the archive to instead of the standard output")
+	c.AddFlag("s", "secrets",
+		"include identity data encrypted with a passphrase")
+	c.AddOption("P", "passphrase-file", "path", "",
+		"a file containing the passphrase used to encrypt identity data")
+
+	// import-project
+	c = p.AddCommand("import-project",
+		"import an archive file in the current project",
+		cmdImportProject)
+
+	c.AddFlag("n", "dry-run", "print changes but do not apply them")
+	c.AddOption("c", "on-conflict", "mode", "fail",
+		"what to do with objects which already exist in the project "+
+			"(fail, skip or overwrite)")
+	c.AddOption("P", "passphrase-file", "path", "",
+		"a file containing the passphrase used to decrypt identity data")
+
+	c.AddArgument("path", "the path of the archive file")
+}
+
+func cmdExportProject(p *program.Program) {
+	app.IdentifyCurrentProject()
+
+	outputPath := p.OptionValue("output")
+	passphraseFilePath := p.OptionValue("passphrase-file")
+
+	var options eventline.ProjectExportOptions
+
+	if passphraseFilePath != "" {
+		options.Passphrase = loadPassphraseFile(passphraseFilePath)
+	} else if p.IsOptionSet("secrets") {
+		passphrase := PromptPassphrase("Passphrase: ")
+		if PromptPassphrase("Confirm passphrase: ") != passphrase {
+			p.Fatal("passphrases do not match")
+		}
+
+		options.Passphrase = passphrase
+	}
+
+	if p.IsOptionSet("secrets") && options.Passphrase == "" {
+		p.Fatal("empty passphrase")
+	}
+
+	archive, err := app.Client.ExportProject(&options)
+	if err != nil {
+		p.Fatal("cannot export project: %v", err)
+	}
+
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		p.Fatal("cannot encode archive: %v", err)
+	}
+
+	data = append(data, '\n')
+
+	if outputPath == "" {
+		os.Stdout.Write(data)
+		return
+	}
+
+	// The archive may contain identity data, it must not be readable by
+	// other users.
+	if err := os.WriteFile(outputPath, data, 0600); err != nil {
+		p.Fatal("cannot write %q: %v", outputPath, err)
+	}
+
+	p.Info("project exported to %s: %d jobs, %d identities",
+		outputPath, len(archive.Jobs), len(archive.Identities))
+}
+
+func cmdImportProject(p *program.Program) {
+	app.IdentifyCurrentProject()
+
+	filePath := p.ArgumentValue("path")
+	dryRun := p.IsOptionSet("dry-run")
+	passphraseFilePath := p.OptionValue("passphrase-file")
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		p.Fatal("cannot read %q: %v", filePath, err)
+	}
+
+	var archive eventline.ProjectArchive
+
+	if err := json.Unmarshal(data, &archive); err != nil {
+		p.Fatal("cannot decode %q: %v", filePath, err)
+	}
+
+	onConflict := p.OptionValue("on-conflict")
+
+	options := eventline.ProjectImportOptions{
+		Archive:    &archive,
+		OnConflict: eventline.ProjectImportConflictMode(onConflict),
+	}
+
+	if archive.HasSecrets() {
+		if passphraseFilePath != "" {
+			options.Passphrase = loadPassphraseFile(passphraseFilePath)
+		} else {
+			options.Passphrase = PromptPassphrase("Passphrase: ")
+		}
+	}
+
+	// As for synchronization, always start by computing the plan so that it
+	// can be reviewed before being applied.
+	plan, err := app.Client.ImportProject(&options, true)
+	if err != nil {
+		fatalImportError(err, filePath)
+	}
+
+	if plan.IsEmpty() {
+		p.Info("project up to date")
+		return
+	}
+
+	printProjectSyncPlan(plan)
+
+	if dryRun {
+		return
+	}
+
+	if Confirm("Do you want to apply these changes?") == false {
+		p.Info("import aborted")
+		return
+	}
+
+	plan, err = app.Client.ImportProject(&options, false)
+	if err != nil {
+		fatalImportError(err, filePath)
+	}
+
+	p.Info("project imported: %d created, %d updated, %d skipped",
+		plan.Count(eventline.ProjectSyncOperationCreate),
+		plan.Count(eventline.ProjectSyncOperationUpdate),
+		plan.Count(eventline.ProjectSyncOperationSkip))
+}
+
+func loadPassphraseFile(filePath string) string {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		p.Fatal("cannot read %q: %v", filePath, err)
+	}
+
+	passphrase := strings.TrimRight(string(data), "\r\n")
+	if passphrase == "" {
+		p.Fatal("empty passphrase in %q", filePath)
+	}
+
+	return passphrase
+}
+
+func fatalImportError(err error, filePath string) {
+	isRequestBodyError, verrs := IsInvalidRequestBodyError(err)
+	if !isRequestBodyError {
+		p.Fatal("cannot import project: %v", err)
+	}
+
+	p.Error("invalid project archive")
+
+	for _, verr := range verrs {
+		if len(verr.Pointer) > 0 && verr.Pointer[0] == "archive" {
+			verr.Pointer = verr.Pointer[1:]
+		}
+
+		p.Error("%s: %v", filePath, verr)
+	}
+
+	os.Exit(1)
+}
@@ -20,6 +20,15 @@ func addProjectCommands() {
 
 	c.AddArgument("name", "the name of the project")
 
+	// clone-project
+	c = p.AddCommand("clone-project",
+		"create a new project containing the jobs, step templates, "+
+			"settings and identity placeholders of an existing project",
+		cmdCloneProject)
+
+	c.AddArgument("source", "the name of the project to clone")
+	c.AddArgument("name", "the name of the new project")
+
 	// delete-project
 	c = p.AddCommand("delete-project", "delete a project",
 		cmdDeleteProject)
@@ -58,6 +67,28 @@ func cmdCreateProject(p *program.Program) {
 	p.Info("project %q created", project.Name)
 }
 
+func cmdCloneProject(p *program.Program) {
+	sourceName := p.ArgumentValue("source")
+	name := p.ArgumentValue("name")
+
+	sourceProject, err := app.Client.FetchProjectByName(sourceName)
+	if err != nil {
+		p.Fatal("cannot fetch project: %v", err)
+	}
+
+	data := eventline.ProjectCloningData{
+		Name: name,
+	}
+
+	project, err := app.Client.CloneProject(sourceProject.Id, &data)
+	if err != nil {
+		p.Fatal("cannot clone project: %v", err)
+	}
+
+	p.Info("project %q created from project %q; identities must be "+
+		"configured before being used", project.Name, sourceProject.Name)
+}
+
 func cmdDeleteProject(p *program.Program) {
 	name := p.ArgumentValue("name")
 
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/program"
+)
+
+func addPromoteCommand() {
+	var c *program.Command
+
+	// promote-jobs
+	c = p.AddCommand("promote-jobs",
+		"copy jobs from another project to the current project",
+		cmdPromoteJobs)
+
+	c.AddOption("f", "from", "name", "",
+		"the name of the project containing the jobs to promote")
+	c.AddOption("m", "mapping", "path", "",
+		"a file containing overrides applied to promoted jobs")
+	c.AddFlag("n", "dry-run", "print changes but do not apply them")
+
+	c.AddTrailingArgument("job",
+		"the name of a job to promote (all jobs if none is provided)")
+}
+
+func cmdPromoteJobs(p *program.Program) {
+	app.IdentifyCurrentProject()
+
+	sourceName := p.OptionValue("from")
+	mappingFilePath := p.OptionValue("mapping")
+	dryRun := p.IsOptionSet("dry-run")
+
+	if sourceName == "" {
+		p.Fatal("missing source project")
+	}
+
+	sourceProject, err := app.Client.FetchProjectByName(sourceName)
+	if err != nil {
+		p.Fatal("cannot fetch project: %v", err)
+	}
+
+	data := eventline.JobPromotionData{
+		SourceProjectId: sourceProject.Id,
+		JobNames:        p.TrailingArgumentValues("job"),
+	}
+
+	if mappingFilePath != "" {
+		data.Mapping = &eventline.ProjectPromotionMapping{}
+
+		if err := loadYAMLFile(mappingFilePath, data.Mapping); err != nil {
+			p.Fatal("cannot load %q: %v", mappingFilePath, err)
+		}
+	}
+
+	plan, err := app.Client.PromoteJobs(&data, true)
+	if err != nil {
+		fatalPromotionError(err, mappingFilePath)
+	}
+
+	if plan.IsEmpty() {
+		p.Info("project up to date")
+		return
+	}
+
+	printProjectSyncPlan(plan)
+
+	if dryRun {
+		return
+	}
+
+	if Confirm("Do you want to apply these changes?") == false {
+		p.Info("promotion aborted")
+		return
+	}
+
+	plan, err = app.Client.PromoteJobs(&data, false)
+	if err != nil {
+		fatalPromotionError(err, mappingFilePath)
+	}
+
+	p.Info("jobs promoted: %d created, %d updated",
+		plan.Count(eventline.ProjectSyncOperationCreate),
+		plan.Count(eventline.ProjectSyncOperationUpdate))
+}
+
+func fatalPromotionError(err error, mappingFilePath string) {
+	isRequestBodyError, verrs := IsInvalidRequestBodyError(err)
+	if !isRequestBodyError {
+		p.Fatal("cannot promote jobs: %v", err)
+	}
+
+	p.Error("invalid promotion")
+
+	for _, verr := range verrs {
+		if len(verr.Pointer) > 0 && verr.Pointer[0] == "mapping" {
+			verr.Pointer = verr.Pointer[1:]
+			p.Error("%s: %v", mappingFilePath, verr)
+			continue
+		}
+
+		p.Error("%v", verr)
+	}
+
+	os.Exit(1)
+}
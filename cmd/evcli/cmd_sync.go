@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/program"
+)
+
+func addSyncCommand() {
+	var c *program.Command
+
+	// sync
+	c = p.AddCommand("sync",
+		"synchronize the current project with a set of job specification "+
+			"files, deleting jobs which are not part of the set",
+		cmdSync)
+
+	c.AddFlag("n", "dry-run", "print changes but do not apply them")
+	c.AddFlag("r", "recursive", "find job files in nested directories")
+
+	c.AddOption("i", "identities", "path", "",
+		"a file containing the list of identities of the project")
+	c.AddOption("s", "settings", "path", "",
+		"a file containing the settings of the project")
+
+	c.AddTrailingArgument("path",
+		"the path of a job specification file or directory")
+}
+
+func cmdSync(p *program.Program) {
+	app.IdentifyCurrentProject()
+
+	fileOrDirPaths := p.TrailingArgumentValues("path")
+	dryRun := p.IsOptionSet("dry-run")
+	recursive := p.IsOptionSet("recursive")
+
+	filePaths, err := eventline.FindJobFiles(fileOrDirPaths, recursive)
+	if err != nil {
+		p.Fatal("%v", err)
+	}
+
+	var spec eventline.ProjectSyncSpec
+
+	spec.Jobs = make(eventline.JobSpecs, len(filePaths))
+
+	for i, filePath := range filePaths {
+		jobSpec, err := LoadJobFile(filePath)
+		if err != nil {
+			p.Fatal("cannot load %q: %v", filePath, err)
+		}
+
+		spec.Jobs[i] = jobSpec
+	}
+
+	identitiesFilePath := p.OptionValue("identities")
+	if identitiesFilePath != "" {
+		spec.Identities = eventline.IdentitySyncSpecs{}
+
+		err := loadYAMLFile(identitiesFilePath, &spec.Identities)
+		if err != nil {
+			p.Fatal("cannot load %q: %v", identitiesFilePath, err)
+		}
+	}
+
+	settingsFilePath := p.OptionValue("settings")
+	if settingsFilePath != "" {
+		spec.ProjectSettings = &eventline.ProjectSettingsUpdate{}
+
+		err := loadYAMLFile(settingsFilePath, spec.ProjectSettings)
+		if err != nil {
+			p.Fatal("cannot load %q: %v", settingsFilePath, err)
+		}
+	}
+
+	// Always start by computing the plan so that it can be reviewed before
+	// being applied.
+	plan, err := app.Client.SyncProject(&spec, true)
+	if err != nil {
+		fatalSyncError(err, filePaths, identitiesFilePath, settingsFilePath)
+	}
+
+	if plan.IsEmpty() {
+		p.Info("project up to date")
+		return
+	}
+
+	printProjectSyncPlan(plan)
+
+	if dryRun {
+		return
+	}
+
+	if Confirm("Do you want to apply these changes?") == false {
+		p.Info("synchronization aborted")
+		return
+	}
+
+	plan, err = app.Client.SyncProject(&spec, false)
+	if err != nil {
+		fatalSyncError(err, filePaths, identitiesFilePath, settingsFilePath)
+	}
+
+	p.Info("project synchronized: %d created, %d updated, %d deleted",
+		plan.Count(eventline.ProjectSyncOperationCreate),
+		plan.Count(eventline.ProjectSyncOperationUpdate),
+		plan.Count(eventline.ProjectSyncOperationDelete))
+}
+
+func printProjectSyncPlan(plan *eventline.ProjectSyncPlan) {
+	for _, change := range plan.Changes {
+		var prefix string
+
+		switch change.Operation {
+		case eventline.ProjectSyncOperationCreate:
+			prefix = Colorize(ColorGreen, "+")
+		case eventline.ProjectSyncOperationUpdate:
+			prefix = Colorize(ColorYellow, "~")
+		case eventline.ProjectSyncOperationDelete:
+			prefix = Colorize(ColorRed, "-")
+		case eventline.ProjectSyncOperationSkip:
+			prefix = "="
+		}
+
+		switch change.ObjectType {
+		case eventline.ProjectSyncObjectTypeProjectSettings:
+			fmt.Printf("%s project settings\n", prefix)
+		case eventline.ProjectSyncObjectTypeProjectNotificationSettings:
+			fmt.Printf("%s project notification settings\n", prefix)
+		default:
+			fmt.Printf("%s %s %s\n", prefix, change.ObjectType, change.Name)
+		}
+	}
+
+	fmt.Printf("\nPlan: %d to create, %d to update, %d to delete.\n",
+		plan.Count(eventline.ProjectSyncOperationCreate),
+		plan.Count(eventline.ProjectSyncOperationUpdate),
+		plan.Count(eventline.ProjectSyncOperationDelete))
+
+	if n := plan.Count(eventline.ProjectSyncOperationSkip); n > 0 {
+		fmt.Printf("%d skipped.\n", n)
+	}
+}
+
+func fatalSyncError(err error, jobFilePaths []string, identitiesFilePath, settingsFilePath string) {
+	isRequestBodyError, verrs := IsInvalidRequestBodyError(err)
+	if !isRequestBodyError {
+		p.Fatal("cannot synchronize project: %v", err)
+	}
+
+	p.Error("invalid project specification")
+
+	for _, verr := range verrs {
+		if len(verr.Pointer) == 0 {
+			p.Error("%v", verr)
+			continue
+		}
+
+		switch verr.Pointer[0] {
+		case "jobs":
+			if len(verr.Pointer) < 2 {
+				p.Error("%v", verr)
+				continue
+			}
+
+			i, err := strconv.Atoi(verr.Pointer[1])
+			if err != nil || i < 0 || i >= len(jobFilePaths) {
+				p.Error("invalid error pointer %v", verr.Pointer)
+				continue
+			}
+
+			verr.Pointer = verr.Pointer[2:]
+			p.Error("%s: %v", jobFilePaths[i], verr)
+
+		case "identities":
+			verr.Pointer = verr.Pointer[1:]
+			p.Error("%s: %v", identitiesFilePath, verr)
+
+		case "project_settings":
+			verr.Pointer = verr.Pointer[1:]
+			p.Error("%s: %v", settingsFilePath, verr)
+
+		default:
+			p.Error("%v", verr)
+		}
+	}
+
+	os.Exit(1)
+}
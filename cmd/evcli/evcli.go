@@ -35,6 +35,9 @@ func main() {
 	addJobCommands()
 	addJobExecutionCommands()
 	addIdentityCommands()
+	addSyncCommand()
+	addProjectArchiveCommands()
+	addPromoteCommand()
 
 	p.AddCommand("version", "print the version of evcli and exit", cmdVersion)
 
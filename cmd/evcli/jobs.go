@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
@@ -11,97 +10,59 @@ import (
 	"github.com/exograd/eventline/pkg/utils"
 )
 
-func FindJobFiles(filesOrDirPaths []string, recursive bool) ([]string, error) {
-	var filePaths []string
+func LoadJobFile(filePath string) (*eventline.JobSpec, error) {
+	p.Debug(1, "loading job file %s", filePath)
 
-	var fn func(string, int) error
-	fn = func(currentPath string, depth int) error {
-		info, err := os.Stat(currentPath)
-		if err != nil {
-			return fmt.Errorf("cannot stat %q: %w", currentPath, err)
-		}
+	return eventline.LoadJobFile(filePath, "")
+}
 
-		if info.IsDir() {
-			if recursive || depth == 0 {
-				entries, err := ioutil.ReadDir(currentPath)
-				if err != nil {
-					return fmt.Errorf("cannot list directory %q: %w",
-						currentPath, err)
-				}
-
-				for _, entry := range entries {
-					fullPath := path.Join(currentPath, entry.Name())
-					if err := fn(fullPath, depth+1); err != nil {
-						return err
-					}
-				}
-			}
-		} else {
-			ext := path.Ext(currentPath)
-			if ext == ".yml" || ext == ".yaml" {
-				filePaths = append(filePaths, currentPath)
-			}
-		}
+type YAMLParser interface {
+	ParseYAML([]byte) error
+}
 
-		return nil
+func loadYAMLFile(filePath string, value YAMLParser) error {
+	p.Debug(1, "loading file %s", filePath)
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("cannot read %q: %w", filePath, err)
 	}
 
-	for _, fileOrDirPath := range filesOrDirPaths {
-		if err := fn(fileOrDirPath, 0); err != nil {
-			return nil, err
-		}
+	if err := value.ParseYAML(data); err != nil {
+		return fmt.Errorf("cannot decode data: %w", err)
 	}
 
-	return filePaths, nil
+	return nil
 }
 
-func LoadJobFile(filePath string) (*eventline.JobSpec, error) {
-	p.Debug(1, "loading job file %s", filePath)
+func LoadStepTemplateFile(filePath string) (*eventline.StepTemplateSpec, error) {
+	p.Debug(1, "loading step template file %s", filePath)
 
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("cannot read %q: %w", filePath, err)
 	}
 
-	var spec eventline.JobSpec
+	var spec eventline.StepTemplateSpec
 	if err := spec.ParseYAML(data); err != nil {
 		return nil, fmt.Errorf("cannot decode data: %w", err)
 	}
 
+	// Script paths are relative to the step template file, not to the files
+	// of the jobs using the template.
 	dirPath := filepath.Dir(filePath)
-	if err := LoadSteps(&spec, dirPath); err != nil {
-		return nil, err
-	}
-
-	return &spec, nil
-}
-
-func LoadSteps(spec *eventline.JobSpec, dirPath string) error {
 	for i, step := range spec.Steps {
-		switch {
-		case step.Script != nil:
-			if err := LoadScriptStep(step, dirPath); err != nil {
-				return fmt.Errorf("cannot load script for "+
+		if step.Script != nil {
+			if err := eventline.LoadScriptStep(step, dirPath, ""); err != nil {
+				return nil, fmt.Errorf("cannot load script for "+
 					"step %d: %w", i+1, err)
 			}
 		}
 	}
 
-	return nil
+	return &spec, nil
 }
 
-func LoadScriptStep(step *eventline.Step, dirPath string) error {
-	filePath := path.Join(dirPath, step.Script.Path)
-
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return fmt.Errorf("cannot read %q: %w", filePath, err)
-	}
-
-	step.Script.Content = string(data)
-
-	return nil
-}
 func ExportJob(spec *eventline.JobSpec, dirPath string) (string, error) {
 	for _, step := range spec.Steps {
 		if script := step.Script; script != nil {
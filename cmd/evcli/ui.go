@@ -46,6 +46,21 @@ func Confirm(prompt string) bool {
 	return false
 }
 
+func PromptPassphrase(prompt string) string {
+	line := liner.NewLiner()
+	defer line.Close()
+
+	line.SetCtrlCAborts(true)
+
+	passphrase, err := line.PasswordPrompt(prompt)
+	if err != nil {
+		line.Close()
+		p.Fatal("cannot read passphrase: %v", err)
+	}
+
+	return passphrase
+}
+
 func Colorize(color Color, text string) string {
 	if !colorOutput {
 		return text
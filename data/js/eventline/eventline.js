@@ -94,6 +94,15 @@ function evIsDropdownActive() {
   return document.querySelectorAll(".dropdown.is-active").length > 0
 }
 
+function evIsFormInputActive() {
+  const element = document.activeElement;
+  return element != null && element.closest("form") != null;
+}
+
+function evIsSelectionActive() {
+  return document.querySelectorAll("input.ev-selection:checked").length > 0
+}
+
 function evSetupLogout() {
   if (evIsLoggedIn()) {
     const link = document.querySelector(".menu li a[data-id='logout']");
@@ -210,6 +219,14 @@ function evAutoRefresh() {
         return;
       }
 
+      if (evIsFormInputActive()) {
+        return;
+      }
+
+      if (evIsSelectionActive()) {
+        return;
+      }
+
       const html = document.createElement("html");
       html.innerHTML = response.data;
 
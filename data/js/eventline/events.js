@@ -11,6 +11,102 @@ function evSetupEventList() {
   replayLinks.forEach(link => {
     link.onclick = evOnReplayEventClicked;
   });
+
+  const selectionInputs = document.querySelectorAll("#ev-events .ev-selection");
+  selectionInputs.forEach(input => {
+    input.onchange = evUpdateEventSelection;
+  });
+
+  const selectAllInput = document.querySelector("#ev-events .ev-selection-all");
+  if (selectAllInput) {
+    selectAllInput.onchange = () => {
+      selectionInputs.forEach(input => {
+        input.checked = selectAllInput.checked;
+      });
+
+      evUpdateEventSelection();
+    };
+  }
+
+  const replaySelectionButton =
+        document.querySelector("button[name='replay-selection']");
+  if (replaySelectionButton) {
+    replaySelectionButton.onclick = evOnReplaySelectionClicked;
+  }
+}
+
+function evSelectedEventIds() {
+  const selector = "#ev-events .ev-selection:checked";
+  return Array.from(document.querySelectorAll(selector))
+    .map(input => input.dataset.id);
+}
+
+function evUpdateEventSelection() {
+  const button = document.querySelector("button[name='replay-selection']");
+  button.disabled = evSelectedEventIds().length == 0;
+}
+
+function evOnReplaySelectionClicked(event) {
+  event.preventDefault();
+
+  const selection = {
+    event_ids: evSelectedEventIds()
+  };
+
+  const modal = document.querySelector("#ev-replay-events-modal");
+
+  modal.querySelectorAll("button[name='cancel']").forEach(button => {
+    button.onclick = evCloseModals;
+  });
+
+  const replayButton = modal.querySelector("button[name='replay']");
+  replayButton.onclick = function () {
+    replayButton.classList.add("is-loading");
+
+    const request = {
+      method: "POST",
+      body: JSON.stringify(selection)
+    };
+
+    evFetch("/events/replay", request)
+      .then(response => {
+        const count = response.data.count;
+        evShowInfo(`${count} event${count == 1 ? "" : "s"} replayed.`);
+
+        // Clear the selection so that auto-refresh shows new events
+        document.querySelectorAll("#ev-events input[type='checkbox']")
+          .forEach(input => {
+            input.checked = false;
+          });
+        evUpdateEventSelection();
+      })
+      .catch (e => {
+        evShowError(`cannot replay events: ${e.message}`);
+      })
+      .finally(() => {
+        replayButton.classList.remove("is-loading");
+        evCloseModals();
+      });
+  };
+
+  // Count events with a dry run first so that the user knows what will
+  // happen.
+  const request = {
+    method: "POST",
+    body: JSON.stringify(selection)
+  };
+
+  evFetch("/events/replay?dry-run", request)
+    .then(response => {
+      const count = response.data.count;
+      const countSpan = modal.querySelector(".ev-event-count");
+      countSpan.textContent = `${count} event${count == 1 ? "" : "s"}`;
+
+      evOpenModal(modal);
+    })
+    .catch (e => {
+      evShowError(`cannot count events: ${e.message}`);
+    });
 }
 
 function evSetupEventView() {
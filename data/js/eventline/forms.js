@@ -8,6 +8,7 @@ function evSetupForms() {
 
 function evSetupForm(form, options) {
   evCreateFormHelpElements(form);
+  evSetupJSONInputs(form);
 
   form.addEventListener("submit", (event) => {
     event.preventDefault();
@@ -15,6 +16,24 @@ function evSetupForm(form, options) {
   });
 }
 
+function evSetupJSONInputs(container) {
+  const inputs = container.querySelectorAll(".ev-json-input");
+
+  inputs.forEach((input) => {
+    input.addEventListener("input", () => {
+      try {
+        if (input.value.trim() != "") {
+          JSON.parse(input.value);
+        }
+
+        input.setCustomValidity("");
+      } catch (e) {
+        input.setCustomValidity("Invalid JSON value.");
+      }
+    });
+  });
+}
+
 function evCreateFormHelpElements(container) {
   const fields = container.querySelectorAll(".field");
 
@@ -186,6 +205,16 @@ function evCollectFormData(form, button, options) {
         return input.value;
       case "select-multiple":
         return [...input.options].filter(o => o.selected).map(o => o.value);
+      case "datetime-local":
+        // Datetimes are expressed in UTC in the web interface; seconds are
+        // omitted by some browsers.
+        if (input.value == "") {
+          return input.value;
+        } else if (input.value.length == 16) {
+          return input.value + ":00Z";
+        } else {
+          return input.value + "Z";
+        }
       case 'number':
         if (input.value == "") {
           return input.value;
@@ -203,7 +232,14 @@ function evCollectFormData(form, button, options) {
           return input.value
                       .split(",")
                       .map(v => v.trim())
-                      .filter(v => v.length > 0);
+                      .filter(v => v.length > 0)
+                      .map(v => evListElementValue(input, v));
+        } else if (input.classList.contains("ev-json-input")) {
+          if (input.value.trim() == "") {
+            return "";
+          }
+
+          return JSON.parse(input.value);
         } else {
           return input.value;
         }
@@ -231,6 +267,28 @@ function evCollectFormData(form, button, options) {
   return data;
 }
 
+function evListElementValue(input, value) {
+  switch (input.dataset.elementType) {
+  case "number":
+  case "integer":
+  case "boolean":
+    try {
+      const elementValue = JSON.parse(value);
+      if (typeof elementValue == "number"
+          || typeof elementValue == "boolean") {
+        return elementValue;
+      }
+    } catch (e) {
+    }
+
+    // Let server validation return a nice error
+    return value;
+
+  default:
+    return value;
+  }
+}
+
 function evFormDataInsert(data, pointer, value) {
   const key = pointer[0];
 
@@ -86,7 +86,7 @@ function evSetupStepFolding() {
     let stepState = window.evStepStates.get(stepId);
     let folded = true;
 
-    if (stepStatus != "created") {
+    if (stepStatus != "created" && stepStatus != "skipped") {
       lastRunStep = step;
     }
 
@@ -94,7 +94,7 @@ function evSetupStepFolding() {
       folded = stepState.folded;
     } else {
       if (window.evAutoFold) {
-        folded = (stepStatus == "created");
+        folded = (stepStatus == "created" || stepStatus == "skipped");
 
         if (!folded) {
           // Once automatically open, keep it open as long as it does not
@@ -192,8 +192,20 @@ function evOnRestartJobExecutionClicked(event) {
   const link = event.target;
   const id = link.dataset.id;
 
+  // Secret parameters are not stored with the job execution and must be
+  // provided again.
+  const secretNames = (link.dataset.secretParameters ?? "")
+        .split(",")
+        .filter(name => name !== "");
+
   const modal = document.querySelector("#ev-restart-job-modal");
 
+  const secretParameters = modal.querySelector(".ev-secret-parameters");
+  secretParameters.hidden = secretNames.length == 0;
+
+  const secretFields = modal.querySelector(".ev-secret-parameter-fields");
+  secretFields.replaceChildren(...secretNames.map(evSecretParameterField));
+
   modal.querySelectorAll("button[name='cancel']").forEach(button => {
     button.onclick = evCloseModals;
   });
@@ -207,6 +219,15 @@ function evOnRestartJobExecutionClicked(event) {
       method: "POST"
     };
 
+    if (secretNames.length > 0) {
+      const parameters = {};
+      secretFields.querySelectorAll("input").forEach(input => {
+        parameters[input.name] = input.value;
+      });
+
+      request.body = JSON.stringify({parameters: parameters});
+    }
+
     evFetch(uri, request)
       .then(response => {
         location.reload();
@@ -223,3 +244,28 @@ function evOnRestartJobExecutionClicked(event) {
 
   evOpenModal(modal);
 }
+
+function evSecretParameterField(name) {
+  const field = document.createElement("div");
+  field.classList.add("field", "ev-required");
+
+  const label = document.createElement("label");
+  label.classList.add("label");
+  label.htmlFor = `/parameters/${name}`;
+  label.textContent = name;
+
+  const control = document.createElement("div");
+  control.classList.add("control");
+
+  const input = document.createElement("input");
+  input.classList.add("input");
+  input.id = `/parameters/${name}`;
+  input.name = name;
+  input.type = "password";
+  input.autocomplete = "off";
+
+  control.appendChild(input);
+  field.append(label, control);
+
+  return field;
+}
@@ -5,6 +5,8 @@ evOnPageLoaded("jobs", evSetupAutoRefresh);
 evOnPageLoaded("job_timeline", evSetupJobTimeline);
 evOnPageLoaded("job_timeline", evSetupAutoRefresh);
 evOnPageLoaded("job_metrics", evSetupJobMetrics);
+evOnPageLoaded("job_versions", evSetupJobVersions);
+evOnPageLoaded("job_version", evSetupJobVersions);
 
 function evSetupJobList() {
   const disableLinkSelector = "#ev-jobs a[data-action='disable']:not(.ev-disabled)";
@@ -170,6 +172,35 @@ function evSetupJobTimeline() {
   });
 }
 
+function evSetupJobVersions() {
+  const rollbackSelector = "[data-action='rollback']";
+  const rollbackElements = document.querySelectorAll(rollbackSelector);
+  rollbackElements.forEach(element => {
+    element.onclick = evOnRollbackJobClicked;
+  });
+}
+
+function evOnRollbackJobClicked(event) {
+  event.preventDefault();
+
+  const element = event.target.closest("[data-action='rollback']");
+  const jobId = element.dataset.jobId;
+  const version = element.dataset.version;
+
+  const uri = `/jobs/id/${jobId}/versions/${version}/rollback`
+  const request = {
+    method: "POST"
+  };
+
+  evFetch(uri, request)
+    .then(response => {
+      window.location.href = response.data.location;
+    })
+    .catch (e => {
+      evShowError(`cannot roll back job to version ${version}: ${e.message}`);
+    });
+}
+
 function evSetupJobMetrics() {
   const element = document.getElementById("job-metrics");
   const jobId = element.dataset.jobId;
@@ -0,0 +1 @@
+ALTER TYPE STEP_EXECUTION_STATUS ADD VALUE 'skipped';
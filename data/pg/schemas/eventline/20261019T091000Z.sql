@@ -0,0 +1,7 @@
+ALTER TABLE job_executions
+  ADD COLUMN matrix_id UUID,
+  ADD COLUMN matrix_combination JSONB
+    CHECK (jsonb_typeof(matrix_combination) = 'object');
+
+CREATE INDEX job_executions_matrix_id_idx
+  ON job_executions (matrix_id);
@@ -0,0 +1,10 @@
+CREATE TABLE step_templates
+  (id UUID PRIMARY KEY,
+   project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+   name VARCHAR NOT NULL,
+   creation_time TIMESTAMP NOT NULL,
+   update_time TIMESTAMP NOT NULL,
+   spec JSONB NOT NULL);
+
+CREATE UNIQUE INDEX step_templates_project_id_name_idx
+  ON step_templates (project_id, name);
@@ -0,0 +1,28 @@
+CREATE TABLE job_versions
+  (id UUID PRIMARY KEY,
+   project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+   job_id UUID NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+   version INTEGER NOT NULL,
+   creation_time TIMESTAMP NOT NULL,
+   source VARCHAR NOT NULL,
+   account_id UUID REFERENCES accounts (id) ON DELETE SET NULL,
+   spec JSONB NOT NULL);
+
+CREATE UNIQUE INDEX job_versions_job_id_version_idx
+  ON job_versions (job_id, version);
+
+-- Existing jobs start with a single version containing their current
+-- specification.
+INSERT INTO job_versions
+    (id, project_id, job_id, version, creation_time, source, spec)
+  SELECT gen_random_uuid(), project_id, id, 1, update_time, 'deployment',
+         spec
+    FROM jobs;
+
+ALTER TABLE job_executions
+  ADD COLUMN job_version_id UUID
+    REFERENCES job_versions (id) ON DELETE SET NULL;
+
+ALTER TABLE project_settings
+  ADD COLUMN job_version_retention INTEGER NOT NULL DEFAULT 0
+    CHECK (job_version_retention >= 0);
@@ -0,0 +1,19 @@
+CREATE TABLE git_repositories
+  (project_id UUID PRIMARY KEY REFERENCES projects (id) ON DELETE CASCADE,
+   uri VARCHAR NOT NULL,
+   branch VARCHAR NOT NULL,
+   directory VARCHAR NOT NULL DEFAULT '',
+   sync_interval INTEGER NOT NULL CHECK (sync_interval > 0),
+   next_sync_time TIMESTAMP NOT NULL,
+   last_sync_time TIMESTAMP,
+   last_commit VARCHAR NOT NULL DEFAULT '',
+   last_error VARCHAR NOT NULL DEFAULT '');
+
+CREATE INDEX git_repositories_next_sync_time_idx
+  ON git_repositories (next_sync_time);
+
+ALTER TABLE jobs
+  ADD COLUMN git_commit VARCHAR NOT NULL DEFAULT '';
+
+ALTER TABLE job_versions
+  ADD COLUMN git_commit VARCHAR NOT NULL DEFAULT '';
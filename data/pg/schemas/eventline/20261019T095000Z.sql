@@ -0,0 +1,13 @@
+CREATE TABLE project_variables
+  (project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+   name VARCHAR NOT NULL,
+   creation_time TIMESTAMP NOT NULL,
+   update_time TIMESTAMP NOT NULL,
+   secret BOOLEAN NOT NULL DEFAULT FALSE,
+   value VARCHAR,
+   encrypted_value BYTEA,
+
+   PRIMARY KEY (project_id, name),
+
+   CHECK ((secret AND value IS NULL AND encrypted_value IS NOT NULL)
+          OR (NOT secret AND value IS NOT NULL AND encrypted_value IS NULL)));
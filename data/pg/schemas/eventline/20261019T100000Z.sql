@@ -0,0 +1,8 @@
+CREATE TABLE c_gitlab_subscriptions
+  (id UUID PRIMARY KEY REFERENCES subscriptions (id),
+   group_path VARCHAR NOT NULL,
+   project VARCHAR NOT NULL,
+   hook_id INT8 NOT NULL);
+
+CREATE INDEX c_gitlab_subscriptions_group_path_project_idx
+  ON c_gitlab_subscriptions (group_path, project);
@@ -0,0 +1,18 @@
+CREATE TABLE event_deduplication_keys
+  (job_id UUID NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+   key VARCHAR NOT NULL,
+   expiration_time TIMESTAMP NOT NULL,
+
+   PRIMARY KEY (job_id, key));
+
+CREATE TABLE debounced_events
+  (job_id UUID NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+   key VARCHAR NOT NULL,
+   project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+   event_id UUID NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+   scheduled_time TIMESTAMP NOT NULL,
+
+   PRIMARY KEY (job_id, key));
+
+CREATE INDEX debounced_events_scheduled_time_idx
+  ON debounced_events (scheduled_time);
@@ -0,0 +1,26 @@
+CREATE TABLE event_deliveries
+  (event_id UUID NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+   job_id UUID NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+   project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+   processed BOOLEAN NOT NULL,
+
+   PRIMARY KEY (event_id, job_id));
+
+CREATE INDEX event_deliveries_job_id_idx
+  ON event_deliveries (job_id);
+
+CREATE INDEX event_deliveries_project_id_idx
+  ON event_deliveries (project_id);
+
+CREATE INDEX event_deliveries_processed_idx
+  ON event_deliveries (processed)
+  WHERE processed = FALSE;
+
+INSERT INTO event_deliveries (event_id, job_id, project_id, processed)
+  SELECT id, job_id, project_id, processed
+    FROM events
+    WHERE job_id IS NOT NULL;
+
+ALTER TABLE events
+  DROP COLUMN job_id,
+  DROP COLUMN processed;
@@ -0,0 +1,9 @@
+CREATE TABLE c_postgresql_subscriptions
+  (id UUID PRIMARY KEY REFERENCES subscriptions (id),
+   last_notification_time TIMESTAMP,
+   last_poll_time TIMESTAMP,
+   next_poll_time TIMESTAMP,
+   row_cursor VARCHAR);
+
+CREATE INDEX c_postgresql_subscriptions_next_poll_time_idx
+  ON c_postgresql_subscriptions (next_poll_time);
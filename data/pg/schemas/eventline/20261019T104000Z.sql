@@ -0,0 +1,13 @@
+CREATE TABLE job_execution_reports
+  (id UUID PRIMARY KEY,
+   job_execution_id UUID NOT NULL
+     REFERENCES job_executions (id) ON DELETE CASCADE,
+   next_delivery_time TIMESTAMP NOT NULL,
+   delivery_delay INT NOT NULL,
+   nb_attempts INT NOT NULL);
+
+CREATE INDEX job_execution_reports_job_execution_id_idx
+  ON job_execution_reports (job_execution_id);
+
+CREATE INDEX job_execution_reports_next_delivery_time_idx
+  ON job_execution_reports (next_delivery_time);
@@ -0,0 +1,176 @@
+package amqp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/exograd/eventline/pkg/connectors/mq"
+	"github.com/exograd/eventline/pkg/eventline"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// The number of messages the server delivers before waiting for
+// acknowledgements.
+const prefetchCount = 10
+
+type Channel interface {
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Close() error
+}
+
+type Consumer struct {
+	Parameters *Parameters
+	Identity   *PasswordIdentity
+
+	openChannel func() (Channel, error)
+}
+
+type Message struct {
+	Delivery amqp.Delivery
+	Queue    string
+	Format   string
+}
+
+func (c *Connector) NewConsumer(sub *eventline.Subscription, identity *eventline.Identity) (mq.Consumer, error) {
+	consumer := Consumer{
+		Parameters: sub.Parameters.(*Parameters),
+	}
+
+	if identity != nil {
+		idata, ok := identity.Data.(*PasswordIdentity)
+		if !ok {
+			return nil, fmt.Errorf("unsupported identity")
+		}
+
+		consumer.Identity = idata
+	}
+
+	consumer.openChannel = consumer.dial
+
+	return &consumer, nil
+}
+
+func (c *Consumer) Run(ctx context.Context, handler mq.Handler) error {
+	ch, err := c.openChannel()
+	if err != nil {
+		return err
+	}
+
+	// Closing the channel and the connection makes the server requeue
+	// messages which were delivered but not acknowledged.
+	defer ch.Close()
+
+	deliveries, err := ch.Consume(c.Parameters.Queue, "", false, false, false,
+		false, nil)
+	if err != nil {
+		return fmt.Errorf("cannot consume queue %q: %w",
+			c.Parameters.Queue, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case delivery, ok := <-deliveries:
+			if !ok {
+				return fmt.Errorf("delivery channel closed")
+			}
+
+			msg := Message{
+				Delivery: delivery,
+				Queue:    c.Parameters.Queue,
+				Format:   c.Parameters.Format,
+			}
+
+			if err := handler(&msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *Consumer) dial() (Channel, error) {
+	var cfg amqp.Config
+
+	if c.Identity != nil {
+		cfg.SASL = []amqp.Authentication{
+			&amqp.PlainAuth{
+				Username: c.Identity.Username,
+				Password: c.Identity.Password,
+			},
+		}
+	}
+
+	conn, err := amqp.DialConfig(c.Parameters.URI, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %q: %w",
+			c.Parameters.URI, err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("cannot open channel: %w", err)
+	}
+
+	if err := ch.Qos(prefetchCount, 0, false); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("cannot set channel quality of service: %w",
+			err)
+	}
+
+	return &connChannel{Channel: ch, conn: conn}, nil
+}
+
+// connChannel is a channel which closes its connection when it is closed.
+type connChannel struct {
+	*amqp.Channel
+
+	conn *amqp.Connection
+}
+
+func (ch *connChannel) Close() error {
+	ch.Channel.Close()
+	return ch.conn.Close()
+}
+
+func (m *Message) Decode() (*mq.Delivery, error) {
+	d := m.Delivery
+
+	body, err := mq.DecodeBody(m.Format, d.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var eventTime *time.Time
+	if !d.Timestamp.IsZero() {
+		t := d.Timestamp.UTC()
+		eventTime = &t
+	}
+
+	event := MessageEvent{
+		Queue:       m.Queue,
+		Exchange:    d.Exchange,
+		RoutingKey:  d.RoutingKey,
+		MessageId:   d.MessageId,
+		ContentType: d.ContentType,
+		Headers:     mq.StringHeaders(d.Headers),
+		Body:        body,
+	}
+
+	return &mq.Delivery{EventTime: eventTime, Data: &event}, nil
+}
+
+func (m *Message) Ack() error {
+	return m.Delivery.Ack(false)
+}
+
+func (m *Message) Requeue() error {
+	return m.Delivery.Nack(false, true)
+}
+
+func (m *Message) Reject() error {
+	return m.Delivery.Reject(false)
+}
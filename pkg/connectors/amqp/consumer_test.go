@@ -0,0 +1,139 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/exograd/eventline/pkg/connectors/mq"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.n16f.net/log"
+)
+
+// testBroker is an in-process stand-in for an AMQP channel which records
+// acknowledgements.
+type testBroker struct {
+	deliveries chan amqp.Delivery
+	acks       []string
+	closed     bool
+}
+
+func newTestBroker() *testBroker {
+	return &testBroker{
+		deliveries: make(chan amqp.Delivery, 10),
+	}
+}
+
+func (b *testBroker) Publish(tag uint64, body string) {
+	b.deliveries <- amqp.Delivery{
+		Acknowledger: b,
+		DeliveryTag:  tag,
+		MessageId:    strconv.FormatUint(tag, 10),
+		Exchange:     "events",
+		RoutingKey:   "builds",
+		Headers:      amqp.Table{"origin": "ci", "attempt": int32(2)},
+		Body:         []byte(body),
+	}
+}
+
+func (b *testBroker) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	if autoAck {
+		return nil, errors.New("automatic acknowledgement is not allowed")
+	}
+
+	return b.deliveries, nil
+}
+
+func (b *testBroker) Close() error {
+	b.closed = true
+	return nil
+}
+
+func (b *testBroker) Ack(tag uint64, multiple bool) error {
+	b.acks = append(b.acks, fmt.Sprintf("ack %d", tag))
+	return nil
+}
+
+func (b *testBroker) Nack(tag uint64, multiple, requeue bool) error {
+	if requeue {
+		b.acks = append(b.acks, fmt.Sprintf("requeue %d", tag))
+	} else {
+		b.acks = append(b.acks, fmt.Sprintf("nack %d", tag))
+	}
+
+	return nil
+}
+
+func (b *testBroker) Reject(tag uint64, requeue bool) error {
+	b.acks = append(b.acks, fmt.Sprintf("reject %d", tag))
+	return nil
+}
+
+func TestConsumer(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	broker := newTestBroker()
+
+	consumer := Consumer{
+		Parameters: &Parameters{Queue: "builds"},
+		openChannel: func() (Channel, error) {
+			return broker, nil
+		},
+	}
+
+	logger := log.DefaultLogger("amqp")
+
+	var events []*MessageEvent
+	var storeErr error
+
+	handler := func(msg mq.Message) error {
+		return mq.ProcessMessage(logger, msg, func(d *mq.Delivery) error {
+			if storeErr != nil {
+				return storeErr
+			}
+
+			// Messages must not be acknowledged before the event is stored
+			event := d.Data.(*MessageEvent)
+			assert.NotContains(broker.acks, "ack "+event.MessageId)
+
+			events = append(events, event)
+			return nil
+		})
+	}
+
+	broker.Publish(1, `{"status": "success"}`)
+	broker.Publish(2, `not json`)
+	broker.Publish(3, `{"status": "failure"}`)
+	close(broker.deliveries)
+
+	err := consumer.Run(context.Background(), handler)
+	assert.Error(err)
+	assert.True(broker.closed)
+
+	require.Len(events, 2)
+
+	assert.Equal("builds", events[0].Queue)
+	assert.Equal("events", events[0].Exchange)
+	assert.Equal(map[string]string{"origin": "ci", "attempt": "2"},
+		events[0].Headers)
+	assert.Equal(map[string]interface{}{"status": "success"},
+		events[0].Body)
+
+	assert.Equal([]string{"ack 1", "reject 2", "ack 3"}, broker.acks)
+
+	// If the event cannot be stored, the message is requeued and the
+	// consumer stops.
+	broker = newTestBroker()
+	storeErr = errors.New("database unavailable")
+
+	broker.Publish(4, `{}`)
+
+	err = consumer.Run(context.Background(), handler)
+	assert.ErrorIs(err, storeErr)
+	assert.Equal([]string{"requeue 4"}, broker.acks)
+}
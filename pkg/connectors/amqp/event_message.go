@@ -0,0 +1,19 @@
+package amqp
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+)
+
+type MessageEvent struct {
+	Queue       string            `json:"queue"`
+	Exchange    string            `json:"exchange,omitempty"`
+	RoutingKey  string            `json:"routing_key,omitempty"`
+	MessageId   string            `json:"message_id,omitempty"`
+	ContentType string            `json:"content_type,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        interface{}       `json:"body"`
+}
+
+func MessageEventDef() *eventline.EventDef {
+	return eventline.NewEventDef("message", &MessageEvent{}, &Parameters{})
+}
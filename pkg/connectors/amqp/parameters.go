@@ -0,0 +1,18 @@
+package amqp
+
+import (
+	"github.com/exograd/eventline/pkg/connectors/mq"
+	"go.n16f.net/ejson"
+)
+
+type Parameters struct {
+	URI    string `json:"uri"`
+	Queue  string `json:"queue"`
+	Format string `json:"format,omitempty"`
+}
+
+func (p *Parameters) ValidateJSON(v *ejson.Validator) {
+	v.CheckStringURI("uri", p.URI)
+	v.CheckStringNotEmpty("queue", p.Queue)
+	mq.CheckBodyFormat(v, "format", p.Format)
+}
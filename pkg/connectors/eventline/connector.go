@@ -20,6 +20,8 @@ func NewConnector() *Connector {
 
 	def.AddIdentity(APIKeyIdentityDef())
 
+	def.AddEvent(CustomEventDef())
+
 	return &Connector{
 		Def: def,
 	}
@@ -0,0 +1,42 @@
+package eventline
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
+)
+
+type CustomEvent struct {
+	Name string                 `json:"name"`
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+type CustomEventParameters struct {
+	Name   string                `json:"name"`
+	Schema *eventline.JSONSchema `json:"schema,omitempty"`
+}
+
+func CustomEventDef() *eventline.EventDef {
+	return eventline.NewEventDef("custom",
+		&CustomEvent{}, &CustomEventParameters{})
+}
+
+func (p *CustomEventParameters) ValidateJSON(v *ejson.Validator) {
+	eventline.CheckName(v, "name", p.Name)
+
+	v.CheckOptionalObject("schema", p.Schema)
+}
+
+// CheckData validates the data of a custom event against the schema of the
+// subscription if there is one.
+func (p *CustomEventParameters) CheckData(v *ejson.Validator, token string, data map[string]interface{}) {
+	if p.Schema == nil {
+		return
+	}
+
+	var value interface{} = map[string]interface{}{}
+	if data != nil {
+		value = data
+	}
+
+	p.Schema.CheckValue(v, token, value)
+}
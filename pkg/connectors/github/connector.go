@@ -36,6 +36,10 @@ func NewConnector() *Connector {
 	def.AddEvent(BranchCreationEventDef())
 	def.AddEvent(BranchDeletionEventDef())
 	def.AddEvent(PushEventDef())
+	def.AddEvent(PullRequestEventDef())
+	def.AddEvent(ReleaseEventDef())
+	def.AddEvent(IssueCommentEventDef())
+	def.AddEvent(WorkflowRunEventDef())
 
 	return &Connector{
 		Def: def,
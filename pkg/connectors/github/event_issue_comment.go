@@ -0,0 +1,60 @@
+package github
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/google/go-github/v45/github"
+)
+
+type IssueCommentEvent struct {
+	Organization string `json:"organization"`
+	Repository   string `json:"repository"`
+	Action       string `json:"action"`
+	IssueNumber  int    `json:"issue_number"`
+	IssueTitle   string `json:"issue_title"`
+	PullRequest  bool   `json:"pull_request"`
+	CommentId    int64  `json:"comment_id"`
+	URL          string `json:"url"`
+	Author       string `json:"author"`
+	Body         string `json:"body"`
+}
+
+func IssueCommentEventDef() *eventline.EventDef {
+	return eventline.NewEventDef("issue_comment",
+		&IssueCommentEvent{}, &Parameters{})
+}
+
+func newIssueCommentEvent(e *github.IssueCommentEvent) (*IssueCommentEvent, error) {
+	organization, repository, err := webhookEventRepository(e.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Action == nil {
+		return nil, NewInvalidWebhookEventError("missing action")
+	}
+
+	issue := e.Issue
+	if issue == nil {
+		return nil, NewInvalidWebhookEventError("missing issue")
+	}
+
+	comment := e.Comment
+	if comment == nil {
+		return nil, NewInvalidWebhookEventError("missing comment")
+	}
+
+	eventData := IssueCommentEvent{
+		Organization: organization,
+		Repository:   repository,
+		Action:       *e.Action,
+		IssueNumber:  issue.GetNumber(),
+		IssueTitle:   issue.GetTitle(),
+		PullRequest:  issue.IsPullRequest(),
+		CommentId:    comment.GetID(),
+		URL:          comment.GetHTMLURL(),
+		Author:       comment.GetUser().GetLogin(),
+		Body:         comment.GetBody(),
+	}
+
+	return &eventData, nil
+}
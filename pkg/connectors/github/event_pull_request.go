@@ -0,0 +1,117 @@
+package github
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/google/go-github/v45/github"
+)
+
+// The pull request actions for which pull_request events are created. Merged
+// pull requests are reported with the "merged" action instead of "closed".
+var PullRequestActions = []string{
+	"opened",
+	"synchronize",
+	"closed",
+	"merged",
+	"labeled",
+}
+
+type PullRequestEvent struct {
+	Organization   string   `json:"organization"`
+	Repository     string   `json:"repository"`
+	Action         string   `json:"action"`
+	Number         int      `json:"number"`
+	Title          string   `json:"title"`
+	URL            string   `json:"url"`
+	Author         string   `json:"author"`
+	Draft          bool     `json:"draft"`
+	Labels         []string `json:"labels,omitempty"`
+	Label          string   `json:"label,omitempty"`
+	BaseBranch     string   `json:"base_branch"`
+	BaseRevision   string   `json:"base_revision"`
+	HeadRepository string   `json:"head_repository,omitempty"`
+	HeadBranch     string   `json:"head_branch"`
+	HeadRevision   string   `json:"head_revision"`
+	OldRevision    string   `json:"old_revision,omitempty"`
+	MergeRevision  string   `json:"merge_revision,omitempty"`
+}
+
+func PullRequestEventDef() *eventline.EventDef {
+	return eventline.NewEventDef("pull_request",
+		&PullRequestEvent{}, &Parameters{})
+}
+
+// newPullRequestEvent returns nil if the action of the webhook event is not
+// one of the supported actions.
+func newPullRequestEvent(e *github.PullRequestEvent) (*PullRequestEvent, error) {
+	organization, repository, err := webhookEventRepository(e.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Action == nil {
+		return nil, NewInvalidWebhookEventError("missing action")
+	}
+
+	pr := e.PullRequest
+	if pr == nil {
+		return nil, NewInvalidWebhookEventError("missing pull request")
+	}
+
+	action := *e.Action
+	if action == "closed" && pr.GetMerged() {
+		action = "merged"
+	}
+
+	found := false
+	for _, a := range PullRequestActions {
+		if a == action {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	eventData := PullRequestEvent{
+		Organization: organization,
+		Repository:   repository,
+		Action:       action,
+		Number:       pr.GetNumber(),
+		Title:        pr.GetTitle(),
+		URL:          pr.GetHTMLURL(),
+		Author:       pr.GetUser().GetLogin(),
+		Draft:        pr.GetDraft(),
+		BaseBranch:   pr.GetBase().GetRef(),
+		BaseRevision: pr.GetBase().GetSHA(),
+		HeadBranch:   pr.GetHead().GetRef(),
+		HeadRevision: pr.GetHead().GetSHA(),
+	}
+
+	for _, label := range pr.Labels {
+		eventData.Labels = append(eventData.Labels, label.GetName())
+	}
+
+	if action == "labeled" {
+		eventData.Label = e.GetLabel().GetName()
+	}
+
+	// The head repository is only set if it is different from the base
+	// repository, i.e. for pull requests opened from a fork.
+	if headRepo := pr.GetHead().GetRepo(); headRepo != nil {
+		if headRepo.GetFullName() != e.Repo.GetFullName() {
+			eventData.HeadRepository = headRepo.GetFullName()
+		}
+	}
+
+	if action == "synchronize" {
+		eventData.OldRevision = e.GetBefore()
+	}
+
+	if action == "merged" {
+		eventData.MergeRevision = pr.GetMergeCommitSHA()
+	}
+
+	return &eventData, nil
+}
@@ -0,0 +1,85 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/exograd/eventline/pkg/utils"
+	"github.com/google/go-github/v45/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPullRequestEvent(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	repo := github.Repository{
+		Name:     utils.Ref("bar"),
+		FullName: utils.Ref("foo/bar"),
+		Owner:    &github.User{Login: utils.Ref("foo")},
+	}
+
+	newEvent := func(action string, merged bool) *github.PullRequestEvent {
+		return &github.PullRequestEvent{
+			Action: utils.Ref(action),
+			Repo:   &repo,
+			Label:  &github.Label{Name: utils.Ref("bug")},
+			PullRequest: &github.PullRequest{
+				Number:         utils.Ref(42),
+				Merged:         utils.Ref(merged),
+				MergeCommitSHA: utils.Ref("abc"),
+				Labels:         []*github.Label{{Name: utils.Ref("bug")}},
+				Base: &github.PullRequestBranch{
+					Ref: utils.Ref("main"),
+					SHA: utils.Ref("def"),
+				},
+				Head: &github.PullRequestBranch{
+					Ref: utils.Ref("feature"),
+					SHA: utils.Ref("ghi"),
+					Repo: &github.Repository{
+						FullName: utils.Ref("baz/bar"),
+					},
+				},
+			},
+		}
+	}
+
+	event, err := newPullRequestEvent(newEvent("opened", false))
+	require.NoError(err)
+	require.NotNil(event)
+	assert.Equal("foo", event.Organization)
+	assert.Equal("bar", event.Repository)
+	assert.Equal("opened", event.Action)
+	assert.Equal(42, event.Number)
+	assert.Equal([]string{"bug"}, event.Labels)
+	assert.Equal("", event.Label)
+	assert.Equal("main", event.BaseBranch)
+	assert.Equal("feature", event.HeadBranch)
+	assert.Equal("baz/bar", event.HeadRepository)
+	assert.Equal("", event.MergeRevision)
+
+	event, err = newPullRequestEvent(newEvent("closed", true))
+	require.NoError(err)
+	require.NotNil(event)
+	assert.Equal("merged", event.Action)
+	assert.Equal("abc", event.MergeRevision)
+
+	event, err = newPullRequestEvent(newEvent("closed", false))
+	require.NoError(err)
+	require.NotNil(event)
+	assert.Equal("closed", event.Action)
+
+	event, err = newPullRequestEvent(newEvent("labeled", false))
+	require.NoError(err)
+	require.NotNil(event)
+	assert.Equal("bug", event.Label)
+
+	event, err = newPullRequestEvent(newEvent("assigned", false))
+	require.NoError(err)
+	assert.Nil(event)
+
+	invalidEvent := newEvent("opened", false)
+	invalidEvent.Repo = nil
+	_, err = newPullRequestEvent(invalidEvent)
+	assert.Error(err)
+}
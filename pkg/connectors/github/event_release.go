@@ -0,0 +1,55 @@
+package github
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/google/go-github/v45/github"
+)
+
+type ReleaseEvent struct {
+	Organization string `json:"organization"`
+	Repository   string `json:"repository"`
+	Action       string `json:"action"`
+	Tag          string `json:"tag"`
+	Name         string `json:"name,omitempty"`
+	URL          string `json:"url"`
+	Author       string `json:"author"`
+	Target       string `json:"target"`
+	Draft        bool   `json:"draft"`
+	Prerelease   bool   `json:"prerelease"`
+}
+
+func ReleaseEventDef() *eventline.EventDef {
+	return eventline.NewEventDef("release",
+		&ReleaseEvent{}, &Parameters{})
+}
+
+func newReleaseEvent(e *github.ReleaseEvent) (*ReleaseEvent, error) {
+	organization, repository, err := webhookEventRepository(e.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Action == nil {
+		return nil, NewInvalidWebhookEventError("missing action")
+	}
+
+	release := e.Release
+	if release == nil {
+		return nil, NewInvalidWebhookEventError("missing release")
+	}
+
+	eventData := ReleaseEvent{
+		Organization: organization,
+		Repository:   repository,
+		Action:       *e.Action,
+		Tag:          release.GetTagName(),
+		Name:         release.GetName(),
+		URL:          release.GetHTMLURL(),
+		Author:       release.GetAuthor().GetLogin(),
+		Target:       release.GetTargetCommitish(),
+		Draft:        release.GetDraft(),
+		Prerelease:   release.GetPrerelease(),
+	}
+
+	return &eventData, nil
+}
@@ -0,0 +1,61 @@
+package github
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/google/go-github/v45/github"
+)
+
+type WorkflowRunEvent struct {
+	Organization string `json:"organization"`
+	Repository   string `json:"repository"`
+	Action       string `json:"action"`
+	Workflow     string `json:"workflow"`
+	WorkflowId   int64  `json:"workflow_id"`
+	RunId        int64  `json:"run_id"`
+	RunNumber    int    `json:"run_number"`
+	URL          string `json:"url"`
+	Event        string `json:"event"`
+	Status       string `json:"status"`
+	Conclusion   string `json:"conclusion,omitempty"`
+	Branch       string `json:"branch"`
+	Revision     string `json:"revision"`
+}
+
+func WorkflowRunEventDef() *eventline.EventDef {
+	return eventline.NewEventDef("workflow_run",
+		&WorkflowRunEvent{}, &Parameters{})
+}
+
+func newWorkflowRunEvent(e *github.WorkflowRunEvent) (*WorkflowRunEvent, error) {
+	organization, repository, err := webhookEventRepository(e.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Action == nil {
+		return nil, NewInvalidWebhookEventError("missing action")
+	}
+
+	run := e.WorkflowRun
+	if run == nil {
+		return nil, NewInvalidWebhookEventError("missing workflow run")
+	}
+
+	eventData := WorkflowRunEvent{
+		Organization: organization,
+		Repository:   repository,
+		Action:       *e.Action,
+		Workflow:     run.GetName(),
+		WorkflowId:   run.GetWorkflowID(),
+		RunId:        run.GetID(),
+		RunNumber:    run.GetRunNumber(),
+		URL:          run.GetHTMLURL(),
+		Event:        run.GetEvent(),
+		Status:       run.GetStatus(),
+		Conclusion:   run.GetConclusion(),
+		Branch:       run.GetHeadBranch(),
+		Revision:     run.GetHeadSHA(),
+	}
+
+	return &eventData, nil
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/google/go-github/v45/github"
+)
+
+func (c *Connector) ReportJobExecution(ctx context.Context, report *eventline.JobExecutionReport) error {
+	client, err := c.NewClient(report.Identity)
+	if err != nil {
+		return fmt.Errorf("cannot create client: %w", err)
+	}
+
+	return reportJobExecution(ctx, client, report)
+}
+
+// reportJobExecution creates a commit status for the revision associated
+// with the event which triggered the job execution. Events which are not
+// associated with a revision are ignored.
+func reportJobExecution(ctx context.Context, client *github.Client, report *eventline.JobExecutionReport) error {
+	organization, repository, revision := eventRevision(report.Event)
+	if revision == "" {
+		return nil
+	}
+
+	je := report.JobExecution
+
+	state, description := commitStatusState(je.Status)
+
+	status := github.RepoStatus{
+		State:       github.String(state),
+		TargetURL:   github.String(report.JobExecutionURI),
+		Description: github.String(description),
+		Context:     github.String("eventline/" + je.JobSpec.Name),
+	}
+
+	_, _, err := client.Repositories.CreateStatus(ctx, organization,
+		repository, revision, &status)
+	if err != nil {
+		return fmt.Errorf("cannot create commit status: %w", err)
+	}
+
+	return nil
+}
+
+func eventRevision(event *eventline.Event) (string, string, string) {
+	switch data := event.Data.(type) {
+	case *PushEvent:
+		return data.Organization, data.Repository, data.NewRevision
+	case *BranchCreationEvent:
+		return data.Organization, data.Repository, data.Revision
+	case *TagCreationEvent:
+		return data.Organization, data.Repository, data.Revision
+	case *PullRequestEvent:
+		return data.Organization, data.Repository, data.HeadRevision
+	case *WorkflowRunEvent:
+		return data.Organization, data.Repository, data.Revision
+	}
+
+	return "", "", ""
+}
+
+func commitStatusState(status eventline.JobExecutionStatus) (string, string) {
+	switch status {
+	case eventline.JobExecutionStatusSuccessful:
+		return "success", "Job execution succeeded."
+	case eventline.JobExecutionStatusFailed:
+		return "failure", "Job execution failed."
+	case eventline.JobExecutionStatusAborted:
+		return "error", "Job execution aborted."
+	default:
+		return "pending", "Job execution in progress."
+	}
+}
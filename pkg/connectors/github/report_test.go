@@ -0,0 +1,87 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/google/go-github/v45/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportJobExecution(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	type request struct {
+		Path   string
+		Status github.RepoStatus
+	}
+
+	var requests []request
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			var r request
+
+			r.Path = req.URL.Path
+			if err := json.NewDecoder(req.Body).Decode(&r.Status); err != nil {
+				w.WriteHeader(400)
+				return
+			}
+
+			requests = append(requests, r)
+
+			w.WriteHeader(201)
+			w.Write([]byte("{}"))
+		}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	report := eventline.JobExecutionReport{
+		JobExecution: &eventline.JobExecution{
+			Status:  eventline.JobExecutionStatusStarted,
+			JobSpec: &eventline.JobSpec{Name: "build"},
+		},
+		Event: &eventline.Event{
+			Data: &PushEvent{
+				Organization: "foo",
+				Repository:   "bar",
+				Branch:       "main",
+				NewRevision:  "abc",
+			},
+		},
+		JobExecutionURI: "http://localhost:8087/job_executions/id/1",
+	}
+
+	require.NoError(reportJobExecution(ctx, client, &report))
+
+	report.JobExecution.Status = eventline.JobExecutionStatusFailed
+	require.NoError(reportJobExecution(ctx, client, &report))
+
+	// Events without any revision are not reported
+	report.Event.Data = &RepositoryCreationEvent{
+		Organization: "foo",
+		Repository:   "bar",
+	}
+	require.NoError(reportJobExecution(ctx, client, &report))
+
+	require.Len(requests, 2)
+
+	assert.Equal("/repos/foo/bar/statuses/abc", requests[0].Path)
+	assert.Equal("pending", requests[0].Status.GetState())
+	assert.Equal("eventline/build", requests[0].Status.GetContext())
+	assert.Equal(report.JobExecutionURI, requests[0].Status.GetTargetURL())
+
+	assert.Equal("/repos/foo/bar/statuses/abc", requests[1].Path)
+	assert.Equal("failure", requests[1].Status.GetState())
+}
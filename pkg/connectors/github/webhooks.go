@@ -77,6 +77,18 @@ func (c *Connector) ProcessWebhookRequest(req *http.Request, params *Parameters)
 
 	case *github.PushEvent:
 		return c.processWebhookEventPush(e, params)
+
+	case *github.PullRequestEvent:
+		return c.processWebhookEventPullRequest(e, params)
+
+	case *github.ReleaseEvent:
+		return c.processWebhookEventRelease(e, params)
+
+	case *github.IssueCommentEvent:
+		return c.processWebhookEventIssueComment(e, params)
+
+	case *github.WorkflowRunEvent:
+		return c.processWebhookEventWorkflowRun(e, params)
 	}
 
 	return nil
@@ -266,6 +278,83 @@ func (c *Connector) processWebhookEventPush(e *github.PushEvent, params *Paramet
 	return nil
 }
 
+func (c *Connector) processWebhookEventPullRequest(e *github.PullRequestEvent, params *Parameters) error {
+	eventData, err := newPullRequestEvent(e)
+	if err != nil {
+		return err
+	} else if eventData == nil {
+		return nil
+	}
+
+	if err := c.CreateEvents("pull_request", nil, eventData, params); err != nil {
+		return fmt.Errorf("cannot create event: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Connector) processWebhookEventRelease(e *github.ReleaseEvent, params *Parameters) error {
+	eventData, err := newReleaseEvent(e)
+	if err != nil {
+		return err
+	}
+
+	if err := c.CreateEvents("release", nil, eventData, params); err != nil {
+		return fmt.Errorf("cannot create event: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Connector) processWebhookEventIssueComment(e *github.IssueCommentEvent, params *Parameters) error {
+	eventData, err := newIssueCommentEvent(e)
+	if err != nil {
+		return err
+	}
+
+	err = c.CreateEvents("issue_comment", nil, eventData, params)
+	if err != nil {
+		return fmt.Errorf("cannot create event: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Connector) processWebhookEventWorkflowRun(e *github.WorkflowRunEvent, params *Parameters) error {
+	eventData, err := newWorkflowRunEvent(e)
+	if err != nil {
+		return err
+	}
+
+	err = c.CreateEvents("workflow_run", nil, eventData, params)
+	if err != nil {
+		return fmt.Errorf("cannot create event: %w", err)
+	}
+
+	return nil
+}
+
+// webhookEventRepository returns the organization and repository names of
+// the repository a webhook event is associated with. Some payloads (e.g.
+// releases) do not contain any organization field, so we use the owner of
+// the repository.
+func webhookEventRepository(repo *github.Repository) (string, string, error) {
+	if repo == nil {
+		return "", "", NewInvalidWebhookEventError("missing repository")
+	}
+
+	if repo.Name == nil {
+		return "", "", NewInvalidWebhookEventError("missing repository name")
+	}
+
+	if repo.Owner == nil || repo.Owner.Login == nil {
+		return "", "",
+			NewInvalidWebhookEventError("missing repository owner login")
+	}
+
+	return *repo.Owner.Login, *repo.Name, nil
+}
+
 func (c *Connector) CreateEvents(ename string, eventTime *time.Time, eventData eventline.EventData, params *Parameters) error {
 	return c.Pg.WithTx(func(conn pg.Conn) error {
 		var subs eventline.Subscriptions
@@ -275,15 +364,9 @@ func (c *Connector) CreateEvents(ename string, eventTime *time.Time, eventData e
 			return fmt.Errorf("cannot load subscriptions: %w", err)
 		}
 
-		for _, sub := range subs {
-			event := sub.NewEvent(c.Def.Name, ename, eventTime, eventData)
-
-			if err := event.Insert(conn); err != nil {
-				return fmt.Errorf("cannot insert event: %w", err)
-			}
-		}
-
-		return nil
+		_, err = eventline.DispatchEvent(conn, subs, c.Def.Name, ename,
+			eventTime, eventData)
+		return err
 	})
 }
 
@@ -0,0 +1,186 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type HookId = int64
+
+// There is no official Go client for the GitLab API, and we only need a
+// couple of routes, so we use a minimal client.
+type Client struct {
+	HTTPClient *http.Client
+	BaseURI    *url.URL
+	Header     http.Header
+}
+
+type APIError struct {
+	Method     string
+	URI        string
+	StatusCode int
+	Message    string
+}
+
+func (err *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: request failed with status %d",
+		err.Method, err.URI, err.StatusCode)
+	if err.Message != "" {
+		msg += ": " + err.Message
+	}
+
+	return msg
+}
+
+func IsNotFoundAPIError(err error) bool {
+	var apiErr *APIError
+
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == 404
+	}
+
+	return false
+}
+
+type Hook struct {
+	Id                    HookId `json:"id,omitempty"`
+	URL                   string `json:"url"`
+	Token                 string `json:"token,omitempty"`
+	PushEvents            bool   `json:"push_events"`
+	TagPushEvents         bool   `json:"tag_push_events"`
+	MergeRequestsEvents   bool   `json:"merge_requests_events"`
+	PipelineEvents        bool   `json:"pipeline_events"`
+	EnableSSLVerification bool   `json:"enable_ssl_verification"`
+}
+
+// NewClient returns a client for the API of a GitLab instance, e.g.
+// "https://gitlab.com".
+func NewClient(httpClient *http.Client, uri *url.URL, header http.Header) *Client {
+	baseURI := uri.ResolveReference(&url.URL{Path: "/api/v4/"})
+
+	return &Client{
+		HTTPClient: httpClient,
+		BaseURI:    baseURI,
+		Header:     header,
+	}
+}
+
+func (c *Client) CreateProjectHook(project string, hook *Hook) (*Hook, error) {
+	var hook2 Hook
+
+	path := "projects/" + url.PathEscape(project) + "/hooks"
+	if err := c.SendRequest("POST", path, hook, &hook2); err != nil {
+		return nil, err
+	}
+
+	return &hook2, nil
+}
+
+func (c *Client) DeleteProjectHook(project string, id HookId) error {
+	path := "projects/" + url.PathEscape(project) + "/hooks/" +
+		strconv.FormatInt(id, 10)
+
+	return c.SendRequest("DELETE", path, nil, nil)
+}
+
+func (c *Client) CreateGroupHook(group string, hook *Hook) (*Hook, error) {
+	var hook2 Hook
+
+	path := "groups/" + url.PathEscape(group) + "/hooks"
+	if err := c.SendRequest("POST", path, hook, &hook2); err != nil {
+		return nil, err
+	}
+
+	return &hook2, nil
+}
+
+func (c *Client) DeleteGroupHook(group string, id HookId) error {
+	path := "groups/" + url.PathEscape(group) + "/hooks/" +
+		strconv.FormatInt(id, 10)
+
+	return c.SendRequest("DELETE", path, nil, nil)
+}
+
+func (c *Client) SendRequest(method, path string, reqBody, resBody interface{}) error {
+	// Project and group paths are escaped (e.g. "my-group%2Fmy-project"), so
+	// we cannot use url.URL.Path directly.
+	ref, err := url.Parse(path)
+	if err != nil {
+		return fmt.Errorf("invalid path %q: %w", path, err)
+	}
+
+	uri := c.BaseURI.ResolveReference(ref)
+
+	var body io.Reader
+	if reqBody != nil {
+		data, err := json.Marshal(reqBody)
+		if err != nil {
+			return fmt.Errorf("cannot encode request body: %w", err)
+		}
+
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), method,
+		uri.String(), body)
+	if err != nil {
+		return fmt.Errorf("cannot create request: %w", err)
+	}
+
+	for name, values := range c.Header {
+		req.Header[name] = values
+	}
+
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("cannot read response body: %w", err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		apiErr := APIError{
+			Method:     method,
+			URI:        uri.String(),
+			StatusCode: res.StatusCode,
+		}
+
+		var errBody struct {
+			Message interface{} `json:"message"`
+			Error   string      `json:"error"`
+		}
+
+		if err := json.Unmarshal(data, &errBody); err == nil {
+			if errBody.Message != nil {
+				apiErr.Message = fmt.Sprintf("%v", errBody.Message)
+			} else {
+				apiErr.Message = errBody.Error
+			}
+		}
+
+		return &apiErr
+	}
+
+	if resBody != nil {
+		if err := json.Unmarshal(data, resBody); err != nil {
+			return fmt.Errorf("cannot decode response body: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package gitlab
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
+)
+
+type ConnectorCfg struct {
+	Enabled       bool   `json:"enabled"`
+	URI           string `json:"uri"`
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+}
+
+func (c *Connector) DefaultCfg() eventline.ConnectorCfg {
+	return &ConnectorCfg{
+		URI: "https://gitlab.com",
+	}
+}
+
+func (cfg *ConnectorCfg) ValidateJSON(v *ejson.Validator) {
+	v.CheckStringURI("uri", cfg.URI)
+
+	if cfg.Enabled {
+		v.CheckStringNotEmpty("webhook_secret", cfg.WebhookSecret)
+	}
+}
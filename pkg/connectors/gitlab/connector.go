@@ -0,0 +1,148 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/log"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/service/pkg/shttp"
+)
+
+type Connector struct {
+	Def *eventline.ConnectorDef
+	Cfg *ConnectorCfg
+	Pg  *pg.Client
+	Log *log.Logger
+
+	uri              *url.URL
+	webHTTPServerURI *url.URL
+}
+
+func NewConnector() *Connector {
+	def := eventline.NewConnectorDef("gitlab")
+
+	def.AddIdentity(TokenIdentityDef())
+	def.AddIdentity(OAuth2IdentityDef())
+
+	def.AddEvent(PushEventDef())
+	def.AddEvent(TagPushEventDef())
+	def.AddEvent(MergeRequestEventDef())
+	def.AddEvent(PipelineEventDef())
+
+	return &Connector{
+		Def: def,
+	}
+}
+
+func (c *Connector) Name() string {
+	return "gitlab"
+}
+
+func (c *Connector) Definition() *eventline.ConnectorDef {
+	return c.Def
+}
+
+func (c *Connector) Enabled() bool {
+	return c.Cfg.Enabled
+}
+
+func (c *Connector) Init(ccfg eventline.ConnectorCfg, initData eventline.ConnectorInitData) error {
+	c.Cfg = ccfg.(*ConnectorCfg)
+	c.Pg = initData.Pg
+	c.Log = initData.Log
+
+	uri, err := url.Parse(c.Cfg.URI)
+	if err != nil {
+		return fmt.Errorf("invalid uri %q: %w", c.Cfg.URI, err)
+	}
+	c.uri = uri
+
+	c.webHTTPServerURI = initData.WebHTTPServerURI
+
+	return nil
+}
+
+func (c *Connector) Terminate() {
+}
+
+// InstanceURI returns the URI of the GitLab instance the connector is
+// configured for.
+func InstanceURI() string {
+	c := eventline.GetConnector("gitlab").(*Connector)
+	return c.Cfg.URI
+}
+
+func (c *Connector) Subscribe(conn pg.Conn, sctx *eventline.SubscriptionContext) error {
+	params := sctx.Subscription.Parameters.(*Parameters)
+
+	hookId, err := c.MaybeCreateHook(conn, params, sctx.Identity)
+	if err != nil {
+		return fmt.Errorf("cannot create hook: %w", err)
+	}
+
+	s := Subscription{
+		Id:      sctx.Subscription.Id,
+		Group:   params.Group,
+		Project: params.Project,
+		HookId:  *hookId,
+	}
+
+	if err := s.Insert(conn); err != nil {
+		return fmt.Errorf("cannot insert subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Connector) Unsubscribe(conn pg.Conn, sctx *eventline.SubscriptionContext) error {
+	params := sctx.Subscription.Parameters.(*Parameters)
+
+	var subscription Subscription
+	err := subscription.LoadForUpdate(conn, sctx.Subscription.Id)
+	if err != nil {
+		return fmt.Errorf("cannot load subscription: %w", err)
+	}
+
+	err = c.MaybeDeleteHook(conn, params, sctx.Identity, subscription.HookId)
+	if err != nil {
+		return fmt.Errorf("cannot delete hook: %w", err)
+	}
+
+	if err := subscription.Delete(conn); err != nil {
+		return fmt.Errorf("cannot delete subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Connector) NewClient(identity *eventline.Identity) (*Client, error) {
+	header := make(http.Header)
+
+	if identity != nil {
+		switch idata := identity.Data.(type) {
+		case *TokenIdentity:
+			header.Add("PRIVATE-TOKEN", idata.Token)
+
+		case *OAuth2Identity:
+			header.Add("Authorization", "Bearer "+idata.AccessToken)
+
+		default:
+			return nil, fmt.Errorf("unsupported identity")
+		}
+	}
+
+	httpClientCfg := shttp.ClientCfg{
+		Log:         c.Log,
+		LogRequests: true,
+	}
+
+	httpClient, err := shttp.NewClient(httpClientCfg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create http client: %w", err)
+	}
+
+	return NewClient(httpClient.Client, c.uri, header), nil
+}
@@ -0,0 +1,27 @@
+package gitlab
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+)
+
+type MergeRequestEvent struct {
+	Group         string   `json:"group"`
+	Project       string   `json:"project"`
+	Action        string   `json:"action"`
+	Number        int      `json:"number"`
+	Title         string   `json:"title"`
+	URL           string   `json:"url"`
+	User          string   `json:"user"`
+	State         string   `json:"state"`
+	Draft         bool     `json:"draft"`
+	Labels        []string `json:"labels,omitempty"`
+	SourceBranch  string   `json:"source_branch"`
+	TargetBranch  string   `json:"target_branch"`
+	Revision      string   `json:"revision"`
+	MergeRevision string   `json:"merge_revision,omitempty"`
+}
+
+func MergeRequestEventDef() *eventline.EventDef {
+	return eventline.NewEventDef("merge_request",
+		&MergeRequestEvent{}, &Parameters{})
+}
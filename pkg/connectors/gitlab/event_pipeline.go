@@ -0,0 +1,23 @@
+package gitlab
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+)
+
+type PipelineEvent struct {
+	Group      string `json:"group"`
+	Project    string `json:"project"`
+	PipelineId int64  `json:"pipeline_id"`
+	URL        string `json:"url"`
+	Ref        string `json:"ref"`
+	Tag        bool   `json:"tag"`
+	Revision   string `json:"revision"`
+	Source     string `json:"source"`
+	Status     string `json:"status"`
+	User       string `json:"user"`
+}
+
+func PipelineEventDef() *eventline.EventDef {
+	return eventline.NewEventDef("pipeline",
+		&PipelineEvent{}, &Parameters{})
+}
@@ -0,0 +1,18 @@
+package gitlab
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+)
+
+type PushEvent struct {
+	Group       string `json:"group"`
+	Project     string `json:"project"`
+	Branch      string `json:"branch"`
+	OldRevision string `json:"old_revision,omitempty"`
+	NewRevision string `json:"new_revision"`
+	User        string `json:"user"`
+}
+
+func PushEventDef() *eventline.EventDef {
+	return eventline.NewEventDef("push", &PushEvent{}, &Parameters{})
+}
@@ -0,0 +1,18 @@
+package gitlab
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+)
+
+type TagPushEvent struct {
+	Group    string `json:"group"`
+	Project  string `json:"project"`
+	Action   string `json:"action"`
+	Tag      string `json:"tag"`
+	Revision string `json:"revision"`
+	User     string `json:"user"`
+}
+
+func TagPushEventDef() *eventline.EventDef {
+	return eventline.NewEventDef("tag_push", &TagPushEvent{}, &Parameters{})
+}
@@ -0,0 +1,120 @@
+package gitlab
+
+import (
+	"fmt"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/service/pkg/pg"
+)
+
+// Hooks are shared between subscriptions with the same parameters so that we
+// do not create one hook per job.
+
+func (c *Connector) MaybeCreateHook(conn pg.Conn, params *Parameters, identity *eventline.Identity) (*HookId, error) {
+	if err := LockHooks(conn); err != nil {
+		return nil, err
+	}
+
+	hookId, err := LoadHookIdByParameters(conn, params)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load hook id: %w", err)
+	}
+
+	if hookId != nil {
+		return hookId, nil
+	}
+
+	return c.CreateHook(conn, params, identity)
+}
+
+func (c *Connector) CreateHook(conn pg.Conn, params *Parameters, identity *eventline.Identity) (*HookId, error) {
+	client, err := c.NewClient(identity)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create client: %w", err)
+	}
+
+	return createHook(client, params, c.WebhookURI(params),
+		c.Cfg.WebhookSecret)
+}
+
+func createHook(client *Client, params *Parameters, uri, secret string) (*HookId, error) {
+	hook := Hook{
+		URL:                   uri,
+		Token:                 secret,
+		PushEvents:            true,
+		TagPushEvents:         true,
+		MergeRequestsEvents:   true,
+		PipelineEvents:        true,
+		EnableSSLVerification: true,
+	}
+
+	var hook2 *Hook
+	var err error
+
+	if params.Project == "" {
+		hook2, err = client.CreateGroupHook(params.Group, &hook)
+	} else {
+		hook2, err = client.CreateProjectHook(params.Path(), &hook)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &hook2.Id, nil
+}
+
+func (c *Connector) MaybeDeleteHook(conn pg.Conn, params *Parameters, identity *eventline.Identity, hookId HookId) error {
+	if err := LockHooks(conn); err != nil {
+		return err
+	}
+
+	n, err := CountSubscriptionsByHookId(conn, params, hookId)
+	if err != nil {
+		return fmt.Errorf("cannot count subscriptions: %w", err)
+	}
+
+	if n != 1 {
+		return nil
+	}
+
+	return c.DeleteHook(conn, params, identity, hookId)
+}
+
+func (c *Connector) DeleteHook(conn pg.Conn, params *Parameters, identity *eventline.Identity, hookId HookId) error {
+	client, err := c.NewClient(identity)
+	if err != nil {
+		return fmt.Errorf("cannot create client: %w", err)
+	}
+
+	return deleteHook(client, params, hookId)
+}
+
+func deleteHook(client *Client, params *Parameters, hookId HookId) error {
+	var err error
+
+	if params.Project == "" {
+		err = client.DeleteGroupHook(params.Group, hookId)
+	} else {
+		err = client.DeleteProjectHook(params.Path(), hookId)
+	}
+
+	// If the hook does not exist, it was probably deleted manually; we do
+	// not want the subscription to be stuck in a terminating loop.
+	if err != nil && !IsNotFoundAPIError(err) {
+		return err
+	}
+
+	return nil
+}
+
+func LockHooks(conn pg.Conn) error {
+	id1 := PgAdvisoryLockId1
+	id2 := PgAdvisoryLockId2GitLabHooks
+
+	if err := pg.TakeAdvisoryTxLock(conn, id1, id2); err != nil {
+		return fmt.Errorf("cannot take advisory lock: %w", err)
+	}
+
+	return nil
+}
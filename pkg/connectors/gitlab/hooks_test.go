@@ -0,0 +1,109 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	type request struct {
+		Method string
+		Path   string
+		Token  string
+		Hook   *Hook
+	}
+
+	var requests []request
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			r := request{
+				Method: req.Method,
+				Path:   req.URL.EscapedPath(),
+				Token:  req.Header.Get("PRIVATE-TOKEN"),
+			}
+
+			switch req.Method {
+			case "POST":
+				var hook Hook
+				if err := json.NewDecoder(req.Body).Decode(&hook); err != nil {
+					w.WriteHeader(400)
+					return
+				}
+
+				r.Hook = &hook
+				requests = append(requests, r)
+
+				w.WriteHeader(201)
+				w.Write([]byte(`{"id": 42}`))
+
+			case "DELETE":
+				requests = append(requests, r)
+
+				if req.URL.Path == "/api/v4/groups/foo/hooks/404" {
+					w.WriteHeader(404)
+					w.Write([]byte(`{"message": "404 Not found"}`))
+					return
+				}
+
+				w.WriteHeader(204)
+			}
+		}))
+	defer server.Close()
+
+	serverURI, err := url.Parse(server.URL)
+	require.NoError(err)
+
+	header := make(http.Header)
+	header.Set("PRIVATE-TOKEN", "secret")
+
+	client := NewClient(server.Client(), serverURI, header)
+
+	projectParams := Parameters{Group: "foo/bar", Project: "baz"}
+	groupParams := Parameters{Group: "foo"}
+
+	hookId, err := createHook(client, &projectParams,
+		"http://localhost:8087/ext/connectors/gitlab/hooks/foo%2Fbar:baz",
+		"webhook-secret")
+	require.NoError(err)
+	assert.Equal(HookId(42), *hookId)
+
+	_, err = createHook(client, &groupParams,
+		"http://localhost:8087/ext/connectors/gitlab/hooks/foo",
+		"webhook-secret")
+	require.NoError(err)
+
+	require.NoError(deleteHook(client, &projectParams, 42))
+
+	// Hooks which do not exist anymore are ignored
+	require.NoError(deleteHook(client, &groupParams, 404))
+
+	require.Len(requests, 4)
+
+	assert.Equal("POST", requests[0].Method)
+	assert.Equal("/api/v4/projects/foo%2Fbar%2Fbaz/hooks", requests[0].Path)
+	assert.Equal("secret", requests[0].Token)
+	if assert.NotNil(requests[0].Hook) {
+		assert.Equal("webhook-secret", requests[0].Hook.Token)
+		assert.True(requests[0].Hook.PushEvents)
+		assert.True(requests[0].Hook.MergeRequestsEvents)
+	}
+
+	assert.Equal("POST", requests[1].Method)
+	assert.Equal("/api/v4/groups/foo/hooks", requests[1].Path)
+
+	assert.Equal("DELETE", requests[2].Method)
+	assert.Equal("/api/v4/projects/foo%2Fbar%2Fbaz/hooks/42", requests[2].Path)
+
+	assert.Equal("DELETE", requests[3].Method)
+	assert.Equal("/api/v4/groups/foo/hooks/404", requests[3].Path)
+}
@@ -0,0 +1,239 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/exograd/go-oauth2c"
+	"go.n16f.net/ejson"
+)
+
+type OAuth2Identity struct {
+	Username string `json:"username"`
+
+	ClientId     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	Scopes       []string `json:"scopes"`
+
+	AccessToken    string     `json:"access_token,omitempty"`
+	RefreshToken   string     `json:"refresh_token,omitempty"`
+	TTL            int        `json:"ttl"`
+	ExpirationTime *time.Time `json:"expiration_time,omitempty"`
+}
+
+func OAuth2IdentityDef() *eventline.IdentityDef {
+	def := eventline.NewIdentityDef("oauth2", &OAuth2Identity{})
+	def.DeferredReadiness = true
+	def.Refreshable = true
+	return def
+}
+
+func (i *OAuth2Identity) ValidateJSON(v *ejson.Validator) {
+	v.CheckStringNotEmpty("client_id", i.ClientId)
+	v.CheckStringNotEmpty("client_secret", i.ClientSecret)
+
+	v.CheckArrayNotEmpty("scopes", i.Scopes)
+}
+
+func (i *OAuth2Identity) Def() *eventline.IdentityDataDef {
+	view := eventline.NewIdentityDataDef()
+
+	view.AddEntry(&eventline.IdentityDataEntry{
+		Key:   "username",
+		Label: "Username",
+		Value: i.Username,
+		Type:  eventline.IdentityDataTypeString,
+	})
+
+	view.AddEntry(&eventline.IdentityDataEntry{
+		Key:      "client_id",
+		Label:    "Client id",
+		Value:    i.ClientId,
+		Type:     eventline.IdentityDataTypeString,
+		Verbatim: true,
+	})
+
+	view.AddEntry(&eventline.IdentityDataEntry{
+		Key:      "client_secret",
+		Label:    "Client secret",
+		Value:    i.ClientSecret,
+		Type:     eventline.IdentityDataTypeString,
+		Secret:   true,
+		Verbatim: true,
+	})
+
+	view.AddEntry(&eventline.IdentityDataEntry{
+		Key:                   "scopes",
+		Label:                 "Scopes",
+		Value:                 i.Scopes,
+		Type:                  eventline.IdentityDataTypeEnumList,
+		EnumValues:            OAuth2Scopes(),
+		PreselectedEnumValues: RequiredOAuth2Scopes(),
+		MultiselectEnumSize:   8,
+	})
+
+	view.AddEntry(&eventline.IdentityDataEntry{
+		Key:      "access_token",
+		Label:    "Access token",
+		Value:    i.AccessToken,
+		Type:     eventline.IdentityDataTypeString,
+		Optional: true,
+		Secret:   true,
+		Verbatim: true,
+		Internal: true,
+	})
+
+	view.AddEntry(&eventline.IdentityDataEntry{
+		Key:      "refresh_token",
+		Label:    "Refresh token",
+		Value:    i.RefreshToken,
+		Type:     eventline.IdentityDataTypeString,
+		Optional: true,
+		Secret:   true,
+		Verbatim: true,
+		Internal: true,
+	})
+
+	view.AddEntry(&eventline.IdentityDataEntry{
+		Key:      "expiration_date",
+		Label:    "Expiration date",
+		Value:    i.ExpirationTime,
+		Type:     eventline.IdentityDataTypeDate,
+		Optional: true,
+		Internal: true,
+	})
+
+	return view
+}
+
+func (i *OAuth2Identity) RedirectionURI(httpClient *http.Client, state, redirectionURI string) (string, error) {
+	client, err := i.newOAuth2Client(httpClient)
+	if err != nil {
+		return "", fmt.Errorf("cannot create oauth2 client: %w", err)
+	}
+
+	req := oauth2c.AuthorizeRequest{
+		RedirectURI: redirectionURI,
+		State:       state,
+		Scope:       i.Scopes,
+	}
+
+	uri := client.AuthorizeURL("code", &req)
+
+	return uri.String(), nil
+}
+
+func (i *OAuth2Identity) FetchTokenData(httpClient *http.Client, code, redirectionURI string) error {
+	client, err := i.newOAuth2Client(httpClient)
+	if err != nil {
+		return fmt.Errorf("cannot create oauth2 client: %w", err)
+	}
+
+	req := oauth2c.TokenCodeRequest{
+		Code:        code,
+		RedirectURI: redirectionURI,
+	}
+
+	res, err := client.Token(context.Background(), "authorization_code", &req)
+	if err != nil {
+		return err
+	}
+
+	i.setTokenData(res)
+
+	return nil
+}
+
+func (i *OAuth2Identity) Refresh(httpClient *http.Client) error {
+	if i.RefreshToken == "" {
+		return fmt.Errorf("missing refresh token")
+	}
+
+	client, err := i.newOAuth2Client(httpClient)
+	if err != nil {
+		return fmt.Errorf("cannot create oauth2 client: %w", err)
+	}
+
+	req := oauth2c.TokenRefreshRequest{
+		RefreshToken: i.RefreshToken,
+	}
+
+	res, err := client.Token(context.Background(), "refresh_token", &req)
+	if err != nil {
+		return err
+	}
+
+	i.setTokenData(res)
+
+	return nil
+}
+
+func (i *OAuth2Identity) setTokenData(res *oauth2c.TokenResponse) {
+	// GitLab access tokens expire after two hours and must be refreshed
+	ttl := time.Duration(res.ExpiresIn) * time.Second
+	expirationTime := time.Now().UTC().Add(ttl)
+
+	i.AccessToken = res.AccessToken
+	i.RefreshToken = res.RefreshToken
+	i.TTL = int(res.ExpiresIn)
+	i.ExpirationTime = &expirationTime
+}
+
+func (i *OAuth2Identity) RefreshTime() time.Time {
+	now := time.Now().UTC()
+
+	halfTTL := time.Duration(math.Ceil(float64(i.TTL)/2.0)) * time.Second
+
+	return now.Add(halfTTL)
+}
+
+func (i *OAuth2Identity) newOAuth2Client(httpClient *http.Client) (*oauth2c.Client, error) {
+	// OAuth2 endpoints depend on the GitLab instance configured for the
+	// connector.
+	issuer := strings.TrimRight(InstanceURI(), "/")
+
+	options := oauth2c.Options{
+		HTTPClient: httpClient,
+
+		AuthorizationEndpoint: issuer + "/oauth/authorize",
+		TokenEndpoint:         issuer + "/oauth/token",
+	}
+
+	return oauth2c.NewClient(issuer, i.ClientId, i.ClientSecret, &options)
+}
+
+func (i *OAuth2Identity) Environment() map[string]string {
+	return map[string]string{
+		"GITLAB_USER":  i.Username,
+		"GITLAB_TOKEN": i.AccessToken,
+	}
+}
+
+func OAuth2Scopes() []string {
+	// See
+	// https://docs.gitlab.com/ee/integration/oauth_provider.html#authorized-applications
+	return []string{
+		"api",
+		"read_api",
+		"read_user",
+		"read_repository",
+		"write_repository",
+		"read_registry",
+		"write_registry",
+		"sudo",
+		"openid",
+		"profile",
+		"email",
+	}
+}
+
+func RequiredOAuth2Scopes() []string {
+	return []string{
+		"api",
+	}
+}
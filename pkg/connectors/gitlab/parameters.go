@@ -0,0 +1,45 @@
+package gitlab
+
+import (
+	"strings"
+
+	"go.n16f.net/ejson"
+)
+
+type Parameters struct {
+	Group   string `json:"group"`
+	Project string `json:"project,omitempty"`
+}
+
+func (p *Parameters) ValidateJSON(v *ejson.Validator) {
+	v.CheckStringNotEmpty("group", p.Group)
+}
+
+// The full path of the project or group the subscription applies to, e.g.
+// "my-group/my-subgroup/my-project".
+func (p *Parameters) Path() string {
+	if p.Project == "" {
+		return p.Group
+	} else {
+		return p.Group + "/" + p.Project
+	}
+}
+
+func (p *Parameters) Target() string {
+	if p.Project == "" {
+		return p.Group
+	} else {
+		return p.Group + ":" + p.Project
+	}
+}
+
+func (p *Parameters) ParseTarget(s string) {
+	idx := strings.IndexByte(s, ':')
+	if idx == -1 {
+		p.Group = s
+		p.Project = ""
+	} else {
+		p.Group = s[:idx]
+		p.Project = s[idx+1:]
+	}
+}
@@ -0,0 +1,8 @@
+package gitlab
+
+// Note that the service and the github connector use 0x0101.
+const PgAdvisoryLockId1 uint32 = 0x0102
+
+const (
+	PgAdvisoryLockId2GitLabHooks uint32 = 0x0001
+)
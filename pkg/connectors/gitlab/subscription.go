@@ -0,0 +1,112 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+type UnknownSubscriptionError struct {
+	Id uuid.UUID
+}
+
+func (err UnknownSubscriptionError) Error() string {
+	return fmt.Sprintf("unknown subscription %q", err.Id)
+}
+
+type Subscription struct {
+	Id      uuid.UUID
+	Group   string
+	Project string // empty for group subscriptions
+	HookId  HookId // either a group hook or a project hook
+}
+
+// Contrary to GitHub organization hooks, GitLab group hooks are only
+// available in paid tiers, so we do not use group hooks for project
+// subscriptions: a hook is shared only by subscriptions with the exact same
+// parameters.
+
+func LoadHookIdByParameters(conn pg.Conn, params *Parameters) (*HookId, error) {
+	ctx := context.Background()
+
+	query := `
+SELECT hook_id
+  FROM c_gitlab_subscriptions
+  WHERE group_path = $1
+    AND project = $2
+  LIMIT 1
+`
+	var hookId HookId
+	err := conn.QueryRow(ctx, query, params.Group, params.Project).
+		Scan(&hookId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &hookId, nil
+}
+
+func (s *Subscription) LoadForUpdate(conn pg.Conn, id uuid.UUID) error {
+	query := `
+SELECT id, group_path, project, hook_id
+  FROM c_gitlab_subscriptions
+  WHERE id = $1
+  FOR UPDATE;
+`
+	err := pg.QueryObject(conn, s, query, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &UnknownSubscriptionError{Id: id}
+	}
+
+	return err
+}
+
+func CountSubscriptionsByHookId(conn pg.Conn, params *Parameters, hookId HookId) (int64, error) {
+	ctx := context.Background()
+
+	// Group and project hook identifiers are not guaranteed to be unique
+	// across groups and projects.
+	query := `
+SELECT COUNT(*)
+  FROM c_gitlab_subscriptions
+  WHERE group_path = $1
+    AND project = $2
+    AND hook_id = $3
+`
+	var count int64
+	err := conn.QueryRow(ctx, query, params.Group, params.Project, hookId).
+		Scan(&count)
+	if err != nil {
+		return -1, err
+	}
+
+	return count, nil
+}
+
+func (s *Subscription) Insert(conn pg.Conn) error {
+	query := `
+INSERT INTO c_gitlab_subscriptions
+    (id, group_path, project, hook_id)
+  VALUES
+    ($1, $2, $3, $4);
+`
+	return pg.Exec(conn, query, s.Id, s.Group, s.Project, s.HookId)
+}
+
+func (s *Subscription) Delete(conn pg.Conn) error {
+	query := `
+DELETE FROM c_gitlab_subscriptions
+  WHERE id = $1;
+`
+	return pg.Exec(conn, query, s.Id)
+}
+
+func (s *Subscription) FromRow(row pgx.Row) error {
+	return row.Scan(&s.Id, &s.Group, &s.Project, &s.HookId)
+}
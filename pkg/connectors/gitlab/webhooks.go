@@ -0,0 +1,376 @@
+package gitlab
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/service/pkg/pg"
+)
+
+const zeroRevision = "0000000000000000000000000000000000000000"
+
+type InvalidWebhookEventError struct {
+	Msg string
+}
+
+func NewInvalidWebhookEventError(format string, args ...interface{}) *InvalidWebhookEventError {
+	return &InvalidWebhookEventError{Msg: fmt.Sprintf(format, args...)}
+}
+
+func (err *InvalidWebhookEventError) Error() string {
+	return fmt.Sprintf("invalid webhook event: %s", err.Msg)
+}
+
+// See https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html
+// for the content of webhook payloads. We only decode the fields we use.
+
+type webhookProject struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+}
+
+type webhookUser struct {
+	Username string `json:"username"`
+}
+
+type webhookLabel struct {
+	Title string `json:"title"`
+}
+
+type pushWebhookEvent struct {
+	Ref          string          `json:"ref"`
+	Before       string          `json:"before"`
+	After        string          `json:"after"`
+	UserUsername string          `json:"user_username"`
+	Project      *webhookProject `json:"project"`
+}
+
+type mergeRequestWebhookEvent struct {
+	User             *webhookUser    `json:"user"`
+	Project          *webhookProject `json:"project"`
+	Labels           []webhookLabel  `json:"labels"`
+	ObjectAttributes *struct {
+		IId            int    `json:"iid"`
+		Title          string `json:"title"`
+		URL            string `json:"url"`
+		State          string `json:"state"`
+		Action         string `json:"action"`
+		Draft          bool   `json:"draft"`
+		SourceBranch   string `json:"source_branch"`
+		TargetBranch   string `json:"target_branch"`
+		MergeCommitSHA string `json:"merge_commit_sha"`
+		LastCommit     *struct {
+			Id string `json:"id"`
+		} `json:"last_commit"`
+	} `json:"object_attributes"`
+}
+
+type pipelineWebhookEvent struct {
+	User             *webhookUser    `json:"user"`
+	Project          *webhookProject `json:"project"`
+	ObjectAttributes *struct {
+		Id     int64  `json:"id"`
+		URL    string `json:"url"`
+		Ref    string `json:"ref"`
+		Tag    bool   `json:"tag"`
+		SHA    string `json:"sha"`
+		Source string `json:"source"`
+		Status string `json:"status"`
+	} `json:"object_attributes"`
+}
+
+func (c *Connector) WebhookURI(params *Parameters) string {
+	targetPart := url.PathEscape(params.Target())
+	path := "/ext/connectors/gitlab/hooks/" + targetPart
+	uri := c.webHTTPServerURI.ResolveReference(&url.URL{Path: path})
+	return uri.String()
+}
+
+func (c *Connector) ProcessWebhookRequest(req *http.Request, params *Parameters) error {
+	token := []byte(req.Header.Get("X-Gitlab-Token"))
+	secret := []byte(c.Cfg.WebhookSecret)
+
+	if subtle.ConstantTimeCompare(token, secret) != 1 {
+		return fmt.Errorf("invalid webhook token")
+	}
+
+	payload, err := io.ReadAll(req.Body)
+	if err != nil {
+		return fmt.Errorf("cannot read payload: %w", err)
+	}
+
+	eventType := req.Header.Get("X-Gitlab-Event")
+
+	ename, eventData, err := ParseWebhookEvent(eventType, payload)
+	if err != nil {
+		return err
+	} else if eventData == nil {
+		return nil
+	}
+
+	if err := c.CreateEvents(ename, nil, eventData, params); err != nil {
+		return fmt.Errorf("cannot create event: %w", err)
+	}
+
+	return nil
+}
+
+// ParseWebhookEvent decodes a webhook payload and returns the name and data
+// of the event to create. It returns nil event data for payloads which are
+// not associated with any event.
+func ParseWebhookEvent(eventType string, payload []byte) (string, eventline.EventData, error) {
+	var ename string
+	var eventData eventline.EventData
+	var err error
+
+	switch eventType {
+	case "Push Hook":
+		ename = "push"
+		eventData, err = parseWebhookEventPush(payload)
+
+	case "Tag Push Hook":
+		ename = "tag_push"
+		eventData, err = parseWebhookEventTagPush(payload)
+
+	case "Merge Request Hook":
+		ename = "merge_request"
+		eventData, err = parseWebhookEventMergeRequest(payload)
+
+	case "Pipeline Hook":
+		ename = "pipeline"
+		eventData, err = parseWebhookEventPipeline(payload)
+
+	default:
+		return "", nil, nil
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return ename, eventData, nil
+}
+
+func parseWebhookEventPush(payload []byte) (eventline.EventData, error) {
+	const headsRefPrefix = "refs/heads/"
+
+	var e pushWebhookEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, NewInvalidWebhookEventError("%v", err)
+	}
+
+	group, project, err := webhookEventProject(e.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(e.Ref, headsRefPrefix) {
+		return nil, NewInvalidWebhookEventError("invalid ref %q", e.Ref)
+	}
+
+	// Branch deletions are not reported as push events
+	if e.After == zeroRevision {
+		return nil, nil
+	}
+
+	eventData := PushEvent{
+		Group:       group,
+		Project:     project,
+		Branch:      e.Ref[len(headsRefPrefix):],
+		NewRevision: e.After,
+		User:        e.UserUsername,
+	}
+
+	// Branch creations do not have a previous revision
+	if e.Before != zeroRevision {
+		eventData.OldRevision = e.Before
+	}
+
+	return &eventData, nil
+}
+
+func parseWebhookEventTagPush(payload []byte) (eventline.EventData, error) {
+	const tagsRefPrefix = "refs/tags/"
+
+	var e pushWebhookEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, NewInvalidWebhookEventError("%v", err)
+	}
+
+	group, project, err := webhookEventProject(e.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(e.Ref, tagsRefPrefix) {
+		return nil, NewInvalidWebhookEventError("invalid ref %q", e.Ref)
+	}
+
+	eventData := TagPushEvent{
+		Group:   group,
+		Project: project,
+		Tag:     e.Ref[len(tagsRefPrefix):],
+		User:    e.UserUsername,
+	}
+
+	if e.After == zeroRevision {
+		eventData.Action = "deleted"
+		eventData.Revision = e.Before
+	} else {
+		eventData.Action = "created"
+		eventData.Revision = e.After
+	}
+
+	return &eventData, nil
+}
+
+func parseWebhookEventMergeRequest(payload []byte) (eventline.EventData, error) {
+	var e mergeRequestWebhookEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, NewInvalidWebhookEventError("%v", err)
+	}
+
+	group, project, err := webhookEventProject(e.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	mr := e.ObjectAttributes
+	if mr == nil {
+		return nil, NewInvalidWebhookEventError("missing object attributes")
+	}
+
+	eventData := MergeRequestEvent{
+		Group:        group,
+		Project:      project,
+		Action:       mr.Action,
+		Number:       mr.IId,
+		Title:        mr.Title,
+		URL:          mr.URL,
+		State:        mr.State,
+		Draft:        mr.Draft,
+		SourceBranch: mr.SourceBranch,
+		TargetBranch: mr.TargetBranch,
+	}
+
+	if e.User != nil {
+		eventData.User = e.User.Username
+	}
+
+	for _, label := range e.Labels {
+		eventData.Labels = append(eventData.Labels, label.Title)
+	}
+
+	if mr.LastCommit != nil {
+		eventData.Revision = mr.LastCommit.Id
+	}
+
+	if mr.Action == "merge" {
+		eventData.MergeRevision = mr.MergeCommitSHA
+	}
+
+	return &eventData, nil
+}
+
+func parseWebhookEventPipeline(payload []byte) (eventline.EventData, error) {
+	var e pipelineWebhookEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, NewInvalidWebhookEventError("%v", err)
+	}
+
+	group, project, err := webhookEventProject(e.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := e.ObjectAttributes
+	if pipeline == nil {
+		return nil, NewInvalidWebhookEventError("missing object attributes")
+	}
+
+	eventData := PipelineEvent{
+		Group:      group,
+		Project:    project,
+		PipelineId: pipeline.Id,
+		URL:        pipeline.URL,
+		Ref:        pipeline.Ref,
+		Tag:        pipeline.Tag,
+		Revision:   pipeline.SHA,
+		Source:     pipeline.Source,
+		Status:     pipeline.Status,
+	}
+
+	// Older GitLab versions do not include the URL of the pipeline
+	if eventData.URL == "" && e.Project.WebURL != "" {
+		eventData.URL = fmt.Sprintf("%s/-/pipelines/%d",
+			e.Project.WebURL, pipeline.Id)
+	}
+
+	if e.User != nil {
+		eventData.User = e.User.Username
+	}
+
+	return &eventData, nil
+}
+
+// webhookEventProject returns the path of the group (including subgroups)
+// and the name of the project a webhook event is associated with.
+func webhookEventProject(p *webhookProject) (string, string, error) {
+	if p == nil {
+		return "", "", NewInvalidWebhookEventError("missing project")
+	}
+
+	path := p.PathWithNamespace
+
+	idx := strings.LastIndexByte(path, '/')
+	if idx <= 0 || idx == len(path)-1 {
+		return "", "", NewInvalidWebhookEventError("invalid project path %q",
+			path)
+	}
+
+	return path[:idx], path[idx+1:], nil
+}
+
+func (c *Connector) CreateEvents(ename string, eventTime *time.Time, eventData eventline.EventData, params *Parameters) error {
+	return c.Pg.WithTx(func(conn pg.Conn) error {
+		var subs eventline.Subscriptions
+
+		subs, err := LoadSubscriptionsByParams(conn, ename, params)
+		if err != nil {
+			return fmt.Errorf("cannot load subscriptions: %w", err)
+		}
+
+		_, err = eventline.DispatchEvent(conn, subs, c.Def.Name, ename,
+			eventTime, eventData)
+		return err
+	})
+}
+
+func LoadSubscriptionsByParams(conn pg.Conn, ename string, params *Parameters) (eventline.Subscriptions, error) {
+	query := `
+SELECT es.id, es.project_id, es.job_id, es.identity_id, es.connector, es.event,
+       es.parameters, es.creation_time, es.status, es.update_delay,
+       es.last_update_time, es.next_update_time
+  FROM subscriptions AS es
+  JOIN c_gitlab_subscriptions AS gs ON gs.id = es.id
+  WHERE es.connector = 'gitlab'
+    AND es.event = $1
+    AND gs.group_path = $2
+    AND gs.project = $3
+`
+	var subs eventline.Subscriptions
+	err := pg.QueryObjects(conn, &subs, query,
+		ename, params.Group, params.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	return subs, nil
+}
@@ -0,0 +1,143 @@
+package gitlab
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWebhookEvent(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	const rev1 = "95790bf891e76fee5e1747ab589903a6a1f80f22"
+	const rev2 = "da1560886d4f094c3e6c9ef40349f7d38b5d27d7"
+
+	parse := func(eventType, payload string) (string, interface{}) {
+		ename, eventData, err := ParseWebhookEvent(eventType, []byte(payload))
+		require.NoError(err)
+		return ename, eventData
+	}
+
+	// Push
+	ename, eventData := parse("Push Hook", `{
+  "object_kind": "push",
+  "ref": "refs/heads/main",
+  "before": "`+rev1+`",
+  "after": "`+rev2+`",
+  "user_username": "jsmith",
+  "project": {"path_with_namespace": "foo/bar/baz"}
+}`)
+	assert.Equal("push", ename)
+	assert.Equal(&PushEvent{
+		Group:       "foo/bar",
+		Project:     "baz",
+		Branch:      "main",
+		OldRevision: rev1,
+		NewRevision: rev2,
+		User:        "jsmith",
+	}, eventData)
+
+	// Branch deletion
+	_, eventData = parse("Push Hook", `{
+  "ref": "refs/heads/main",
+  "before": "`+rev1+`",
+  "after": "`+zeroRevision+`",
+  "project": {"path_with_namespace": "foo/baz"}
+}`)
+	assert.Nil(eventData)
+
+	// Tag push
+	ename, eventData = parse("Tag Push Hook", `{
+  "object_kind": "tag_push",
+  "ref": "refs/tags/v1.0.0",
+  "before": "`+zeroRevision+`",
+  "after": "`+rev2+`",
+  "user_username": "jsmith",
+  "project": {"path_with_namespace": "foo/baz"}
+}`)
+	assert.Equal("tag_push", ename)
+	assert.Equal(&TagPushEvent{
+		Group:    "foo",
+		Project:  "baz",
+		Action:   "created",
+		Tag:      "v1.0.0",
+		Revision: rev2,
+		User:     "jsmith",
+	}, eventData)
+
+	// Merge request
+	ename, eventData = parse("Merge Request Hook", `{
+  "object_kind": "merge_request",
+  "user": {"username": "jsmith"},
+  "project": {"path_with_namespace": "foo/baz"},
+  "labels": [{"title": "bug"}],
+  "object_attributes": {
+    "iid": 3,
+    "title": "Fix the bug",
+    "url": "https://gitlab.com/foo/baz/-/merge_requests/3",
+    "state": "merged",
+    "action": "merge",
+    "source_branch": "fix",
+    "target_branch": "main",
+    "merge_commit_sha": "`+rev1+`",
+    "last_commit": {"id": "`+rev2+`"}
+  }
+}`)
+	assert.Equal("merge_request", ename)
+	assert.Equal(&MergeRequestEvent{
+		Group:         "foo",
+		Project:       "baz",
+		Action:        "merge",
+		Number:        3,
+		Title:         "Fix the bug",
+		URL:           "https://gitlab.com/foo/baz/-/merge_requests/3",
+		User:          "jsmith",
+		State:         "merged",
+		Labels:        []string{"bug"},
+		SourceBranch:  "fix",
+		TargetBranch:  "main",
+		Revision:      rev2,
+		MergeRevision: rev1,
+	}, eventData)
+
+	// Pipeline
+	ename, eventData = parse("Pipeline Hook", `{
+  "object_kind": "pipeline",
+  "user": {"username": "jsmith"},
+  "project": {
+    "path_with_namespace": "foo/baz",
+    "web_url": "https://gitlab.com/foo/baz"
+  },
+  "object_attributes": {
+    "id": 31,
+    "ref": "main",
+    "tag": false,
+    "sha": "`+rev2+`",
+    "source": "push",
+    "status": "success"
+  }
+}`)
+	assert.Equal("pipeline", ename)
+	assert.Equal(&PipelineEvent{
+		Group:      "foo",
+		Project:    "baz",
+		PipelineId: 31,
+		URL:        "https://gitlab.com/foo/baz/-/pipelines/31",
+		Ref:        "main",
+		Revision:   rev2,
+		Source:     "push",
+		Status:     "success",
+		User:       "jsmith",
+	}, eventData)
+
+	// Unsupported events
+	_, eventData = parse("Note Hook", `{}`)
+	assert.Nil(eventData)
+
+	// Invalid events
+	_, _, err := ParseWebhookEvent("Push Hook",
+		[]byte(`{"ref": "refs/heads/main"}`))
+	assert.Error(err)
+}
@@ -0,0 +1,169 @@
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exograd/eventline/pkg/connectors/mq"
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/segmentio/kafka-go"
+)
+
+type Reader interface {
+	FetchMessage(context.Context) (kafka.Message, error)
+	CommitMessages(context.Context, ...kafka.Message) error
+	Close() error
+}
+
+type Consumer struct {
+	Parameters *Parameters
+	Identity   *PasswordIdentity
+
+	openReader func() (Reader, error)
+}
+
+// Kafka does not support acknowledging or rejecting individual messages:
+// offsets are committed for each partition. Since messages are processed in
+// order and the consumer stops as soon as a message cannot be stored, messages
+// which were not committed are delivered again when the consumer restarts.
+type Message struct {
+	Message kafka.Message
+	Format  string
+
+	ctx    context.Context
+	reader Reader
+}
+
+func (c *Connector) NewConsumer(sub *eventline.Subscription, identity *eventline.Identity) (mq.Consumer, error) {
+	consumer := Consumer{
+		Parameters: sub.Parameters.(*Parameters),
+	}
+
+	if identity != nil {
+		idata, ok := identity.Data.(*PasswordIdentity)
+		if !ok {
+			return nil, fmt.Errorf("unsupported identity")
+		}
+
+		consumer.Identity = idata
+	}
+
+	consumer.openReader = consumer.newReader
+
+	return &consumer, nil
+}
+
+func (c *Consumer) Run(ctx context.Context, handler mq.Handler) error {
+	reader, err := c.openReader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for {
+		kmsg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("cannot fetch message: %w", err)
+		}
+
+		msg := Message{
+			Message: kmsg,
+			Format:  c.Parameters.Format,
+
+			ctx:    ctx,
+			reader: reader,
+		}
+
+		if err := handler(&msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Consumer) newReader() (Reader, error) {
+	dialer := kafka.Dialer{
+		Timeout:   10 * time.Second,
+		DualStack: true,
+	}
+
+	if c.Parameters.TLS {
+		dialer.TLS = &tls.Config{}
+	}
+
+	if c.Identity != nil {
+		mechanism, err := c.Identity.SASLMechanism()
+		if err != nil {
+			return nil, err
+		}
+
+		dialer.SASLMechanism = mechanism
+	}
+
+	cfg := kafka.ReaderConfig{
+		Brokers: c.Parameters.Brokers,
+		GroupID: c.Parameters.GroupId(),
+		Topic:   c.Parameters.Topic,
+		Dialer:  &dialer,
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid reader configuration: %w", err)
+	}
+
+	return kafka.NewReader(cfg), nil
+}
+
+func (m *Message) Decode() (*mq.Delivery, error) {
+	kmsg := m.Message
+
+	body, err := mq.DecodeBody(m.Format, kmsg.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	var eventTime *time.Time
+	if !kmsg.Time.IsZero() {
+		t := kmsg.Time.UTC()
+		eventTime = &t
+	}
+
+	var headers map[string][]byte
+	if len(kmsg.Headers) > 0 {
+		headers = make(map[string][]byte, len(kmsg.Headers))
+		for _, header := range kmsg.Headers {
+			headers[header.Key] = header.Value
+		}
+	}
+
+	event := MessageEvent{
+		Topic:     kmsg.Topic,
+		Partition: kmsg.Partition,
+		Offset:    kmsg.Offset,
+		Key:       strings.ToValidUTF8(string(kmsg.Key), "�"),
+		Headers:   mq.StringHeaders(headers),
+		Body:      body,
+	}
+
+	return &mq.Delivery{EventTime: eventTime, Data: &event}, nil
+}
+
+func (m *Message) Ack() error {
+	return m.reader.CommitMessages(m.ctx, m.Message)
+}
+
+func (m *Message) Requeue() error {
+	return nil
+}
+
+// Reject commits the offset of the message so that it is not delivered again.
+func (m *Message) Reject() error {
+	return m.reader.CommitMessages(m.ctx, m.Message)
+}
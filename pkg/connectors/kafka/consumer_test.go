@@ -0,0 +1,130 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/exograd/eventline/pkg/connectors/mq"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.n16f.net/log"
+)
+
+// testBroker is an in-process stand-in for a Kafka consumer group reader
+// which delivers messages of a single partition and records committed
+// offsets.
+type testBroker struct {
+	messages []kafka.Message
+	position int
+	commits  []int64
+	closed   bool
+}
+
+func (b *testBroker) Publish(value string) {
+	b.messages = append(b.messages, kafka.Message{
+		Topic:     "builds",
+		Partition: 0,
+		Offset:    int64(len(b.messages)),
+		Key:       []byte("main"),
+		Value:     []byte(value),
+		Headers:   []kafka.Header{{Key: "origin", Value: []byte("ci")}},
+		Time:      time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC),
+	})
+}
+
+func (b *testBroker) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	if b.position >= len(b.messages) {
+		return kafka.Message{}, context.Canceled
+	}
+
+	msg := b.messages[b.position]
+	b.position++
+
+	return msg, nil
+}
+
+func (b *testBroker) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	for _, msg := range msgs {
+		b.commits = append(b.commits, msg.Offset)
+	}
+
+	return nil
+}
+
+func (b *testBroker) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestConsumer(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	broker := testBroker{}
+
+	consumer := Consumer{
+		Parameters: &Parameters{Topic: "builds"},
+		openReader: func() (Reader, error) {
+			return &broker, nil
+		},
+	}
+
+	logger := log.DefaultLogger("kafka")
+
+	var events []*MessageEvent
+	var storeErr error
+
+	handler := func(msg mq.Message) error {
+		return mq.ProcessMessage(logger, msg, func(d *mq.Delivery) error {
+			if storeErr != nil {
+				return storeErr
+			}
+
+			// Offsets must not be committed before the event is stored
+			event := d.Data.(*MessageEvent)
+			assert.NotContains(broker.commits, event.Offset)
+
+			assert.Equal(time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC),
+				*d.EventTime)
+
+			events = append(events, event)
+			return nil
+		})
+	}
+
+	broker.Publish(`{"status": "success"}`)
+	broker.Publish(`not json`)
+	broker.Publish(`{"status": "failure"}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := consumer.Run(ctx, handler)
+	assert.NoError(err)
+	assert.True(broker.closed)
+
+	require.Len(events, 2)
+
+	assert.Equal("builds", events[0].Topic)
+	assert.Equal("main", events[0].Key)
+	assert.Equal(map[string]string{"origin": "ci"}, events[0].Headers)
+	assert.Equal(map[string]interface{}{"status": "success"},
+		events[0].Body)
+
+	// Invalid messages are committed so that they are not delivered again
+	assert.Equal([]int64{0, 1, 2}, broker.commits)
+
+	// If the event cannot be stored, the offset is not committed and the
+	// consumer stops.
+	broker = testBroker{}
+	storeErr = errors.New("database unavailable")
+
+	broker.Publish(`{}`)
+
+	err = consumer.Run(context.Background(), handler)
+	assert.ErrorIs(err, storeErr)
+	assert.Empty(broker.commits)
+}
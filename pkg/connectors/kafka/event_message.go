@@ -0,0 +1,18 @@
+package kafka
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+)
+
+type MessageEvent struct {
+	Topic     string            `json:"topic"`
+	Partition int               `json:"partition"`
+	Offset    int64             `json:"offset"`
+	Key       string            `json:"key,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Body      interface{}       `json:"body"`
+}
+
+func MessageEventDef() *eventline.EventDef {
+	return eventline.NewEventDef("message", &MessageEvent{}, &Parameters{})
+}
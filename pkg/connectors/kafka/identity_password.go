@@ -0,0 +1,92 @@
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	"go.n16f.net/ejson"
+)
+
+type SASLMechanism string
+
+const (
+	SASLMechanismPlain       SASLMechanism = "plain"
+	SASLMechanismSCRAMSHA256 SASLMechanism = "scram-sha-256"
+	SASLMechanismSCRAMSHA512 SASLMechanism = "scram-sha-512"
+)
+
+var SASLMechanismValues = []SASLMechanism{
+	SASLMechanismPlain,
+	SASLMechanismSCRAMSHA256,
+	SASLMechanismSCRAMSHA512,
+}
+
+type PasswordIdentity struct {
+	Mechanism SASLMechanism `json:"mechanism"`
+	Username  string        `json:"username"`
+	Password  string        `json:"password"`
+}
+
+func PasswordIdentityDef() *eventline.IdentityDef {
+	def := eventline.NewIdentityDef("password", &PasswordIdentity{})
+	return def
+}
+
+func (i *PasswordIdentity) ValidateJSON(v *ejson.Validator) {
+	v.CheckStringValue("mechanism", i.Mechanism, SASLMechanismValues)
+	v.CheckStringNotEmpty("username", i.Username)
+	v.CheckStringNotEmpty("password", i.Password)
+}
+
+func (i *PasswordIdentity) Def() *eventline.IdentityDataDef {
+	view := eventline.NewIdentityDataDef()
+
+	view.AddEntry(&eventline.IdentityDataEntry{
+		Key:   "mechanism",
+		Label: "SASL mechanism",
+		Value: string(i.Mechanism),
+		Type:  eventline.IdentityDataTypeEnum,
+		EnumValues: []string{
+			string(SASLMechanismPlain),
+			string(SASLMechanismSCRAMSHA256),
+			string(SASLMechanismSCRAMSHA512),
+		},
+	})
+
+	view.AddEntry(&eventline.IdentityDataEntry{
+		Key:   "username",
+		Label: "Username",
+		Value: i.Username,
+		Type:  eventline.IdentityDataTypeString,
+	})
+
+	view.AddEntry(&eventline.IdentityDataEntry{
+		Key:    "password",
+		Label:  "Password",
+		Value:  i.Password,
+		Type:   eventline.IdentityDataTypeString,
+		Secret: true,
+	})
+
+	return view
+}
+
+func (i *PasswordIdentity) Environment() map[string]string {
+	return map[string]string{}
+}
+
+func (i *PasswordIdentity) SASLMechanism() (sasl.Mechanism, error) {
+	switch i.Mechanism {
+	case SASLMechanismPlain:
+		return plain.Mechanism{Username: i.Username, Password: i.Password}, nil
+	case SASLMechanismSCRAMSHA256:
+		return scram.Mechanism(scram.SHA256, i.Username, i.Password)
+	case SASLMechanismSCRAMSHA512:
+		return scram.Mechanism(scram.SHA512, i.Username, i.Password)
+	default:
+		return nil, fmt.Errorf("unknown sasl mechanism %q", i.Mechanism)
+	}
+}
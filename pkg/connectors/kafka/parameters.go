@@ -0,0 +1,37 @@
+package kafka
+
+import (
+	"github.com/exograd/eventline/pkg/connectors/mq"
+	"go.n16f.net/ejson"
+)
+
+const DefaultGroup = "eventline"
+
+type Parameters struct {
+	Brokers []string `json:"brokers"`
+	Topic   string   `json:"topic"`
+	Group   string   `json:"group,omitempty"`
+	Format  string   `json:"format,omitempty"`
+	TLS     bool     `json:"tls,omitempty"`
+}
+
+func (p *Parameters) ValidateJSON(v *ejson.Validator) {
+	if v.CheckArrayNotEmpty("brokers", p.Brokers) {
+		v.WithChild("brokers", func() {
+			for i, broker := range p.Brokers {
+				v.CheckStringNotEmpty(i, broker)
+			}
+		})
+	}
+
+	v.CheckStringNotEmpty("topic", p.Topic)
+	mq.CheckBodyFormat(v, "format", p.Format)
+}
+
+func (p *Parameters) GroupId() string {
+	if p.Group == "" {
+		return DefaultGroup
+	}
+
+	return p.Group
+}
@@ -0,0 +1,151 @@
+// Package mq contains the code shared by connectors consuming messages (amqp,
+// kafka, nats and postgresql notifications): consumer management, message
+// body decoding and acknowledgement.
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
+	"go.n16f.net/log"
+	"go.n16f.net/service/pkg/pg"
+)
+
+const (
+	BodyFormatJSON = "json"
+	BodyFormatRaw  = "raw"
+)
+
+var BodyFormatValues = []string{
+	BodyFormatJSON,
+	BodyFormatRaw,
+}
+
+// A delivery is a message decoded by a consumer and ready to be stored as an
+// event.
+type Delivery struct {
+	EventTime *time.Time
+	Data      eventline.EventData
+
+	// Store is an optional function called in the transaction creating
+	// events, so that connectors can record their progress for the
+	// subscriptions of the consumer.
+	Store func(pg.Conn, eventline.Subscriptions) error
+}
+
+// A message received by a consumer.
+type Message interface {
+	// Decode returns the delivery for the message. Messages which cannot be
+	// decoded are rejected.
+	Decode() (*Delivery, error)
+
+	// Ack confirms that the message was processed.
+	Ack() error
+
+	// Requeue signals that the message could not be processed and must be
+	// delivered again later.
+	Requeue() error
+
+	// Reject signals that the message is invalid and must not be delivered
+	// again.
+	Reject() error
+}
+
+// The handler is called by consumers for each message received. If it
+// returns an error, the consumer must stop.
+type Handler func(Message) error
+
+type Consumer interface {
+	// Run receives messages and passes them to the handler until the
+	// context is cancelled, the connection fails or the handler fails. The
+	// consumer must release all its resources before returning.
+	Run(context.Context, Handler) error
+}
+
+func CheckBodyFormat(v *ejson.Validator, token string, format string) {
+	if format != "" {
+		v.CheckStringValue(token, format, BodyFormatValues)
+	}
+}
+
+// DecodeBody decodes the body of a message according to a format. Raw bodies
+// are returned as character strings, replacing invalid UTF-8 sequences.
+func DecodeBody(format string, data []byte) (interface{}, error) {
+	switch format {
+	case BodyFormatJSON, "":
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("invalid json body: %w", err)
+		}
+
+		return value, nil
+
+	case BodyFormatRaw:
+		return strings.ToValidUTF8(string(data), "�"), nil
+
+	default:
+		return nil, fmt.Errorf("unknown body format %q", format)
+	}
+}
+
+// ProcessMessage decodes a message and passes the delivery to a function
+// which stores the event. The message is acknowledged once the function has
+// succeeded, i.e. once the event has been committed. Invalid messages are
+// logged and rejected. Messages which could not be stored are requeued and the
+// error is returned so that the consumer stops.
+func ProcessMessage(log *log.Logger, msg Message, fn func(*Delivery) error) error {
+	delivery, err := msg.Decode()
+	if err != nil {
+		log.Error("rejecting message: %v", err)
+
+		if err := msg.Reject(); err != nil {
+			return fmt.Errorf("cannot reject message: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := fn(delivery); err != nil {
+		if err2 := msg.Requeue(); err2 != nil {
+			log.Error("cannot requeue message: %v", err2)
+		}
+
+		return fmt.Errorf("cannot process message: %w", err)
+	}
+
+	if err := msg.Ack(); err != nil {
+		return fmt.Errorf("cannot acknowledge message: %w", err)
+	}
+
+	return nil
+}
+
+// StringHeaders converts message headers to character strings. Headers with
+// multiple values are joined with commas.
+func StringHeaders[T any](headers map[string]T) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	values := make(map[string]string, len(headers))
+
+	for name, value := range headers {
+		switch v := any(value).(type) {
+		case string:
+			values[name] = v
+		case []byte:
+			values[name] = string(v)
+		case []string:
+			values[name] = strings.Join(v, ", ")
+		default:
+			values[name] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	return values
+}
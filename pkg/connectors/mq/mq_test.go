@@ -0,0 +1,109 @@
+package mq
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.n16f.net/log"
+)
+
+type testMessage struct {
+	body   string
+	events []string
+}
+
+func (m *testMessage) Decode() (*Delivery, error) {
+	m.events = append(m.events, "decode")
+
+	body, err := DecodeBody(BodyFormatJSON, []byte(m.body))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Delivery{Data: body}, nil
+}
+
+func (m *testMessage) Ack() error {
+	m.events = append(m.events, "ack")
+	return nil
+}
+
+func (m *testMessage) Requeue() error {
+	m.events = append(m.events, "requeue")
+	return nil
+}
+
+func (m *testMessage) Reject() error {
+	m.events = append(m.events, "reject")
+	return nil
+}
+
+func TestDecodeBody(t *testing.T) {
+	assert := assert.New(t)
+
+	value, err := DecodeBody("", []byte(`{"a": [1, true]}`))
+	if assert.NoError(err) {
+		assert.Equal(map[string]interface{}{"a": []interface{}{1.0, true}},
+			value)
+	}
+
+	_, err = DecodeBody(BodyFormatJSON, []byte(`{"a": `))
+	assert.Error(err)
+
+	value, err = DecodeBody(BodyFormatRaw, []byte("abc\xffdef"))
+	if assert.NoError(err) {
+		assert.Equal("abc�def", value)
+	}
+}
+
+func TestProcessMessage(t *testing.T) {
+	assert := assert.New(t)
+
+	logger := log.DefaultLogger("mq")
+
+	store := func(msg *testMessage) func(*Delivery) error {
+		return func(d *Delivery) error {
+			msg.events = append(msg.events, "store")
+			return nil
+		}
+	}
+
+	// The message is acknowledged once the event is stored
+	msg := testMessage{body: `{}`}
+	assert.NoError(ProcessMessage(logger, &msg, store(&msg)))
+	assert.Equal([]string{"decode", "store", "ack"}, msg.events)
+
+	// Invalid messages are rejected without stopping the consumer
+	msg = testMessage{body: `{`}
+	assert.NoError(ProcessMessage(logger, &msg, store(&msg)))
+	assert.Equal([]string{"decode", "reject"}, msg.events)
+
+	// Messages which cannot be stored are requeued
+	storeErr := errors.New("database unavailable")
+
+	msg = testMessage{body: `{}`}
+	err := ProcessMessage(logger, &msg, func(d *Delivery) error {
+		return storeErr
+	})
+	assert.ErrorIs(err, storeErr)
+	assert.Equal([]string{"decode", "requeue"}, msg.events)
+}
+
+func TestStringHeaders(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(StringHeaders(map[string]string{}))
+
+	assert.Equal(map[string]string{
+		"a": "1",
+		"b": "foo",
+		"c": "bar",
+		"d": "x, y",
+	}, StringHeaders(map[string]interface{}{
+		"a": 1,
+		"b": "foo",
+		"c": []byte("bar"),
+		"d": []string{"x", "y"},
+	}))
+}
@@ -0,0 +1,305 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/log"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+// The delay before restarting a consumer which failed.
+const RestartDelay = 10 * time.Second
+
+// The consumer factory creates a consumer for a subscription. The identity is
+// nil if the subscription does not use one.
+type ConsumerFactory func(*eventline.Subscription, *eventline.Identity) (Consumer, error)
+
+// Worker is the connector worker shared by message queue connectors. It
+// periodically loads active subscriptions and makes sure that there is exactly
+// one running consumer for each set of parameters and identity. Subscriptions
+// sharing the same parameters and identity share the same consumer, and
+// each message produces a single event delivered to all of them. Since
+// consumers are only managed by the worker, message queue connectors have
+// nothing to do when subscribing or unsubscribing.
+type Worker struct {
+	Log *log.Logger
+	Pg  *pg.Client
+
+	connector   string
+	event       string
+	newConsumer ConsumerFactory
+
+	worker *eventline.Worker
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	consumers map[string]*runningConsumer
+	wg        sync.WaitGroup
+}
+
+type runningConsumer struct {
+	key            string
+	subscriptionId uuid.UUID
+	consumer       Consumer
+	cancel         context.CancelFunc
+
+	subscriptionIds map[uuid.UUID]struct{}
+	mutex           sync.Mutex
+}
+
+type consumerSpec struct {
+	subscription *eventline.Subscription
+	identity     *eventline.Identity
+
+	subscriptionIds map[uuid.UUID]struct{}
+}
+
+func NewWorker(connector, event string, newConsumer ConsumerFactory) *Worker {
+	return &Worker{
+		connector:   connector,
+		event:       event,
+		newConsumer: newConsumer,
+
+		consumers: make(map[string]*runningConsumer),
+	}
+}
+
+func (w *Worker) Init(ew *eventline.Worker) {
+	w.Log = ew.Log
+	w.Pg = ew.Pg
+
+	w.worker = ew
+}
+
+func (w *Worker) Start() error {
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	return nil
+}
+
+func (w *Worker) Stop() {
+	if w.cancel == nil {
+		return
+	}
+
+	w.cancel()
+	w.wg.Wait()
+
+	w.consumers = make(map[string]*runningConsumer)
+}
+
+func (w *Worker) ProcessJob() (bool, error) {
+	specs := make(map[string]*consumerSpec)
+
+	err := w.Pg.WithConn(func(conn pg.Conn) error {
+		var subs eventline.Subscriptions
+		err := subs.LoadByEvent(conn, w.connector, w.event,
+			eventline.NewGlobalScope())
+		if err != nil {
+			return fmt.Errorf("cannot load subscriptions: %w", err)
+		}
+
+		for _, sub := range subs {
+			if sub.Status != eventline.SubscriptionStatusActive {
+				continue
+			}
+
+			var identity *eventline.Identity
+
+			if sub.IdentityId != nil {
+				identity = new(eventline.Identity)
+
+				scope := eventline.NewProjectScope(*sub.ProjectId)
+				err := identity.Load(conn, *sub.IdentityId, scope)
+				if err != nil {
+					w.Log.Error("cannot load identity %q for subscription "+
+						"%q: %v", *sub.IdentityId, sub.Id, err)
+					continue
+				}
+			}
+
+			key, err := consumerKey(sub, identity)
+			if err != nil {
+				return err
+			}
+
+			spec, found := specs[key]
+			if !found {
+				spec = &consumerSpec{
+					subscription:    sub,
+					identity:        identity,
+					subscriptionIds: make(map[uuid.UUID]struct{}),
+				}
+
+				specs[key] = spec
+			}
+
+			spec.subscriptionIds[sub.Id] = struct{}{}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	w.updateConsumers(specs)
+
+	// Consumers run in the background; we poll subscriptions at regular
+	// interval to detect changes.
+	return false, nil
+}
+
+// consumerKey identifies the consumer used for a subscription. Updating the
+// identity changes the key so that the consumer is restarted with the new
+// credentials.
+func consumerKey(sub *eventline.Subscription, identity *eventline.Identity) (string, error) {
+	params, err := json.Marshal(sub.Parameters)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode subscription parameters: %w",
+			err)
+	}
+
+	key := string(params)
+
+	if identity != nil {
+		key += "/" + identity.Id.String() + "/" +
+			identity.UpdateTime.Format(time.RFC3339Nano)
+	}
+
+	return key, nil
+}
+
+func (w *Worker) updateConsumers(specs map[string]*consumerSpec) {
+	for key, rc := range w.consumers {
+		if _, found := specs[key]; !found {
+			w.Log.Info("stopping consumer for subscription %q",
+				rc.subscriptionId)
+
+			rc.cancel()
+			delete(w.consumers, key)
+		}
+	}
+
+	for key, spec := range specs {
+		if rc, found := w.consumers[key]; found {
+			rc.mutex.Lock()
+			rc.subscriptionIds = spec.subscriptionIds
+			rc.mutex.Unlock()
+
+			continue
+		}
+
+		consumer, err := w.newConsumer(spec.subscription, spec.identity)
+		if err != nil {
+			w.Log.Error("cannot create consumer for subscription %q: %v",
+				spec.subscription.Id, err)
+			continue
+		}
+
+		w.Log.Info("starting consumer for subscription %q",
+			spec.subscription.Id)
+
+		ctx, cancel := context.WithCancel(w.ctx)
+
+		rc := runningConsumer{
+			key:            key,
+			subscriptionId: spec.subscription.Id,
+			consumer:       consumer,
+			cancel:         cancel,
+
+			subscriptionIds: spec.subscriptionIds,
+		}
+
+		w.consumers[key] = &rc
+
+		w.wg.Add(1)
+		go w.runConsumer(ctx, &rc)
+	}
+}
+
+func (w *Worker) runConsumer(ctx context.Context, rc *runningConsumer) {
+	defer w.wg.Done()
+
+	handler := func(msg Message) error {
+		return ProcessMessage(w.Log, msg, func(d *Delivery) error {
+			return w.processDelivery(rc, d)
+		})
+	}
+
+	for {
+		err := rc.consumer.Run(ctx, handler)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			err = errors.New("consumer stopped")
+		}
+
+		w.Log.Error("consumer for subscription %q failed: %v",
+			rc.subscriptionId, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(RestartDelay):
+		}
+	}
+}
+
+func (w *Worker) processDelivery(rc *runningConsumer, d *Delivery) error {
+	rc.mutex.Lock()
+	subscriptionIds := rc.subscriptionIds
+	rc.mutex.Unlock()
+
+	var events eventline.Events
+
+	err := w.Pg.WithTx(func(conn pg.Conn) error {
+		var subs eventline.Subscriptions
+		err := subs.LoadByEvent(conn, w.connector, w.event,
+			eventline.NewGlobalScope())
+		if err != nil {
+			return fmt.Errorf("cannot load subscriptions: %w", err)
+		}
+
+		var consumerSubs eventline.Subscriptions
+		for _, sub := range subs {
+			if _, found := subscriptionIds[sub.Id]; found {
+				consumerSubs = append(consumerSubs, sub)
+			}
+		}
+
+		if len(consumerSubs) == 0 {
+			return fmt.Errorf("no subscription left for consumer")
+		}
+
+		events, err = eventline.DispatchEvent(conn, consumerSubs,
+			w.connector, w.event, d.EventTime, d.Data)
+		if err != nil {
+			return err
+		}
+
+		if d.Store != nil {
+			return d.Store(conn, consumerSubs)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		w.worker.Cfg.NotificationChan <- event
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package mq
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/stretchr/testify/assert"
+	"go.n16f.net/ejson"
+	"go.n16f.net/log"
+	"go.n16f.net/uuid"
+)
+
+type testConsumer struct {
+	name    string
+	running *sync.Map
+}
+
+func (c *testConsumer) Run(ctx context.Context, handler Handler) error {
+	c.running.Store(c.name, true)
+	<-ctx.Done()
+	c.running.Delete(c.name)
+
+	return nil
+}
+
+func TestWorkerUpdateConsumers(t *testing.T) {
+	assert := assert.New(t)
+
+	var running sync.Map
+	var created []string
+
+	newConsumer := func(sub *eventline.Subscription, identity *eventline.Identity) (Consumer, error) {
+		name := sub.Parameters.(*testParameters).Queue
+		created = append(created, name)
+
+		return &testConsumer{name: name, running: &running}, nil
+	}
+
+	w := NewWorker("test", "message", newConsumer)
+	w.Log = log.DefaultLogger("mq")
+	assert.NoError(w.Start())
+
+	spec := func(queue string, nbSubscriptions int) *consumerSpec {
+		sub := eventline.Subscription{
+			Id:         uuid.MustGenerate(uuid.V7),
+			Parameters: &testParameters{Queue: queue},
+		}
+
+		spec := consumerSpec{
+			subscription:    &sub,
+			subscriptionIds: make(map[uuid.UUID]struct{}),
+		}
+
+		for i := 0; i < nbSubscriptions; i++ {
+			spec.subscriptionIds[uuid.MustGenerate(uuid.V7)] = struct{}{}
+		}
+
+		return &spec
+	}
+
+	runningNames := func() []string {
+		var names []string
+		running.Range(func(key, value any) bool {
+			names = append(names, key.(string))
+			return true
+		})
+
+		return names
+	}
+
+	w.updateConsumers(map[string]*consumerSpec{
+		"a": spec("a", 1),
+		"b": spec("b", 2),
+	})
+
+	assert.Eventually(func() bool { return len(runningNames()) == 2 },
+		time.Second, time.Millisecond)
+	assert.ElementsMatch([]string{"a", "b"}, created)
+
+	// Existing consumers are kept, removed ones are stopped
+	bSpec := spec("b", 3)
+
+	w.updateConsumers(map[string]*consumerSpec{
+		"b": bSpec,
+		"c": spec("c", 1),
+	})
+
+	assert.Eventually(func() bool {
+		names := runningNames()
+		return len(names) == 2 && !slices.Contains(names, "a")
+	}, time.Second, time.Millisecond)
+	assert.ElementsMatch([]string{"a", "b", "c"}, created)
+	assert.Equal(bSpec.subscriptionIds, w.consumers["b"].subscriptionIds)
+
+	w.Stop()
+	assert.Empty(runningNames())
+}
+
+type testParameters struct {
+	Queue string `json:"queue"`
+}
+
+func (p *testParameters) ValidateJSON(v *ejson.Validator) {
+}
@@ -0,0 +1,73 @@
+package nats
+
+import (
+	"github.com/exograd/eventline/pkg/connectors/mq"
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
+	"go.n16f.net/log"
+	"go.n16f.net/service/pkg/pg"
+)
+
+type ConnectorCfg struct {
+	Enabled bool `json:"enabled"`
+}
+
+type Connector struct {
+	Def *eventline.ConnectorDef
+	Cfg *ConnectorCfg
+	Log *log.Logger
+}
+
+func NewConnector() *Connector {
+	def := eventline.NewConnectorDef("nats")
+
+	def.AddIdentity(TokenIdentityDef())
+	def.AddIdentity(PasswordIdentityDef())
+
+	def.AddEvent(MessageEventDef())
+
+	c := Connector{
+		Def: def,
+	}
+
+	def.Worker = mq.NewWorker("nats", "message", c.NewConsumer)
+
+	return &c
+}
+
+func (cfg *ConnectorCfg) ValidateJSON(v *ejson.Validator) {
+}
+
+func (c *Connector) Name() string {
+	return "nats"
+}
+
+func (c *Connector) Definition() *eventline.ConnectorDef {
+	return c.Def
+}
+
+func (c *Connector) Enabled() bool {
+	return c.Cfg.Enabled
+}
+
+func (c *Connector) DefaultCfg() eventline.ConnectorCfg {
+	return &ConnectorCfg{}
+}
+
+func (c *Connector) Init(ccfg eventline.ConnectorCfg, initData eventline.ConnectorInitData) error {
+	c.Cfg = ccfg.(*ConnectorCfg)
+	c.Log = initData.Log
+
+	return nil
+}
+
+func (c *Connector) Terminate() {
+}
+
+func (c *Connector) Subscribe(conn pg.Conn, sctx *eventline.SubscriptionContext) error {
+	return nil
+}
+
+func (c *Connector) Unsubscribe(conn pg.Conn, sctx *eventline.SubscriptionContext) error {
+	return nil
+}
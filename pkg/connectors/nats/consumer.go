@@ -0,0 +1,239 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/exograd/eventline/pkg/connectors/mq"
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/nats-io/nats.go"
+)
+
+// The maximum number of messages delivered by JetStream before waiting for
+// acknowledgements.
+const maxAckPending = 10
+
+type Subscription interface {
+	// Messages returns the channel messages are delivered on.
+	Messages() <-chan *nats.Msg
+
+	// Closed returns a channel which is closed when the connection is lost
+	// and cannot be re-established.
+	Closed() <-chan struct{}
+
+	Close() error
+}
+
+type Consumer struct {
+	Parameters *Parameters
+	Identity   eventline.IdentityData
+
+	subscribe func() (Subscription, error)
+}
+
+// Messages received with core NATS cannot be acknowledged: only JetStream
+// consumers guarantee that messages are delivered again if they could not be
+// stored.
+type Message struct {
+	Msg       *nats.Msg
+	Format    string
+	JetStream bool
+}
+
+func (c *Connector) NewConsumer(sub *eventline.Subscription, identity *eventline.Identity) (mq.Consumer, error) {
+	consumer := Consumer{
+		Parameters: sub.Parameters.(*Parameters),
+	}
+
+	if identity != nil {
+		switch identity.Data.(type) {
+		case *TokenIdentity, *PasswordIdentity:
+			consumer.Identity = identity.Data
+
+		default:
+			return nil, fmt.Errorf("unsupported identity")
+		}
+	}
+
+	consumer.subscribe = consumer.connect
+
+	return &consumer, nil
+}
+
+func (c *Consumer) Run(ctx context.Context, handler mq.Handler) error {
+	subscription, err := c.subscribe()
+	if err != nil {
+		return err
+	}
+
+	// Unacknowledged JetStream messages are delivered again once the
+	// acknowledgement wait delay has expired.
+	defer subscription.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-subscription.Closed():
+			return fmt.Errorf("connection closed")
+
+		case nmsg := <-subscription.Messages():
+			msg := Message{
+				Msg:       nmsg,
+				Format:    c.Parameters.Format,
+				JetStream: c.Parameters.JetStream(),
+			}
+
+			if err := handler(&msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type connSubscription struct {
+	conn         *nats.Conn
+	subscription *nats.Subscription
+	msgs         chan *nats.Msg
+	closed       chan struct{}
+}
+
+func (c *Consumer) connect() (Subscription, error) {
+	s := connSubscription{
+		msgs:   make(chan *nats.Msg, maxAckPending),
+		closed: make(chan struct{}),
+	}
+
+	options := []nats.Option{
+		nats.Name("eventline"),
+		nats.MaxReconnects(-1),
+		nats.ClosedHandler(func(*nats.Conn) {
+			close(s.closed)
+		}),
+	}
+
+	switch idata := c.Identity.(type) {
+	case *TokenIdentity:
+		options = append(options, nats.Token(idata.Token))
+	case *PasswordIdentity:
+		options = append(options,
+			nats.UserInfo(idata.Username, idata.Password))
+	}
+
+	conn, err := nats.Connect(c.Parameters.URI, options...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %q: %w",
+			c.Parameters.URI, err)
+	}
+
+	s.conn = conn
+
+	p := c.Parameters
+
+	if p.JetStream() {
+		js, err := conn.JetStream()
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("cannot create jetstream context: %w", err)
+		}
+
+		subOptions := []nats.SubOpt{
+			nats.BindStream(p.Stream),
+			nats.Durable(p.Durable),
+			nats.ManualAck(),
+			nats.AckExplicit(),
+			nats.MaxAckPending(maxAckPending),
+		}
+
+		if p.Queue == "" {
+			s.subscription, err = js.ChanSubscribe(p.Subject, s.msgs,
+				subOptions...)
+		} else {
+			s.subscription, err = js.ChanQueueSubscribe(p.Subject, p.Queue,
+				s.msgs, subOptions...)
+		}
+	} else {
+		if p.Queue == "" {
+			s.subscription, err = conn.ChanSubscribe(p.Subject, s.msgs)
+		} else {
+			s.subscription, err = conn.ChanQueueSubscribe(p.Subject, p.Queue,
+				s.msgs)
+		}
+	}
+
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("cannot subscribe to %q: %w", p.Subject, err)
+	}
+
+	return &s, nil
+}
+
+func (s *connSubscription) Messages() <-chan *nats.Msg {
+	return s.msgs
+}
+
+func (s *connSubscription) Closed() <-chan struct{} {
+	return s.closed
+}
+
+func (s *connSubscription) Close() error {
+	// Durable JetStream consumers must not be deleted, so we close the
+	// connection instead of unsubscribing.
+	s.conn.Close()
+	return nil
+}
+
+func (m *Message) Decode() (*mq.Delivery, error) {
+	nmsg := m.Msg
+
+	body, err := mq.DecodeBody(m.Format, nmsg.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	event := MessageEvent{
+		Subject: nmsg.Subject,
+		Headers: mq.StringHeaders(nmsg.Header),
+		Body:    body,
+	}
+
+	var eventTime *time.Time
+
+	if m.JetStream {
+		if metadata, err := nmsg.Metadata(); err == nil {
+			t := metadata.Timestamp.UTC()
+			eventTime = &t
+
+			event.Stream = metadata.Stream
+		}
+	}
+
+	return &mq.Delivery{EventTime: eventTime, Data: &event}, nil
+}
+
+func (m *Message) Ack() error {
+	if !m.JetStream {
+		return nil
+	}
+
+	return m.Msg.Ack()
+}
+
+func (m *Message) Requeue() error {
+	if !m.JetStream {
+		return nil
+	}
+
+	return m.Msg.Nak()
+}
+
+func (m *Message) Reject() error {
+	if !m.JetStream {
+		return nil
+	}
+
+	return m.Msg.Term()
+}
@@ -0,0 +1,104 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/exograd/eventline/pkg/connectors/mq"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.n16f.net/log"
+)
+
+// testSubscription is an in-process stand-in for a core NATS subscription.
+type testSubscription struct {
+	msgs   chan *nats.Msg
+	closed chan struct{}
+}
+
+func newTestSubscription() *testSubscription {
+	return &testSubscription{
+		msgs:   make(chan *nats.Msg, 10),
+		closed: make(chan struct{}),
+	}
+}
+
+func (s *testSubscription) Publish(subject, body string) {
+	msg := nats.NewMsg(subject)
+	msg.Header.Add("Origin", "ci")
+	msg.Header.Add("Origin", "cron")
+	msg.Data = []byte(body)
+
+	s.msgs <- msg
+}
+
+func (s *testSubscription) Messages() <-chan *nats.Msg {
+	return s.msgs
+}
+
+func (s *testSubscription) Closed() <-chan struct{} {
+	return s.closed
+}
+
+func (s *testSubscription) Close() error {
+	return nil
+}
+
+func TestConsumer(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	subscription := newTestSubscription()
+
+	consumer := Consumer{
+		Parameters: &Parameters{Subject: "builds.>", Format: "raw"},
+		subscribe: func() (Subscription, error) {
+			return subscription, nil
+		},
+	}
+
+	logger := log.DefaultLogger("nats")
+
+	var events []*MessageEvent
+	var storeErr error
+
+	handler := func(msg mq.Message) error {
+		return mq.ProcessMessage(logger, msg, func(d *mq.Delivery) error {
+			if storeErr != nil {
+				return storeErr
+			}
+
+			events = append(events, d.Data.(*MessageEvent))
+
+			if len(events) == 2 {
+				close(subscription.closed)
+			}
+
+			return nil
+		})
+	}
+
+	subscription.Publish("builds.main", "success")
+	subscription.Publish("builds.dev", "failure \xff")
+
+	err := consumer.Run(context.Background(), handler)
+	assert.Error(err)
+
+	require.Len(events, 2)
+
+	assert.Equal("builds.main", events[0].Subject)
+	assert.Equal(map[string]string{"Origin": "ci, cron"}, events[0].Headers)
+	assert.Equal("success", events[0].Body)
+	assert.Equal("failure �", events[1].Body)
+
+	// Core NATS messages cannot be requeued, but the consumer must stop
+	subscription = newTestSubscription()
+	storeErr = errors.New("database unavailable")
+
+	subscription.Publish("builds.main", "success")
+
+	err = consumer.Run(context.Background(), handler)
+	assert.ErrorIs(err, storeErr)
+}
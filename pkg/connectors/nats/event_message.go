@@ -0,0 +1,16 @@
+package nats
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+)
+
+type MessageEvent struct {
+	Subject string            `json:"subject"`
+	Stream  string            `json:"stream,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body"`
+}
+
+func MessageEventDef() *eventline.EventDef {
+	return eventline.NewEventDef("message", &MessageEvent{}, &Parameters{})
+}
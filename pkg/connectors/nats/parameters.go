@@ -0,0 +1,35 @@
+package nats
+
+import (
+	"github.com/exograd/eventline/pkg/connectors/mq"
+	"go.n16f.net/ejson"
+)
+
+type Parameters struct {
+	URI     string `json:"uri"`
+	Subject string `json:"subject"`
+	Queue   string `json:"queue,omitempty"`
+	Stream  string `json:"stream,omitempty"`
+	Durable string `json:"durable,omitempty"`
+	Format  string `json:"format,omitempty"`
+}
+
+func (p *Parameters) ValidateJSON(v *ejson.Validator) {
+	v.CheckStringURI("uri", p.URI)
+	v.CheckStringNotEmpty("subject", p.Subject)
+
+	// JetStream consumers must be durable so that messages published while
+	// Eventline is not running are not lost.
+	if p.Stream != "" {
+		v.CheckStringNotEmpty("durable", p.Durable)
+	} else if p.Durable != "" {
+		v.AddError("durable", "missing_stream",
+			"durable consumers require a jetstream stream")
+	}
+
+	mq.CheckBodyFormat(v, "format", p.Format)
+}
+
+func (p *Parameters) JetStream() bool {
+	return p.Stream != ""
+}
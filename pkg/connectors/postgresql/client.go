@@ -0,0 +1,209 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Connect opens a connection to an external database. Credentials of the
+// identity, if there is one, override those contained in the URI.
+func (c *Connector) Connect(ctx context.Context, uri string, identity *eventline.Identity) (*pgx.Conn, error) {
+	cfg, err := pgx.ParseConfig(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uri: %w", err)
+	}
+
+	hostAddresses, err := checkDatabaseAddress(ctx, &cfg.Config,
+		c.Cfg.AllowedHosts, c.eventlineDatabase, c.lookupFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Connect to the addresses which were checked instead of resolving hosts
+	// a second time, since the result of the lookup could be different.
+	cfg.LookupFunc = func(ctx context.Context, host string) ([]string, error) {
+		addrs, found := hostAddresses[host]
+		if !found {
+			return nil, fmt.Errorf("unchecked host %q", host)
+		}
+
+		return addrs, nil
+	}
+
+	if identity != nil {
+		idata, ok := identity.Data.(*PasswordIdentity)
+		if !ok {
+			return nil, fmt.Errorf("unsupported identity")
+		}
+
+		cfg.User = idata.User
+		cfg.Password = idata.Password
+	}
+
+	conn, err := pgx.ConnectConfig(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %s:%d: %w",
+			cfg.Host, cfg.Port, err)
+	}
+
+	return conn, nil
+}
+
+// databaseAddress is the resolved address of a database server, either the
+// directory containing its Unix socket or one of its IP addresses.
+type databaseAddress struct {
+	SocketDirectory string
+	IP              net.IP
+	Port            uint16
+}
+
+func (addr databaseAddress) String() string {
+	if addr.SocketDirectory != "" {
+		return fmt.Sprintf("%s:%d", addr.SocketDirectory, addr.Port)
+	}
+
+	return net.JoinHostPort(addr.IP.String(), fmt.Sprintf("%d", addr.Port))
+}
+
+// SameHost returns true if both addresses designate the same host. All local
+// IP addresses, including unspecified addresses which connect to the local
+// host, are considered to be the same host.
+func (addr databaseAddress) SameHost(addr2 databaseAddress) bool {
+	if addr.SocketDirectory != "" || addr2.SocketDirectory != "" {
+		return addr.SocketDirectory == addr2.SocketDirectory
+	}
+
+	isLocal := func(ip net.IP) bool {
+		return ip.IsLoopback() || ip.IsUnspecified()
+	}
+
+	return addr.IP.Equal(addr2.IP) || (isLocal(addr.IP) && isLocal(addr2.IP))
+}
+
+// resolveHost returns the addresses of a database host. Hosts starting with
+// a slash are directories containing a Unix socket.
+func resolveHost(ctx context.Context, host string, port uint16, lookup pgconn.LookupFunc) ([]databaseAddress, error) {
+	if strings.HasPrefix(host, "/") {
+		addr := databaseAddress{
+			SocketDirectory: filepath.Clean(host),
+			Port:            port,
+		}
+
+		return []databaseAddress{addr}, nil
+	}
+
+	ips, err := lookup(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve host %q: %w", host, err)
+	} else if len(ips) == 0 {
+		return nil, fmt.Errorf("cannot resolve host %q: no address found",
+			host)
+	}
+
+	addrs := make([]databaseAddress, len(ips))
+	for i, s := range ips {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid address %q for host %q", s, host)
+		}
+
+		addrs[i] = databaseAddress{IP: ip, Port: port}
+	}
+
+	return addrs, nil
+}
+
+// checkDatabaseAddress makes sure that subscriptions can only read data from
+// allowed hosts, and not from the Eventline database. Hosts are compared
+// using their resolved addresses, and multi-host URIs are checked for each
+// host. The function returns the addresses of each host so that the
+// connection uses the addresses which were checked.
+func checkDatabaseAddress(ctx context.Context, cfg *pgconn.Config, allowedHosts []string, eventlineDatabase *pgconn.Config, lookup pgconn.LookupFunc) (map[string][]string, error) {
+	if len(allowedHosts) == 0 {
+		return nil, fmt.Errorf("no database host is allowed")
+	}
+
+	// Pgx does not support the hostaddr setting and would send it to the
+	// server as a runtime parameter; we reject it instead of ignoring it.
+	if _, found := cfg.RuntimeParams["hostaddr"]; found {
+		return nil, fmt.Errorf("hostaddr setting not supported")
+	}
+
+	var allowedAddrs []databaseAddress
+	for _, host := range allowedHosts {
+		addrs, err := resolveHost(ctx, host, 0, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed host: %w", err)
+		}
+
+		allowedAddrs = append(allowedAddrs, addrs...)
+	}
+
+	var eventlineAddrs []databaseAddress
+	if edb := eventlineDatabase; edb != nil {
+		hosts := []*pgconn.FallbackConfig{{Host: edb.Host, Port: edb.Port}}
+		hosts = append(hosts, edb.Fallbacks...)
+
+		for _, host := range hosts {
+			addrs, err := resolveHost(ctx, host.Host, host.Port, lookup)
+			if err != nil {
+				return nil, fmt.Errorf("cannot resolve eventline database "+
+					"host: %w", err)
+			}
+
+			eventlineAddrs = append(eventlineAddrs, addrs...)
+		}
+	}
+
+	hosts := []*pgconn.FallbackConfig{{Host: cfg.Host, Port: cfg.Port}}
+	hosts = append(hosts, cfg.Fallbacks...)
+
+	hostAddresses := make(map[string][]string)
+
+	for _, host := range hosts {
+		addrs, err := resolveHost(ctx, host.Host, host.Port, lookup)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, addr := range addrs {
+			allowed := false
+			for _, allowedAddr := range allowedAddrs {
+				if addr.SameHost(allowedAddr) {
+					allowed = true
+					break
+				}
+			}
+
+			if !allowed {
+				return nil, fmt.Errorf("host %q (%s) is not allowed",
+					host.Host, addr)
+			}
+
+			if eventlineDatabase != nil &&
+				cfg.Database == eventlineDatabase.Database {
+				for _, eventlineAddr := range eventlineAddrs {
+					if addr.SameHost(eventlineAddr) &&
+						addr.Port == eventlineAddr.Port {
+						return nil, fmt.Errorf("the eventline database " +
+							"cannot be used")
+					}
+				}
+			}
+
+			if addr.IP != nil {
+				hostAddresses[host.Host] = append(hostAddresses[host.Host],
+					addr.IP.String())
+			}
+		}
+	}
+
+	return hostAddresses, nil
+}
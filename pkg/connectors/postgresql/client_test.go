@@ -0,0 +1,137 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDatabaseAddress(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	hosts := map[string][]string{
+		"localhost":         {"127.0.0.1", "::1"},
+		"db.example.com":    {"203.0.113.10"},
+		"db2.example.com":   {"203.0.113.11"},
+		"alias.example.com": {"203.0.113.10"},
+		"local.example.com": {"127.0.0.1"},
+		"mixed.example.com": {"203.0.113.10", "127.0.0.1"},
+	}
+
+	// Behave as net.Resolver.LookupHost: host names are case-insensitive and
+	// IP addresses are returned as they are.
+	lookup := func(ctx context.Context, host string) ([]string, error) {
+		if net.ParseIP(host) != nil {
+			return []string{host}, nil
+		}
+
+		addrs, found := hosts[strings.ToLower(host)]
+		if !found {
+			return nil, fmt.Errorf("unknown host %q", host)
+		}
+
+		return addrs, nil
+	}
+
+	check := func(uri, eventlineURI string, allowedHosts []string) error {
+		cfg, err := pgx.ParseConfig(uri)
+		require.NoError(err)
+
+		eventlineCfg, err := pgx.ParseConfig(eventlineURI)
+		require.NoError(err)
+
+		_, err = checkDatabaseAddress(context.Background(), &cfg.Config,
+			allowedHosts, &eventlineCfg.Config, lookup)
+		return err
+	}
+
+	eventlineURI := "postgres://eventline@localhost:5432/eventline"
+
+	// Subscriptions are disabled without allowed hosts
+	assert.Error(check("postgres://db.example.com/shop", eventlineURI, nil))
+
+	allowedHosts := []string{"db.example.com", "localhost"}
+
+	assert.NoError(check("postgres://db.example.com/shop", eventlineURI,
+		allowedHosts))
+	assert.NoError(check("postgres://DB.example.com/shop", eventlineURI,
+		allowedHosts))
+	assert.NoError(check("postgres://localhost:5432/shop", eventlineURI,
+		allowedHosts))
+	assert.NoError(check("postgres://db.example.com/eventline", eventlineURI,
+		allowedHosts))
+
+	// The Eventline database cannot be used, whatever the way its host is
+	// designated
+	assert.Error(check("postgres://localhost:5432/eventline", eventlineURI,
+		allowedHosts))
+	assert.Error(check("postgres://LOCALHOST/eventline", eventlineURI,
+		allowedHosts))
+	assert.Error(check("postgres://127.0.0.1/eventline", eventlineURI,
+		allowedHosts))
+	assert.Error(check("postgres://[::1]/eventline", eventlineURI,
+		allowedHosts))
+	assert.Error(check("postgres://127.0.0.2/eventline", eventlineURI,
+		[]string{"127.0.0.2"}))
+	assert.Error(check("postgres://0.0.0.0/eventline", eventlineURI,
+		[]string{"0.0.0.0"}))
+	assert.Error(check("postgres://local.example.com/eventline", eventlineURI,
+		allowedHosts))
+	assert.Error(check("postgres://db.example.com,localhost/eventline",
+		eventlineURI, allowedHosts))
+
+	assert.Error(check("postgres://alias.example.com/eventline",
+		"postgres://eventline@db.example.com/eventline", allowedHosts))
+	assert.Error(check("postgres://203.0.113.10/eventline",
+		"postgres://eventline@db.example.com/eventline", allowedHosts))
+
+	// Unix sockets are compared using their directory
+	socketURI := "host=/var/run/postgresql dbname=eventline"
+	socketAllowedHosts := []string{"/var/run/postgresql"}
+
+	assert.Error(check("host=/var/run/postgresql dbname=eventline",
+		socketURI, socketAllowedHosts))
+	assert.Error(check("host=/var/run/postgresql/ dbname=eventline",
+		socketURI, socketAllowedHosts))
+	assert.Error(check("host=/var/run/../run/postgresql dbname=eventline",
+		socketURI, socketAllowedHosts))
+	assert.NoError(check("host=/var/run/postgresql dbname=shop",
+		socketURI, socketAllowedHosts))
+
+	// Socket directories must be allowed explicitly
+	assert.Error(check("host=/var/run/postgresql dbname=shop", eventlineURI,
+		allowedHosts))
+	assert.Error(check("host=/tmp dbname=shop", socketURI,
+		socketAllowedHosts))
+
+	// Only allowed hosts can be used, using all their addresses
+	allowedHosts = []string{"db.example.com"}
+
+	assert.NoError(check("postgres://alias.example.com/shop", eventlineURI,
+		allowedHosts))
+	assert.NoError(check("postgres://203.0.113.10/shop", eventlineURI,
+		allowedHosts))
+	assert.Error(check("postgres://db2.example.com/shop", eventlineURI,
+		allowedHosts))
+	assert.Error(check("postgres://localhost:5432/shop", eventlineURI,
+		allowedHosts))
+	assert.Error(check("postgres://127.0.0.1/shop", eventlineURI,
+		allowedHosts))
+	assert.Error(check("postgres://mixed.example.com/shop", eventlineURI,
+		allowedHosts))
+	assert.Error(check("postgres://db.example.com,localhost/shop",
+		eventlineURI, allowedHosts))
+
+	// The hostaddr setting is not supported by pgx and is rejected
+	assert.Error(check("host=db.example.com hostaddr=127.0.0.1 dbname=shop",
+		eventlineURI, allowedHosts))
+	assert.Error(check("postgres://db.example.com/shop?hostaddr=127.0.0.1",
+		eventlineURI, allowedHosts))
+}
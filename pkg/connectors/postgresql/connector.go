@@ -1,18 +1,29 @@
 package postgresql
 
 import (
+	"fmt"
+	"net"
+	"time"
+
 	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"go.n16f.net/ejson"
 	"go.n16f.net/log"
+	"go.n16f.net/service/pkg/pg"
 )
 
 type ConnectorCfg struct {
+	AllowedHosts []string `json:"allowed_hosts"`
 }
 
 type Connector struct {
 	Def *eventline.ConnectorDef
 	Cfg *ConnectorCfg
 	Log *log.Logger
+
+	eventlineDatabase *pgconn.Config
+	lookupFunc        pgconn.LookupFunc
 }
 
 func NewConnector() *Connector {
@@ -20,12 +31,26 @@ func NewConnector() *Connector {
 
 	def.AddIdentity(PasswordIdentityDef())
 
-	return &Connector{
+	def.AddEvent(NotificationEventDef())
+	def.AddEvent(RowEventDef())
+
+	c := Connector{
 		Def: def,
+
+		lookupFunc: net.DefaultResolver.LookupHost,
 	}
+
+	def.Worker = NewWorker(&c)
+
+	return &c
 }
 
 func (cfg *ConnectorCfg) ValidateJSON(v *ejson.Validator) {
+	v.WithChild("allowed_hosts", func() {
+		for i, host := range cfg.AllowedHosts {
+			v.CheckStringNotEmpty(i, host)
+		}
+	})
 }
 
 func (c *Connector) Name() string {
@@ -44,8 +69,51 @@ func (c *Connector) Init(ccfg eventline.ConnectorCfg, initData eventline.Connect
 	c.Cfg = ccfg.(*ConnectorCfg)
 	c.Log = initData.Log
 
+	// Subscriptions must not be able to read the Eventline database
+	pgCfg, err := pgx.ParseConfig(initData.Pg.Cfg.URI)
+	if err != nil {
+		return fmt.Errorf("cannot parse database uri: %w", err)
+	}
+
+	c.eventlineDatabase = &pgCfg.Config
+
 	return nil
 }
 
 func (c *Connector) Terminate() {
 }
+
+func (c *Connector) Subscribe(conn pg.Conn, sctx *eventline.SubscriptionContext) error {
+	// Subscriptions connect to databases chosen by users deploying jobs, so
+	// they are disabled unless the administrator allows some hosts.
+	if len(c.Cfg.AllowedHosts) == 0 {
+		return fmt.Errorf("no database host is allowed in the configuration " +
+			"of the postgresql connector")
+	}
+
+	s := Subscription{
+		Id: sctx.Subscription.Id,
+	}
+
+	// Row subscriptions are polled by the connector worker, starting as soon
+	// as possible to initialize the cursor. Notification subscriptions are
+	// handled by listeners.
+	if sctx.Subscription.Event == "row" {
+		now := time.Now().UTC()
+		s.NextPollTime = &now
+	}
+
+	if err := s.Insert(conn); err != nil {
+		return fmt.Errorf("cannot insert subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Connector) Unsubscribe(conn pg.Conn, sctx *eventline.SubscriptionContext) error {
+	if err := DeleteSubscription(conn, sctx.Subscription.Id); err != nil {
+		return fmt.Errorf("cannot delete subscription: %w", err)
+	}
+
+	return nil
+}
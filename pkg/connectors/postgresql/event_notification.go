@@ -0,0 +1,16 @@
+package postgresql
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+)
+
+type NotificationEvent struct {
+	Channel string      `json:"channel"`
+	PID     uint32      `json:"pid"`
+	Payload interface{} `json:"payload"`
+}
+
+func NotificationEventDef() *eventline.EventDef {
+	return eventline.NewEventDef("notification",
+		&NotificationEvent{}, &NotificationParameters{})
+}
@@ -0,0 +1,14 @@
+package postgresql
+
+import (
+	"github.com/exograd/eventline/pkg/eventline"
+)
+
+type RowEvent struct {
+	Cursor string                 `json:"cursor"`
+	Row    map[string]interface{} `json:"row"`
+}
+
+func RowEventDef() *eventline.EventDef {
+	return eventline.NewEventDef("row", &RowEvent{}, &RowParameters{})
+}
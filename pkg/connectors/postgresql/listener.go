@@ -0,0 +1,112 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/exograd/eventline/pkg/connectors/mq"
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.n16f.net/service/pkg/pg"
+)
+
+// Listener is a consumer executing LISTEN on a channel of an external
+// database. PostgreSQL does not store notifications: those sent while the
+// listener is not connected, or which could not be stored, are lost.
+type Listener struct {
+	Connector  *Connector
+	Parameters *NotificationParameters
+	Identity   *eventline.Identity
+}
+
+type Notification struct {
+	Notification *pgconn.Notification
+	Format       string
+}
+
+func (c *Connector) NewListener(sub *eventline.Subscription, identity *eventline.Identity) (mq.Consumer, error) {
+	listener := Listener{
+		Connector:  c,
+		Parameters: sub.Parameters.(*NotificationParameters),
+		Identity:   identity,
+	}
+
+	return &listener, nil
+}
+
+func (l *Listener) Run(ctx context.Context, handler mq.Handler) error {
+	conn, err := l.Connector.Connect(ctx, l.Parameters.URI, l.Identity)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	channel := pgx.Identifier{l.Parameters.Channel}.Sanitize()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+channel); err != nil {
+		return fmt.Errorf("cannot listen on channel %q: %w",
+			l.Parameters.Channel, err)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("cannot wait for notification: %w", err)
+		}
+
+		msg := Notification{
+			Notification: n,
+			Format:       l.Parameters.BodyFormat(),
+		}
+
+		if err := handler(&msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (n *Notification) Decode() (*mq.Delivery, error) {
+	payload, err := mq.DecodeBody(n.Format, []byte(n.Notification.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	event := NotificationEvent{
+		Channel: n.Notification.Channel,
+		PID:     n.Notification.PID,
+		Payload: payload,
+	}
+
+	store := func(conn pg.Conn, subs eventline.Subscriptions) error {
+		now := time.Now().UTC()
+
+		for _, sub := range subs {
+			err := UpdateSubscriptionLastNotificationTime(conn, sub.Id, now)
+			if err != nil {
+				return fmt.Errorf("cannot update subscription: %w", err)
+			}
+		}
+
+		return nil
+	}
+
+	return &mq.Delivery{Data: &event, Store: store}, nil
+}
+
+func (n *Notification) Ack() error {
+	return nil
+}
+
+func (n *Notification) Requeue() error {
+	return nil
+}
+
+func (n *Notification) Reject() error {
+	return nil
+}
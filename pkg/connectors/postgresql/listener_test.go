@@ -0,0 +1,48 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/exograd/eventline/pkg/connectors/mq"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationDecode(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	params := NotificationParameters{Channel: "orders"}
+
+	n := Notification{
+		Notification: &pgconn.Notification{
+			PID:     42,
+			Channel: "orders",
+			Payload: `{"id": 1}`,
+		},
+		Format: params.BodyFormat(),
+	}
+
+	// Payloads are not decoded by default
+	d, err := n.Decode()
+	require.NoError(err)
+	assert.Equal(&NotificationEvent{
+		Channel: "orders",
+		PID:     42,
+		Payload: `{"id": 1}`,
+	}, d.Data)
+	assert.NotNil(d.Store)
+
+	n.Format = mq.BodyFormatJSON
+
+	d, err = n.Decode()
+	require.NoError(err)
+	assert.Equal(map[string]interface{}{"id": 1.0},
+		d.Data.(*NotificationEvent).Payload)
+
+	n.Notification.Payload = ""
+
+	_, err = n.Decode()
+	assert.Error(err)
+}
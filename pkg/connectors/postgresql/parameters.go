@@ -0,0 +1,58 @@
+package postgresql
+
+import (
+	"github.com/exograd/eventline/pkg/connectors/mq"
+	"go.n16f.net/ejson"
+)
+
+const (
+	DefaultPollingInterval = 60 // seconds
+	MinPollingInterval     = 10 // seconds
+)
+
+type NotificationParameters struct {
+	URI     string `json:"uri"`
+	Channel string `json:"channel"`
+	Format  string `json:"format,omitempty"`
+}
+
+type RowParameters struct {
+	URI      string `json:"uri"`
+	Query    string `json:"query"`
+	Cursor   string `json:"cursor"`
+	Interval int    `json:"interval,omitempty"` // seconds
+}
+
+func (p *NotificationParameters) ValidateJSON(v *ejson.Validator) {
+	v.CheckStringURI("uri", p.URI)
+	v.CheckStringNotEmpty("channel", p.Channel)
+	mq.CheckBodyFormat(v, "format", p.Format)
+}
+
+// BodyFormat returns the format of notification payloads. Payloads are often
+// simple character strings, so they are not decoded by default.
+func (p *NotificationParameters) BodyFormat() string {
+	if p.Format == "" {
+		return mq.BodyFormatRaw
+	}
+
+	return p.Format
+}
+
+func (p *RowParameters) ValidateJSON(v *ejson.Validator) {
+	v.CheckStringURI("uri", p.URI)
+	v.CheckStringNotEmpty("query", p.Query)
+	v.CheckStringNotEmpty("cursor", p.Cursor)
+
+	if p.Interval != 0 {
+		v.CheckIntMin("interval", p.Interval, MinPollingInterval)
+	}
+}
+
+func (p *RowParameters) PollingInterval() int {
+	if p.Interval == 0 {
+		return DefaultPollingInterval
+	}
+
+	return p.Interval
+}
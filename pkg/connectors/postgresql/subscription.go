@@ -0,0 +1,127 @@
+package postgresql
+
+import (
+	"errors"
+	"time"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/jackc/pgx/v5"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+type Subscription struct {
+	Id                   uuid.UUID
+	LastNotificationTime *time.Time
+	LastPollTime         *time.Time
+	NextPollTime         *time.Time // only for row subscriptions
+	Cursor               *string
+}
+
+func LoadSubscriptionForPolling(conn pg.Conn) (*Subscription, *eventline.Subscription, error) {
+	now := time.Now().UTC()
+
+	query := `
+SELECT s.id, s.last_notification_time, s.last_poll_time, s.next_poll_time,
+       s.row_cursor
+  FROM subscriptions AS es
+  JOIN c_postgresql_subscriptions AS s ON s.id = es.id
+  WHERE es.status = 'active'
+    AND s.next_poll_time <= $1
+  ORDER BY s.next_poll_time
+  LIMIT 1
+  FOR UPDATE OF s SKIP LOCKED
+`
+	var s Subscription
+	var es eventline.Subscription
+
+	err := pg.QueryObject(conn, &s, query, now)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	if err := es.Load(conn, s.Id); err != nil {
+		return nil, nil, err
+	}
+
+	return &s, &es, nil
+}
+
+// LoadSubscriptionForUpdate loads and locks a subscription. It returns nil if
+// the subscription does not exist anymore or is not active.
+func LoadSubscriptionForUpdate(conn pg.Conn, id uuid.UUID) (*Subscription, *eventline.Subscription, error) {
+	query := `
+SELECT s.id, s.last_notification_time, s.last_poll_time, s.next_poll_time,
+       s.row_cursor
+  FROM subscriptions AS es
+  JOIN c_postgresql_subscriptions AS s ON s.id = es.id
+  WHERE es.id = $1
+    AND es.status = 'active'
+  FOR UPDATE OF s
+`
+	var s Subscription
+	var es eventline.Subscription
+
+	err := pg.QueryObject(conn, &s, query, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	if err := es.Load(conn, s.Id); err != nil {
+		return nil, nil, err
+	}
+
+	return &s, &es, nil
+}
+
+func (s *Subscription) Insert(conn pg.Conn) error {
+	query := `
+INSERT INTO c_postgresql_subscriptions
+    (id, last_notification_time, last_poll_time, next_poll_time, row_cursor)
+  VALUES
+    ($1, $2, $3, $4, $5);
+`
+	return pg.Exec(conn, query,
+		s.Id, s.LastNotificationTime, s.LastPollTime, s.NextPollTime,
+		s.Cursor)
+}
+
+func (s *Subscription) Update(conn pg.Conn) error {
+	query := `
+UPDATE c_postgresql_subscriptions SET
+    last_notification_time = $2,
+    last_poll_time = $3,
+    next_poll_time = $4,
+    row_cursor = $5
+  WHERE id = $1
+`
+	return pg.Exec(conn, query,
+		s.Id, s.LastNotificationTime, s.LastPollTime, s.NextPollTime,
+		s.Cursor)
+}
+
+func UpdateSubscriptionLastNotificationTime(conn pg.Conn, id uuid.UUID, t time.Time) error {
+	query := `
+UPDATE c_postgresql_subscriptions SET
+    last_notification_time = $2
+  WHERE id = $1
+`
+	return pg.Exec(conn, query, id, t)
+}
+
+func DeleteSubscription(conn pg.Conn, id uuid.UUID) error {
+	query := `
+DELETE FROM c_postgresql_subscriptions
+  WHERE id = $1;
+`
+	return pg.Exec(conn, query, id)
+}
+
+func (s *Subscription) FromRow(row pgx.Row) error {
+	return row.Scan(&s.Id, &s.LastNotificationTime, &s.LastPollTime,
+		&s.NextPollTime, &s.Cursor)
+}
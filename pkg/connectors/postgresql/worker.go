@@ -0,0 +1,285 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exograd/eventline/pkg/connectors/mq"
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/jackc/pgx/v5"
+	"go.n16f.net/log"
+	"go.n16f.net/service/pkg/pg"
+)
+
+// The maximum number of rows read each time a subscription is polled. If
+// there are more new rows, the subscription is polled again immediately.
+const rowBatchSize = 100
+
+// The maximum duration of queries executed on external databases.
+const queryTimeout = 30 * time.Second
+
+// Worker polls row subscriptions and maintains listeners for notification
+// subscriptions.
+type Worker struct {
+	Log *log.Logger
+	Pg  *pg.Client
+
+	connector *Connector
+	listeners *mq.Worker
+
+	worker *eventline.Worker
+}
+
+func NewWorker(c *Connector) *Worker {
+	return &Worker{
+		connector: c,
+		listeners: mq.NewWorker("postgresql", "notification", c.NewListener),
+	}
+}
+
+func (w *Worker) Init(ew *eventline.Worker) {
+	w.Log = ew.Log
+	w.Pg = ew.Pg
+
+	w.worker = ew
+
+	w.listeners.Init(ew)
+}
+
+func (w *Worker) Start() error {
+	return w.listeners.Start()
+}
+
+func (w *Worker) Stop() {
+	w.listeners.Stop()
+}
+
+// ProcessJob polls a row subscription. The external database is read outside
+// of any transaction: the subscription is first reserved for the duration of
+// the query, and rows are then stored in a second transaction.
+func (w *Worker) ProcessJob() (bool, error) {
+	var s *Subscription
+	var es *eventline.Subscription
+	var identity *eventline.Identity
+
+	err := w.Pg.WithTx(func(conn pg.Conn) (err error) {
+		s, es, identity, err = w.reserveSubscription(conn)
+		return
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if s == nil {
+		// Once there are no more subscriptions to poll, we update listeners
+		return w.listeners.ProcessJob()
+	}
+
+	w.Log.Info("polling subscription %q", s.Id)
+
+	params := es.Parameters.(*RowParameters)
+
+	// The external database may be unavailable; this is not an error of
+	// the worker, so we only log it and try again at the next poll.
+	rows, cursor, pollErr := w.readRows(params, identity, s)
+	if pollErr != nil {
+		w.Log.Error("cannot poll subscription %q: %v", s.Id, pollErr)
+	}
+
+	var events eventline.Events
+
+	err = w.Pg.WithTx(func(conn pg.Conn) (err error) {
+		events, err = w.storeRows(conn, s, rows, cursor, pollErr == nil)
+		return
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, event := range events {
+		w.worker.Cfg.NotificationChan <- event
+	}
+
+	return true, nil
+}
+
+// reserveSubscription loads the next subscription to poll and delays its
+// next poll so that it is not polled again while the external database is
+// being read.
+func (w *Worker) reserveSubscription(conn pg.Conn) (*Subscription, *eventline.Subscription, *eventline.Identity, error) {
+	s, es, err := LoadSubscriptionForPolling(conn)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot load subscription: %w", err)
+	} else if s == nil {
+		return nil, nil, nil, nil
+	}
+
+	var identity *eventline.Identity
+	if es.IdentityId != nil {
+		identity = new(eventline.Identity)
+
+		scope := eventline.NewProjectScope(*es.ProjectId)
+		if err := identity.Load(conn, *es.IdentityId, scope); err != nil {
+			return nil, nil, nil, fmt.Errorf("cannot load identity %q: %w",
+				*es.IdentityId, err)
+		}
+	}
+
+	nextPollTime := time.Now().UTC().Add(2 * queryTimeout)
+	s.NextPollTime = &nextPollTime
+
+	if err := s.Update(conn); err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot update subscription: %w", err)
+	}
+
+	return s, es, identity, nil
+}
+
+// storeRows dispatches the events created for rows read from the external
+// database and updates the subscription. Rows are ignored if the
+// subscription was deleted or polled by someone else in the mean time.
+func (w *Worker) storeRows(conn pg.Conn, s *Subscription, rows []*RowEvent, cursor *string, success bool) (eventline.Events, error) {
+	current, es, err := LoadSubscriptionForUpdate(conn, s.Id)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load subscription: %w", err)
+	} else if current == nil {
+		return nil, nil
+	}
+
+	if !equalCursors(current.Cursor, s.Cursor) {
+		w.Log.Info("ignoring rows of subscription %q: cursor was updated "+
+			"concurrently", s.Id)
+		return nil, nil
+	}
+
+	params := es.Parameters.(*RowParameters)
+
+	now := time.Now().UTC()
+
+	var events eventline.Events
+
+	if success {
+		for _, row := range rows {
+			rowEvents, err := eventline.DispatchEvent(conn,
+				eventline.Subscriptions{es}, "postgresql", "row", &now, row)
+			if err != nil {
+				return nil, err
+			}
+
+			events = append(events, rowEvents...)
+		}
+
+		current.Cursor = cursor
+		current.LastPollTime = &now
+	}
+
+	nextPollTime := now.Add(time.Duration(params.PollingInterval()) *
+		time.Second)
+	if len(rows) == rowBatchSize {
+		nextPollTime = now
+	}
+
+	current.NextPollTime = &nextPollTime
+
+	if err := current.Update(conn); err != nil {
+		return nil, fmt.Errorf("cannot update subscription: %w", err)
+	}
+
+	return events, nil
+}
+
+func equalCursors(c1, c2 *string) bool {
+	if c1 == nil || c2 == nil {
+		return c1 == nil && c2 == nil
+	}
+
+	return *c1 == *c2
+}
+
+// readRows returns the rows with a cursor value greater than the cursor of
+// the subscription, and the new value of the cursor. The first time a
+// subscription is polled, the cursor is initialized with the greatest value
+// of the cursor column and no row is returned: only rows added after the
+// subscription are considered new.
+func (w *Worker) readRows(params *RowParameters, identity *eventline.Identity, s *Subscription) ([]*RowEvent, *string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	conn, err := w.connector.Connect(ctx, params.URI, identity)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer conn.Close(context.Background())
+
+	if s.LastPollTime == nil {
+		var cursor *string
+
+		query := CursorInitializationQuery(params)
+		if err := conn.QueryRow(ctx, query).Scan(&cursor); err != nil {
+			return nil, nil, fmt.Errorf("cannot initialize cursor: %w", err)
+		}
+
+		return nil, cursor, nil
+	}
+
+	// Cursor values are sent as literals so that PostgreSQL converts them to
+	// the type of the cursor column, whatever it is.
+	dbRows, err := conn.Query(ctx, RowQuery(params),
+		pgx.QueryExecModeSimpleProtocol, s.Cursor)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot execute query: %w", err)
+	}
+	defer dbRows.Close()
+
+	var rows []*RowEvent
+
+	for dbRows.Next() {
+		var rowData []byte
+		var row RowEvent
+
+		if err := dbRows.Scan(&rowData, &row.Cursor); err != nil {
+			return nil, nil, fmt.Errorf("cannot read row: %w", err)
+		}
+
+		if err := json.Unmarshal(rowData, &row.Row); err != nil {
+			return nil, nil, fmt.Errorf("cannot decode row: %w", err)
+		}
+
+		rows = append(rows, &row)
+	}
+
+	if err := dbRows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("cannot read rows: %w", err)
+	}
+
+	cursor := s.Cursor
+	if len(rows) > 0 {
+		cursor = &rows[len(rows)-1].Cursor
+	}
+
+	return rows, cursor, nil
+}
+
+func CursorInitializationQuery(params *RowParameters) string {
+	return fmt.Sprintf(`SELECT max(q.%s)::TEXT FROM (%s) AS q`,
+		pgx.Identifier{params.Cursor}.Sanitize(), userQuery(params))
+}
+
+func RowQuery(params *RowParameters) string {
+	cursor := "q." + pgx.Identifier{params.Cursor}.Sanitize()
+
+	return fmt.Sprintf(`SELECT row_to_json(q)::TEXT, %[1]s::TEXT
+  FROM (%[2]s) AS q
+  WHERE %[1]s IS NOT NULL
+    AND ($1::TEXT IS NULL OR %[1]s > $1)
+  ORDER BY %[1]s
+  LIMIT %[3]d`, cursor, userQuery(params), rowBatchSize)
+}
+
+func userQuery(params *RowParameters) string {
+	query := strings.TrimSpace(params.Query)
+	return strings.TrimSpace(strings.TrimRight(query, ";"))
+}
@@ -0,0 +1,32 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRowQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	params := RowParameters{
+		Query:  "SELECT id, status FROM orders WHERE status = 'paid';\n",
+		Cursor: "id",
+	}
+
+	assert.Equal(`SELECT max(q."id")::TEXT FROM `+
+		`(SELECT id, status FROM orders WHERE status = 'paid') AS q`,
+		CursorInitializationQuery(&params))
+
+	assert.Equal(`SELECT row_to_json(q)::TEXT, q."id"::TEXT
+  FROM (SELECT id, status FROM orders WHERE status = 'paid') AS q
+  WHERE q."id" IS NOT NULL
+    AND ($1::TEXT IS NULL OR q."id" > $1)
+  ORDER BY q."id"
+  LIMIT 100`, RowQuery(&params))
+
+	// Cursor columns are quoted identifiers
+	params.Cursor = `creation "time"`
+
+	assert.Contains(RowQuery(&params), `ORDER BY q."creation ""time"""`)
+}
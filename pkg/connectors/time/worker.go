@@ -87,14 +87,19 @@ func (w *Worker) processSubscription(conn pg.Conn, s *Subscription, es *eventlin
 	s.LastTick = &etime
 	s.NextTick = params.NextTick(etime)
 
-	event := es.NewEvent("time", "tick", &etime, &TickEvent{})
-	if err := event.Insert(conn); err != nil {
-		return nil, fmt.Errorf("cannot insert event: %w", err)
+	events, err := eventline.DispatchEvent(conn, eventline.Subscriptions{es},
+		"time", "tick", &etime, &TickEvent{})
+	if err != nil {
+		return nil, err
 	}
 
 	if err := s.Update(conn); err != nil {
 		return nil, fmt.Errorf("cannot update subscription: %w", err)
 	}
 
-	return event, nil
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	return events[0], nil
 }
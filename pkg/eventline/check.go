@@ -19,6 +19,8 @@ const (
 
 var (
 	NameRE = regexp.MustCompile(`^[a-z0-9][a-z0-9\-_]*$`)
+
+	EnvironmentVariableNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
 )
 
 func CheckName(v *ejson.Validator, token interface{}, name string) {
@@ -32,6 +34,17 @@ func CheckName(v *ejson.Validator, token interface{}, name string) {
 
 }
 
+func CheckEnvironmentVariableName(v *ejson.Validator, token interface{}, name string) {
+	v.CheckStringLengthMinMax(token, name, MinNameLength, MaxNameLength)
+
+	if name != "" {
+		v.CheckStringMatch2(token, name, EnvironmentVariableNameRE,
+			"invalid_format",
+			"environment variable names must only contain alphanumeric "+
+				"characters or '_', and must not start with a digit")
+	}
+}
+
 func CheckLabel(v *ejson.Validator, token string, label string) {
 	v.CheckStringLengthMinMax(token, label, MinLabelLength, MaxLabelLength)
 }
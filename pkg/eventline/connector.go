@@ -1,6 +1,7 @@
 package eventline
 
 import (
+	"context"
 	"net/url"
 
 	"go.n16f.net/ejson"
@@ -41,3 +42,19 @@ type OptionalConnector interface {
 
 	Enabled() bool
 }
+
+// Reporting connectors can report the status of job executions triggered by
+// their events back to the external service which emitted them, e.g. as
+// commit statuses.
+type ReportingConnector interface {
+	Connector
+
+	ReportJobExecution(context.Context, *JobExecutionReport) error
+}
+
+type JobExecutionReport struct {
+	JobExecution    *JobExecution
+	Event           *Event
+	Identity        *Identity
+	JobExecutionURI string
+}
@@ -34,6 +34,16 @@ func GetConnector(name string) Connector {
 	return c
 }
 
+func ConnectorSupportsReporting(name string) bool {
+	c, found := Connectors[name]
+	if !found {
+		return false
+	}
+
+	_, ok := c.(ReportingConnector)
+	return ok
+}
+
 func GetConnectorDef(name string) *ConnectorDef {
 	c := GetConnector(name)
 	return c.Definition()
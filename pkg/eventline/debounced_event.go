@@ -0,0 +1,77 @@
+package eventline
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+// A debounced event is the latest event received by a job with a debounce
+// trigger option for a specific key. The job is executed with this event
+// once the scheduled time is reached, unless a new event with the same key
+// is received before, in which case the debounced event is replaced.
+type DebouncedEvent struct {
+	JobId         uuid.UUID
+	Key           string
+	ProjectId     uuid.UUID
+	EventId       uuid.UUID
+	ScheduledTime time.Time
+}
+
+func LoadDebouncedEventForProcessing(conn pg.Conn, now time.Time) (*DebouncedEvent, error) {
+	query := `
+SELECT job_id, key, project_id, event_id, scheduled_time
+  FROM debounced_events
+  WHERE scheduled_time <= $1
+  ORDER BY scheduled_time
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED;
+`
+	var de DebouncedEvent
+	err := pg.QueryObject(conn, &de, query, now)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &de, nil
+}
+
+func (de *DebouncedEvent) Upsert(conn pg.Conn) error {
+	query := `
+INSERT INTO debounced_events
+    (job_id, key, project_id, event_id, scheduled_time)
+  VALUES
+    ($1, $2, $3, $4, $5)
+  ON CONFLICT (job_id, key) DO UPDATE SET
+    event_id = EXCLUDED.event_id,
+    scheduled_time = EXCLUDED.scheduled_time;
+`
+	return pg.Exec(conn, query,
+		de.JobId, de.Key, de.ProjectId, de.EventId, de.ScheduledTime)
+}
+
+func (de *DebouncedEvent) Delete(conn pg.Conn) error {
+	query := `
+DELETE FROM debounced_events
+  WHERE job_id = $1 AND key = $2;
+`
+	return pg.Exec(conn, query, de.JobId, de.Key)
+}
+
+func DeleteJobDebouncedEvents(conn pg.Conn, jobId uuid.UUID) error {
+	query := `
+DELETE FROM debounced_events
+  WHERE job_id = $1;
+`
+	return pg.Exec(conn, query, jobId)
+}
+
+func (de *DebouncedEvent) FromRow(row pgx.Row) error {
+	return row.Scan(&de.JobId, &de.Key, &de.ProjectId, &de.EventId,
+		&de.ScheduledTime)
+}
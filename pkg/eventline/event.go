@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -22,6 +23,48 @@ var EventSorts Sorts = Sorts{
 	Default: "event_time",
 }
 
+type EventPageOptions struct {
+	EventIds  []uuid.UUID
+	Connector string
+	Name      string
+	JobId     *uuid.UUID
+	Processed *bool
+	StartTime *time.Time // inclusive
+	EndTime   *time.Time // exclusive
+	Filters   Filters    // applied to event data
+	Failed    bool       // only events with a failed job execution
+}
+
+// The set of events replayed by a batch replay. Events must match all
+// criteria; at least one criterion is required.
+type EventReplaySelection struct {
+	EventIds  []uuid.UUID `json:"event_ids,omitempty"`
+	Connector string      `json:"connector,omitempty"`
+	Name      string      `json:"name,omitempty"`
+	JobId     *uuid.UUID  `json:"job_id,omitempty"`
+	StartTime *time.Time  `json:"start,omitempty"`
+	EndTime   *time.Time  `json:"end,omitempty"`
+	Filters   Filters     `json:"filters,omitempty"`
+	Failed    bool        `json:"failed,omitempty"`
+}
+
+type EventReplayResult struct {
+	Count  int    `json:"count"`
+	Events Events `json:"events,omitempty"` // empty for dry runs
+}
+
+// MaxEventReplayCount is the maximum number of events which can be replayed
+// with a single selection.
+const MaxEventReplayCount = 1000
+
+type TooManyEventsError struct {
+	Max int
+}
+
+func (err TooManyEventsError) Error() string {
+	return fmt.Sprintf("selection matches more than %d events", err.Max)
+}
+
 type UnknownEventError struct {
 	Id uuid.UUID
 }
@@ -38,17 +81,25 @@ type NewEvent struct {
 	RawData   json.RawMessage `json:"data"`
 }
 
+// The content of a request creating custom events, i.e. eventline/custom
+// events delivered to all jobs subscribed to the name of the event.
+type NewCustomEvent struct {
+	Name      string                 `json:"name"`
+	EventTime *time.Time             `json:"event_time,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
 type Event struct {
 	Id              uuid.UUID   `json:"id"`
 	ProjectId       uuid.UUID   `json:"project_id"`
-	JobId           uuid.UUID   `json:"job_id"`
+	JobIds          []uuid.UUID `json:"job_ids"` // jobs the event was delivered to
 	CreationTime    time.Time   `json:"creation_time"`
 	EventTime       time.Time   `json:"event_time"`
 	Connector       string      `json:"connector"`
 	Name            string      `json:"name"`
 	Data            EventData   `json:"data"`
 	DataValue       interface{} `json:"-"`
-	Processed       bool        `json:"processed,omitempty"`
+	Processed       bool        `json:"processed,omitempty"` // all deliveries processed
 	OriginalEventId *uuid.UUID  `json:"original_event_id,omitempty"`
 }
 
@@ -60,6 +111,47 @@ func (ne *NewEvent) ValidateJSON(v *ejson.Validator) {
 	}
 }
 
+func (es *EventReplaySelection) ValidateJSON(v *ejson.Validator) {
+	if es.Connector != "" {
+		if CheckConnectorName(v, "connector", es.Connector) && es.Name != "" {
+			CheckEventName(v, "name", es.Connector, es.Name)
+		}
+	}
+
+	v.CheckObjectArray("filters", es.Filters)
+	v.WithChild("filters", func() {
+		for i, f := range es.Filters {
+			if err := f.CheckSQLRegexps(); err != nil {
+				v.AddError(i, "unsupported_regexp", "%v", err)
+			}
+		}
+	})
+
+	empty := len(es.EventIds) == 0 && es.Connector == "" && es.Name == "" &&
+		es.JobId == nil && es.StartTime == nil && es.EndTime == nil &&
+		len(es.Filters) == 0 && !es.Failed
+
+	v.Check(nil, !empty, "empty_selection",
+		"selection must contain at least one criterion")
+}
+
+func (es *EventReplaySelection) PageOptions() EventPageOptions {
+	return EventPageOptions{
+		EventIds:  es.EventIds,
+		Connector: es.Connector,
+		Name:      es.Name,
+		JobId:     es.JobId,
+		StartTime: es.StartTime,
+		EndTime:   es.EndTime,
+		Filters:   es.Filters,
+		Failed:    es.Failed,
+	}
+}
+
+func (ne *NewCustomEvent) ValidateJSON(v *ejson.Validator) {
+	CheckName(v, "name", ne.Name)
+}
+
 func (e *Event) SortKey(sort string) (key string) {
 	switch sort {
 	case "id":
@@ -103,13 +195,33 @@ func (e *Event) Def() *EventDef {
 	return cdef.Event(e.Name)
 }
 
+// Events are visible in their own project and in all projects they were
+// delivered to. Job ids are restricted to the jobs visible in the scope.
+func eventColumns(scope Scope) string {
+	return fmt.Sprintf(`
+events.id, events.project_id,
+ARRAY(SELECT d.job_id FROM event_deliveries AS d
+        WHERE d.event_id = events.id AND %s
+        ORDER BY d.job_id)::TEXT[],
+events.creation_time, events.event_time,
+events.connector, events.name, events.data,
+NOT EXISTS (SELECT 1 FROM event_deliveries AS d
+              WHERE d.event_id = events.id AND NOT d.processed),
+events.original_event_id`, scope.SQLCondition2("d"))
+}
+
+func eventScopeCondition(scope Scope) string {
+	return fmt.Sprintf(`(%s OR EXISTS (SELECT 1 FROM event_deliveries AS d
+   WHERE d.event_id = events.id AND %s))`,
+		scope.SQLCondition2("events"), scope.SQLCondition2("d"))
+}
+
 func (e *Event) Load(conn pg.Conn, id uuid.UUID, scope Scope) error {
 	query := fmt.Sprintf(`
-SELECT id, project_id, job_id, creation_time, event_time,
-       connector, name, data, processed, original_event_id
+SELECT %s
   FROM events
   WHERE %s AND id = $1
-`, scope.SQLCondition())
+`, eventColumns(scope), eventScopeCondition(scope))
 
 	err := pg.QueryObject(conn, e, query, id)
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -119,33 +231,36 @@ SELECT id, project_id, job_id, creation_time, event_time,
 	return err
 }
 
-func LoadEventForProcessing(conn pg.Conn) (*Event, error) {
-	query := `
-SELECT id, project_id, job_id, creation_time, event_time,
-       connector, name, data, processed, original_event_id
+// LoadEvents loads all events matching a set of options. It returns
+// TooManyEventsError if there are more than max events.
+func LoadEvents(conn pg.Conn, options EventPageOptions, max int, scope Scope) (Events, error) {
+	query := fmt.Sprintf(`
+SELECT %s
   FROM events
-  WHERE processed = FALSE and job_id IS NOT NULL
-  LIMIT 1
-  FOR UPDATE SKIP LOCKED;
-`
-	var e Event
-	err := pg.QueryObject(conn, &e, query)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	} else if err != nil {
+  WHERE %s AND %s
+  ORDER BY event_time, id
+  LIMIT $1
+`, eventColumns(scope), eventScopeCondition(scope), options.SQLCondition())
+
+	var events Events
+	if err := pg.QueryObjects(conn, &events, query, max+1); err != nil {
 		return nil, err
 	}
 
-	return &e, nil
+	if len(events) > max {
+		return nil, &TooManyEventsError{Max: max}
+	}
+
+	return events, nil
 }
 
-func LoadEventPage(conn pg.Conn, cursor *Cursor, scope Scope) (*Page, error) {
+func LoadEventPage(conn pg.Conn, options EventPageOptions, cursor *Cursor, scope Scope) (*Page, error) {
 	query := fmt.Sprintf(`
-SELECT id, project_id, job_id, creation_time, event_time,
-       connector, name, data, processed, original_event_id
+SELECT %s
   FROM events
-  WHERE %s AND %s
-`, scope.SQLCondition(), cursor.SQLConditionOrderLimit(EventSorts))
+  WHERE %s AND %s AND %s
+`, eventColumns(scope), eventScopeCondition(scope), options.SQLCondition(),
+		cursor.SQLConditionOrderLimit(EventSorts))
 
 	var events Events
 	if err := pg.QueryObjects(conn, &events, query); err != nil {
@@ -155,30 +270,96 @@ SELECT id, project_id, job_id, creation_time, event_time,
 	return events.Page(cursor), nil
 }
 
-func (e *Event) Insert(conn pg.Conn) error {
-	query := `
-INSERT INTO events
-    (id, project_id, job_id, creation_time, event_time,
-     connector, name, data, processed, original_event_id)
-  VALUES
-    ($1, $2, $3, $4, $5,
-     $6, $7, $8, $9, $10);
-`
+func (options *EventPageOptions) SQLCondition() string {
+	conds := []string{"TRUE"}
 
-	return pg.Exec(conn, query,
-		e.Id, e.ProjectId, e.JobId, e.CreationTime, e.EventTime,
-		e.Connector, e.Name, e.Data, e.Processed, e.OriginalEventId)
+	if len(options.EventIds) > 0 {
+		ids := make([]string, len(options.EventIds))
+		for i, id := range options.EventIds {
+			ids[i] = pg.QuoteString(id.String())
+		}
+
+		conds = append(conds, "id IN ("+strings.Join(ids, ", ")+")")
+	}
+
+	if options.Connector != "" {
+		conds = append(conds, "connector = "+pg.QuoteString(options.Connector))
+	}
+
+	if options.Name != "" {
+		conds = append(conds, "name = "+pg.QuoteString(options.Name))
+	}
+
+	if options.JobId != nil {
+		conds = append(conds, `EXISTS
+  (SELECT 1 FROM event_deliveries
+     WHERE event_deliveries.event_id = events.id
+       AND event_deliveries.job_id = `+
+			pg.QuoteString(options.JobId.String())+`)`)
+	}
+
+	if options.Processed != nil {
+		// An event is processed once all its deliveries are processed
+		cond := `EXISTS
+  (SELECT 1 FROM event_deliveries
+     WHERE event_deliveries.event_id = events.id
+       AND NOT event_deliveries.processed)`
+
+		if *options.Processed {
+			cond = "NOT " + cond
+		}
+
+		conds = append(conds, cond)
+	}
+
+	if t := options.StartTime; t != nil {
+		conds = append(conds,
+			"event_time >= "+pg.QuoteString(t.Format(time.RFC3339Nano)))
+	}
+
+	if t := options.EndTime; t != nil {
+		conds = append(conds,
+			"event_time < "+pg.QuoteString(t.Format(time.RFC3339Nano)))
+	}
+
+	if len(options.Filters) > 0 {
+		conds = append(conds, options.Filters.SQLCondition("data"))
+	}
+
+	// Events are considered failed if the last execution of one of the
+	// jobs they were delivered to failed. Events which have already been
+	// replayed are ignored: the replay is the event to consider.
+	if options.Failed {
+		conds = append(conds, `EXISTS
+  (SELECT 1 FROM job_executions AS je
+     WHERE je.event_id = events.id
+       AND je.status = 'failed'
+       AND NOT EXISTS
+         (SELECT 1 FROM job_executions AS je2
+            WHERE je2.event_id = events.id
+              AND je2.job_id = je.job_id
+              AND je2.creation_time > je.creation_time))`,
+			`NOT EXISTS
+  (SELECT 1 FROM events AS replays
+     WHERE replays.original_event_id = events.id)`)
+	}
+
+	return strings.Join(conds, " AND ")
 }
 
-func (e *Event) Update(conn pg.Conn) error {
+func (e *Event) Insert(conn pg.Conn) error {
 	query := `
-UPDATE events SET
-    processed = $2
-  WHERE id = $1
+INSERT INTO events
+    (id, project_id, creation_time, event_time,
+     connector, name, data, original_event_id)
+  VALUES
+    ($1, $2, $3, $4,
+     $5, $6, $7, $8);
 `
 
 	return pg.Exec(conn, query,
-		e.Id, e.Processed)
+		e.Id, e.ProjectId, e.CreationTime, e.EventTime,
+		e.Connector, e.Name, e.Data, e.OriginalEventId)
 }
 
 func (es Events) Page(cursor *Cursor) *Page {
@@ -191,14 +372,22 @@ func (es Events) Page(cursor *Cursor) *Page {
 }
 
 func (e *Event) FromRow(row pgx.Row) error {
+	var jobIds []string
 	var rawData []byte
 
-	err := row.Scan(&e.Id, &e.ProjectId, &e.JobId, &e.CreationTime, &e.EventTime,
+	err := row.Scan(&e.Id, &e.ProjectId, &jobIds, &e.CreationTime, &e.EventTime,
 		&e.Connector, &e.Name, &rawData, &e.Processed, &e.OriginalEventId)
 	if err != nil {
 		return err
 	}
 
+	e.JobIds = make([]uuid.UUID, len(jobIds))
+	for i, s := range jobIds {
+		if err := e.JobIds[i].Parse(s); err != nil {
+			return fmt.Errorf("invalid job id %q: %w", s, err)
+		}
+	}
+
 	edef := e.Def()
 	edata, err := edef.DecodeData(rawData)
 	if err != nil {
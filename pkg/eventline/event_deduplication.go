@@ -0,0 +1,67 @@
+package eventline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+// EventKey returns the key used to deduplicate or debounce an event, i.e. a
+// hash of the value referenced by the pointer in event data. The boolean is
+// false if the pointer does not reference any value.
+func EventKey(pointer ejson.Pointer, data interface{}) (string, bool) {
+	value := pointer.Find(data)
+	if value == nil {
+		return "", false
+	}
+
+	valueData, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+
+	hash := sha256.Sum256(valueData)
+	return hex.EncodeToString(hash[:]), true
+}
+
+// InsertEventDeduplicationKey records a key for a job and returns true if
+// the key was not already known, i.e. if the event it was computed from is
+// not a duplicate. Expired keys of the job are deleted first.
+func InsertEventDeduplicationKey(conn pg.Conn, jobId uuid.UUID, key string, now time.Time, window time.Duration) (bool, error) {
+	ctx := context.Background()
+
+	deleteQuery := `
+DELETE FROM event_deduplication_keys
+  WHERE job_id = $1 AND expiration_time <= $2;
+`
+	if err := pg.Exec(conn, deleteQuery, jobId, now); err != nil {
+		return false, err
+	}
+
+	insertQuery := `
+INSERT INTO event_deduplication_keys
+    (job_id, key, expiration_time)
+  VALUES
+    ($1, $2, $3)
+  ON CONFLICT (job_id, key) DO NOTHING
+  RETURNING key;
+`
+	var insertedKey string
+	err := conn.QueryRow(ctx, insertQuery, jobId, key,
+		now.Add(window)).Scan(&insertedKey)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,50 @@
+package eventline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.n16f.net/ejson"
+)
+
+func TestEventKey(t *testing.T) {
+	assert := assert.New(t)
+
+	data := map[string]interface{}{
+		"branch":   "main",
+		"revision": "f1a2b3",
+		"commits": []interface{}{
+			map[string]interface{}{"id": "f1a2b3"},
+		},
+	}
+
+	key1, found := EventKey(ejson.NewPointer("revision"), data)
+	assert.True(found)
+
+	key2, found := EventKey(ejson.NewPointer("commits", "0", "id"), data)
+	assert.True(found)
+	assert.Equal(key1, key2)
+
+	key3, found := EventKey(ejson.NewPointer("branch"), data)
+	assert.True(found)
+	assert.NotEqual(key1, key3)
+
+	_, found = EventKey(ejson.NewPointer("tag"), data)
+	assert.False(found)
+
+	// The empty pointer references the whole data
+	data2 := map[string]interface{}{
+		"commits": []interface{}{
+			map[string]interface{}{"id": "f1a2b3"},
+		},
+		"revision": "f1a2b3",
+		"branch":   "main",
+	}
+
+	key4, found := EventKey(ejson.Pointer{}, data)
+	assert.True(found)
+
+	key5, found := EventKey(ejson.Pointer{}, data2)
+	assert.True(found)
+	assert.Equal(key4, key5)
+}
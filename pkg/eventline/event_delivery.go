@@ -0,0 +1,232 @@
+package eventline
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+// An event delivery associates an event with a job it was dispatched to.
+// Events are stored once per source occurrence and delivered to every job
+// whose trigger matches them; each delivery is processed independently by
+// the event worker.
+type EventDelivery struct {
+	EventId   uuid.UUID
+	JobId     uuid.UUID
+	ProjectId uuid.UUID // project of the job
+	Processed bool
+}
+
+type EventDeliveries []*EventDelivery
+
+// DispatchEvent creates the event received for a set of subscriptions. A
+// single event is created for each project, and delivered to the job of each
+// subscription. If the trigger of a subscription shares events with other
+// projects, the event is also delivered to the jobs of these projects whose
+// trigger uses the project of the subscription as source.
+func DispatchEvent(conn pg.Conn, subs Subscriptions, cname, ename string, etime *time.Time, data EventData) (Events, error) {
+	var events Events
+	projectEvents := make(map[uuid.UUID]*Event)
+
+	for _, sub := range subs {
+		// Terminating subscriptions are not associated with a job anymore
+		if sub.ProjectId == nil || sub.JobId == nil {
+			continue
+		}
+
+		projectId := *sub.ProjectId
+
+		event, found := projectEvents[projectId]
+		if !found {
+			event = sub.NewEvent(cname, ename, etime, data)
+
+			if err := event.Insert(conn); err != nil {
+				return nil, fmt.Errorf("cannot insert event: %w", err)
+			}
+
+			projectEvents[projectId] = event
+			events = append(events, event)
+		}
+
+		if err := deliverEvent(conn, event, *sub.JobId, projectId); err != nil {
+			return nil, err
+		}
+
+		if err := shareEvent(conn, event, *sub.JobId); err != nil {
+			return nil, err
+		}
+	}
+
+	return events, nil
+}
+
+func shareEvent(conn pg.Conn, event *Event, jobId uuid.UUID) error {
+	var job Job
+	if err := job.Load(conn, jobId, NewGlobalScope()); err != nil {
+		return fmt.Errorf("cannot load job %q: %w", jobId, err)
+	}
+
+	trigger := job.Spec.Trigger
+	if trigger == nil || len(trigger.ShareWith) == 0 {
+		return nil
+	}
+
+	var project Project
+	if err := project.Load(conn, job.ProjectId); err != nil {
+		return fmt.Errorf("cannot load project %q: %w", job.ProjectId, err)
+	}
+
+	for _, name := range trigger.ShareWith {
+		var sharedProject Project
+		if err := sharedProject.LoadByName(conn, name); err != nil {
+			var unknownProjectNameErr *UnknownProjectNameError
+			if errors.As(err, &unknownProjectNameErr) {
+				continue
+			}
+
+			return fmt.Errorf("cannot load project %q: %w", name, err)
+		}
+
+		if sharedProject.Id == project.Id {
+			continue
+		}
+
+		scope := NewProjectScope(sharedProject.Id)
+
+		var jobs Jobs
+		err := jobs.LoadBySource(conn, project.Name, trigger.Event, scope)
+		if err != nil {
+			return fmt.Errorf("cannot load jobs: %w", err)
+		}
+
+		for _, sharedJob := range jobs {
+			err := deliverEvent(conn, event, sharedJob.Id, sharedProject.Id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func deliverEvent(conn pg.Conn, event *Event, jobId, projectId uuid.UUID) error {
+	delivery := EventDelivery{
+		EventId:   event.Id,
+		JobId:     jobId,
+		ProjectId: projectId,
+	}
+
+	inserted, err := delivery.Insert(conn)
+	if err != nil {
+		return fmt.Errorf("cannot insert event delivery: %w", err)
+	}
+
+	if inserted {
+		event.JobIds = append(event.JobIds, jobId)
+	}
+
+	return nil
+}
+
+func LoadEventDeliveryForProcessing(conn pg.Conn) (*EventDelivery, error) {
+	query := `
+SELECT event_id, job_id, project_id, processed
+  FROM event_deliveries
+  WHERE processed = FALSE
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED;
+`
+	var d EventDelivery
+	err := pg.QueryObject(conn, &d, query)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+func (ds *EventDeliveries) LoadByEvent(conn pg.Conn, eventId uuid.UUID, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT event_id, job_id, project_id, processed
+  FROM event_deliveries
+  WHERE %s AND event_id = $1
+  ORDER BY job_id
+`, scope.SQLCondition())
+
+	return pg.QueryObjects(conn, ds, query, eventId)
+}
+
+// LoadFailedByEvent loads the deliveries of an event to jobs whose last
+// execution for this event failed.
+func (ds *EventDeliveries) LoadFailedByEvent(conn pg.Conn, eventId uuid.UUID, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT event_id, job_id, project_id, processed
+  FROM event_deliveries
+  WHERE %s AND event_id = $1
+    AND EXISTS
+      (SELECT 1 FROM job_executions AS je
+         WHERE je.event_id = event_deliveries.event_id
+           AND je.job_id = event_deliveries.job_id
+           AND je.status = 'failed'
+           AND NOT EXISTS
+             (SELECT 1 FROM job_executions AS je2
+                WHERE je2.event_id = je.event_id
+                  AND je2.job_id = je.job_id
+                  AND je2.creation_time > je.creation_time))
+  ORDER BY job_id
+`, scope.SQLCondition())
+
+	return pg.QueryObjects(conn, ds, query, eventId)
+}
+
+// Insert creates the delivery and reports whether it was created or if the
+// event was already delivered to the job.
+func (d *EventDelivery) Insert(conn pg.Conn) (bool, error) {
+	// The same job can match an event both through its own subscription and
+	// through a shared subscription.
+	query := `
+INSERT INTO event_deliveries
+    (event_id, job_id, project_id, processed)
+  VALUES
+    ($1, $2, $3, $4)
+  ON CONFLICT (event_id, job_id) DO NOTHING;
+`
+	n, err := pg.Exec2(conn, query,
+		d.EventId, d.JobId, d.ProjectId, d.Processed)
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (d *EventDelivery) Update(conn pg.Conn) error {
+	query := `
+UPDATE event_deliveries SET
+    processed = $3
+  WHERE event_id = $1 AND job_id = $2
+`
+	return pg.Exec(conn, query,
+		d.EventId, d.JobId, d.Processed)
+}
+
+func (d *EventDelivery) FromRow(row pgx.Row) error {
+	return row.Scan(&d.EventId, &d.JobId, &d.ProjectId, &d.Processed)
+}
+
+func (ds *EventDeliveries) AddFromRow(row pgx.Row) error {
+	var d EventDelivery
+	if err := d.FromRow(row); err != nil {
+		return err
+	}
+
+	*ds = append(*ds, &d)
+	return nil
+}
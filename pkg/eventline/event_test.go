@@ -0,0 +1,58 @@
+package eventline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.n16f.net/ejson"
+	"go.n16f.net/uuid"
+)
+
+func TestEventReplaySelectionValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	var selection EventReplaySelection
+
+	v := ejson.NewValidator()
+	selection.ValidateJSON(v)
+	assert.Error(v.Error())
+
+	selection.Failed = true
+
+	v = ejson.NewValidator()
+	selection.ValidateJSON(v)
+	assert.NoError(v.Error())
+}
+
+func TestEventPageOptionsSQLCondition(t *testing.T) {
+	assert := assert.New(t)
+
+	var options EventPageOptions
+	assert.Equal("TRUE", options.SQLCondition())
+
+	id := uuid.MustParse("0192a3b4-0000-7000-8000-000000000001")
+
+	processed := false
+
+	options = EventPageOptions{
+		EventIds:  []uuid.UUID{id},
+		Name:      "push",
+		Processed: &processed,
+	}
+
+	assert.Equal("TRUE AND id IN ('"+id.String()+"') AND name = 'push' "+
+		"AND EXISTS\n  (SELECT 1 FROM event_deliveries\n"+
+		"     WHERE event_deliveries.event_id = events.id\n"+
+		"       AND NOT event_deliveries.processed)", options.SQLCondition())
+
+	processed = true
+
+	assert.Contains(options.SQLCondition(), "AND NOT EXISTS")
+
+	// Failed events exclude events which were already replayed
+	options = EventPageOptions{Failed: true}
+
+	cond := options.SQLCondition()
+	assert.Contains(cond, "je.status = 'failed'")
+	assert.Contains(cond, "replays.original_event_id = events.id")
+}
@@ -30,8 +30,14 @@ func (ctx *ExecutionContext) Load(conn pg.Conn, je *JobExecution) error {
 
 	ctx.Parameters = je.Parameters
 
+	// Identities referenced by identity parameters are available in the
+	// same way as identities used in the job specification.
+	identityNames := je.JobSpec.IdentityNames()
+	identityNames = append(identityNames,
+		je.JobSpec.Parameters.IdentityNames(je.Parameters)...)
+
 	var identities Identities
-	err := identities.LoadByNames(conn, je.JobSpec.IdentityNames(), scope)
+	err := identities.LoadByNames(conn, identityNames, scope)
 	if err != nil {
 		return fmt.Errorf("cannot load identities: %w", err)
 	}
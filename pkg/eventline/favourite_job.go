@@ -15,7 +15,8 @@ type FavouriteJob struct {
 
 func LoadFavouriteJobs(conn pg.Conn, scope Scope) (Jobs, error) {
 	query := fmt.Sprintf(`
-SELECT j.id, j.project_id, j.creation_time, j.update_time, j.disabled, j.spec
+SELECT j.id, j.project_id, j.creation_time, j.update_time, j.disabled, j.spec,
+       j.git_commit
   FROM jobs AS j
   JOIN favourite_jobs AS fj ON fj.job_id = j.id
   WHERE %s
@@ -2,9 +2,13 @@ package eventline
 
 import (
 	"encoding/json"
+	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
 
 	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
 )
 
 type Filter struct {
@@ -90,3 +94,291 @@ func (fs Filters) Match(obj interface{}) bool {
 
 	return true
 }
+
+// Parse decodes the compact representation of a filter used in query
+// parameters and on the command line: "<path>=<value>", "<path>!=<value>",
+// "<path>=~<regexp>" or "<path>!~<regexp>". Values which are not valid JSON
+// values are used as strings.
+func (f *Filter) Parse(s string) error {
+	idx, op := -1, ""
+
+	for i := 0; i < len(s) && idx < 0; i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "=~"):
+			idx, op = i, "=~"
+		case s[i] == '=':
+			idx, op = i, "="
+		case strings.HasPrefix(s[i:], "!="):
+			idx, op = i, "!="
+		case strings.HasPrefix(s[i:], "!~"):
+			idx, op = i, "!~"
+		}
+	}
+
+	if idx < 0 {
+		return fmt.Errorf("missing operator")
+	}
+
+	var filter Filter
+
+	if err := filter.Path.Parse(s[:idx]); err != nil {
+		return fmt.Errorf("invalid path %q: %w", s[:idx], err)
+	}
+
+	value := s[idx+len(op):]
+
+	switch op {
+	case "=", "!=":
+		var jsonValue interface{}
+		if err := json.Unmarshal([]byte(value), &jsonValue); err != nil ||
+			jsonValue == nil {
+			jsonValue = value
+		}
+
+		if op == "=" {
+			filter.IsEqualTo = jsonValue
+		} else {
+			filter.IsNotEqualTo = jsonValue
+		}
+
+	case "=~", "!~":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return fmt.Errorf("invalid regexp: %w", err)
+		}
+
+		if op == "=~" {
+			filter.Matches = value
+			filter.MatchesRE = re
+		} else {
+			filter.DoesNotMatch = value
+			filter.DoesNotMatchRE = re
+		}
+	}
+
+	*f = filter
+	return nil
+}
+
+// CheckSQLRegexps verifies that the regular expressions of the filter can be
+// evaluated by PostgreSQL with the same meaning as in Go. See
+// CheckSQLRegexp.
+func (f *Filter) CheckSQLRegexps() error {
+	if f.MatchesRE != nil {
+		if err := CheckSQLRegexp(f.Matches); err != nil {
+			return fmt.Errorf("invalid regexp %q: %w", f.Matches, err)
+		}
+	}
+
+	if f.DoesNotMatchRE != nil {
+		if err := CheckSQLRegexp(f.DoesNotMatch); err != nil {
+			return fmt.Errorf("invalid regexp %q: %w", f.DoesNotMatch, err)
+		}
+	}
+
+	return nil
+}
+
+// CheckSQLRegexp verifies that a regular expression only uses the subset of
+// the syntax shared by Go and PostgreSQL regular expressions: literal
+// characters, ".", bracket expressions including character classes such as
+// "[[:alpha:]]", the "^" and "$" anchors, repetitions with bounds up to 255,
+// alternations, groups and non-capturing groups, and escaped punctuation
+// characters. Escape sequences such as "\d" or "\b" and flags are rejected
+// since they are either missing or different in one of the syntaxes.
+func CheckSQLRegexp(s string) error {
+	_, err := translateSQLRegexp(s)
+	return err
+}
+
+// translateSQLRegexp returns the PostgreSQL equivalent of a Go regular
+// expression using the syntax accepted by CheckSQLRegexp. The only
+// difference is that "." does not match newline characters in Go.
+func translateSQLRegexp(s string) (string, error) {
+	var buf strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch c {
+		case '\\':
+			if i+1 >= len(s) {
+				return "", fmt.Errorf("trailing backslash")
+			}
+
+			if c2 := s[i+1]; isSQLRegexpAlnum(c2) || c2 >= 0x80 {
+				return "", fmt.Errorf("unsupported escape sequence \\%c", c2)
+			}
+
+			buf.WriteString(s[i : i+2])
+			i++
+
+		case '.':
+			buf.WriteString(`[^\n]`)
+
+		case '[':
+			end, err := sqlRegexpBracketEnd(s, i)
+			if err != nil {
+				return "", err
+			}
+
+			buf.WriteString(s[i : end+1])
+			i = end
+
+		case '(':
+			if strings.HasPrefix(s[i:], "(?") &&
+				!strings.HasPrefix(s[i:], "(?:") {
+				return "", fmt.Errorf("unsupported group or flag")
+			}
+
+			buf.WriteByte(c)
+
+		case '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unescaped '{'")
+			}
+
+			bound := s[i+1 : i+end]
+
+			min, max, hasMax := strings.Cut(bound, ",")
+			if !isSQLRegexpCount(min) ||
+				(hasMax && max != "" && !isSQLRegexpCount(max)) {
+				return "", fmt.Errorf("invalid repetition bound {%s}", bound)
+			}
+
+			buf.WriteString(s[i : i+end+1])
+			i += end
+
+		case '}':
+			return "", fmt.Errorf("unescaped '}'")
+
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	return buf.String(), nil
+}
+
+// sqlRegexpBracketEnd returns the position of the end of the bracket
+// expression starting at position start.
+func sqlRegexpBracketEnd(s string, start int) (int, error) {
+	i := start + 1
+
+	if i < len(s) && s[i] == '^' {
+		i++
+	}
+
+	// A closing bracket at the beginning of the expression is a literal
+	if i < len(s) && s[i] == ']' {
+		i++
+	}
+
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) && (isSQLRegexpAlnum(s[i+1]) || s[i+1] >= 0x80) {
+				return 0, fmt.Errorf("unsupported escape sequence \\%c",
+					s[i+1])
+			}
+
+			i++
+
+		case '[':
+			if i+1 < len(s) {
+				switch s[i+1] {
+				case ':':
+					end := strings.Index(s[i:], ":]")
+					if end < 0 {
+						return 0, fmt.Errorf("invalid character class")
+					}
+
+					i += end + 1
+
+				case '.', '=':
+					return 0, fmt.Errorf("unsupported bracket expression "+
+						"element \"[%c\"", s[i+1])
+				}
+			}
+
+		case ']':
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("missing ']'")
+}
+
+func isSQLRegexpAlnum(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9')
+}
+
+// isSQLRegexpCount reports whether a string is a repetition count supported
+// by both Go and PostgreSQL.
+func isSQLRegexpCount(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0 && n <= 255 && s[0] != '+'
+}
+
+// SQLCondition returns a SQL condition matching rows whose JSONB column
+// satisfies the filter. Regular expressions are evaluated by PostgreSQL with
+// the ~ operator; they must have been checked with CheckSQLRegexps.
+func (f *Filter) SQLCondition(column string) string {
+	tokens := make([]string, len(f.Path))
+	for i, token := range f.Path {
+		tokens[i] = pg.QuoteString(token)
+	}
+
+	value := fmt.Sprintf("(%s #> ARRAY[%s]::TEXT[])",
+		pg.QuoteIdentifier(column), strings.Join(tokens, ", "))
+
+	conds := []string{"jsonb_typeof(" + value + ") <> 'null'"}
+
+	quoteJSON := func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		return pg.QuoteString(string(data)) + "::JSONB"
+	}
+
+	if v := f.IsEqualTo; v != nil {
+		conds = append(conds, value+" = "+quoteJSON(v))
+	}
+
+	if v := f.IsNotEqualTo; v != nil {
+		conds = append(conds, value+" <> "+quoteJSON(v))
+	}
+
+	stringCond := "jsonb_typeof(" + value + ") = 'string'"
+	stringValue := value + " #>> '{}'"
+
+	quoteRegexp := func(s string) string {
+		re, _ := translateSQLRegexp(s)
+		return pg.QuoteString(re)
+	}
+
+	if f.Matches != "" {
+		conds = append(conds, stringCond,
+			stringValue+" ~ "+quoteRegexp(f.Matches))
+	}
+
+	if f.DoesNotMatch != "" {
+		conds = append(conds, stringCond,
+			stringValue+" !~ "+quoteRegexp(f.DoesNotMatch))
+	}
+
+	return "(" + strings.Join(conds, " AND ") + ")"
+}
+
+func (fs Filters) SQLCondition(column string) string {
+	if len(fs) == 0 {
+		return "TRUE"
+	}
+
+	conds := make([]string, len(fs))
+	for i, f := range fs {
+		conds[i] = f.SQLCondition(column)
+	}
+
+	return strings.Join(conds, " AND ")
+}
@@ -36,3 +36,105 @@ func TestFilterSerialization(t *testing.T) {
 
 	require.Equal(*f1, f2)
 }
+
+func TestFilterParse(t *testing.T) {
+	require := require.New(t)
+
+	tests := []struct {
+		s      string
+		filter Filter
+	}{
+		{"/branch=main",
+			Filter{Path: ejson.NewPointer("branch"), IsEqualTo: "main"}},
+		{"/a/b=42",
+			Filter{Path: ejson.NewPointer("a", "b"), IsEqualTo: 42.0}},
+		{`/a="42"`,
+			Filter{Path: ejson.NewPointer("a"), IsEqualTo: "42"}},
+		{"/a=null",
+			Filter{Path: ejson.NewPointer("a"), IsEqualTo: "null"}},
+		{"/a=b=c",
+			Filter{Path: ejson.NewPointer("a"), IsEqualTo: "b=c"}},
+		{"/a!=true",
+			Filter{Path: ejson.NewPointer("a"), IsNotEqualTo: true}},
+		{"/a!b=1",
+			Filter{Path: ejson.NewPointer("a!b"), IsEqualTo: 1.0}},
+		{"/ref=~^refs/tags/",
+			Filter{Path: ejson.NewPointer("ref"), Matches: "^refs/tags/",
+				MatchesRE: regexp.MustCompile("^refs/tags/")}},
+		{"/ref!~^refs/tags/",
+			Filter{Path: ejson.NewPointer("ref"), DoesNotMatch: "^refs/tags/",
+				DoesNotMatchRE: regexp.MustCompile("^refs/tags/")}},
+	}
+
+	for _, test := range tests {
+		var filter Filter
+		err := filter.Parse(test.s)
+		require.NoError(err, test.s)
+		require.Equal(test.filter, filter, test.s)
+	}
+
+	invalidFilters := []string{"", "/a", "a=b", "/a=~("}
+
+	for _, s := range invalidFilters {
+		var filter Filter
+		require.Error(filter.Parse(s), s)
+	}
+}
+
+func TestFilterSQLCondition(t *testing.T) {
+	require := require.New(t)
+
+	var f1 Filter
+	require.NoError(f1.Parse("/a/b'c=\"x\""))
+	require.Equal(`(jsonb_typeof((data #> ARRAY['a', 'b''c']::TEXT[])) <> 'null' AND (data #> ARRAY['a', 'b''c']::TEXT[]) = '"x"'::JSONB)`,
+		f1.SQLCondition("data"))
+
+	var f2 Filter
+	require.NoError(f2.Parse("/a!~^x"))
+	require.Equal(`(jsonb_typeof((data #> ARRAY['a']::TEXT[])) <> 'null' AND jsonb_typeof((data #> ARRAY['a']::TEXT[])) = 'string' AND (data #> ARRAY['a']::TEXT[]) #>> '{}' !~ '^x')`,
+		f2.SQLCondition("data"))
+
+	var f3 Filter
+	require.NoError(f3.Parse("/a=~^x.y[.]$"))
+	require.Equal(`(jsonb_typeof((data #> ARRAY['a']::TEXT[])) <> 'null' AND jsonb_typeof((data #> ARRAY['a']::TEXT[])) = 'string' AND (data #> ARRAY['a']::TEXT[]) #>> '{}' ~ '^x[^\n]y[.]$')`,
+		f3.SQLCondition("data"))
+
+	require.Equal("TRUE", Filters{}.SQLCondition("data"))
+}
+
+func TestCheckSQLRegexp(t *testing.T) {
+	require := require.New(t)
+
+	validRegexps := []string{
+		"",
+		"^refs/tags/v[0-9]+\\.[0-9]+$",
+		"^(main|release-[[:digit:]]{1,3})$",
+		"(?:foo)*bar?",
+		"[]a]",
+		"[^\\]-]",
+		"a{2,}",
+		"\\{\\}",
+	}
+
+	for _, s := range validRegexps {
+		require.NoError(CheckSQLRegexp(s), s)
+	}
+
+	invalidRegexps := []string{
+		"\\d+",
+		"\\bfoo\\b",
+		"\\Afoo\\z",
+		"(?i)foo",
+		"(?P<name>foo)",
+		"[\\d]",
+		"[[.a.]]",
+		"a{1000}",
+		"a{x}",
+		"a}",
+		"\\",
+	}
+
+	for _, s := range invalidRegexps {
+		require.Error(CheckSQLRegexp(s), s)
+	}
+}
@@ -0,0 +1,196 @@
+package eventline
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+const MinGitRepositorySyncInterval = 60 // seconds
+
+type UnknownGitRepositoryError struct {
+	ProjectId uuid.UUID
+}
+
+func (err UnknownGitRepositoryError) Error() string {
+	return fmt.Sprintf("project %q is not bound to a git repository",
+		err.ProjectId)
+}
+
+type NewGitRepository struct {
+	URI          string `json:"uri"`
+	Branch       string `json:"branch"`
+	Directory    string `json:"directory,omitempty"`
+	SyncInterval int    `json:"sync_interval"` // seconds
+}
+
+// GitRepository binds a project to a directory in a branch of a Git
+// repository. Jobs defined in this directory are periodically deployed in the
+// project.
+type GitRepository struct {
+	ProjectId    uuid.UUID  `json:"project_id"`
+	URI          string     `json:"uri"`
+	Branch       string     `json:"branch"`
+	Directory    string     `json:"directory,omitempty"`
+	SyncInterval int        `json:"sync_interval"` // seconds
+	NextSyncTime time.Time  `json:"next_sync_time"`
+	LastSyncTime *time.Time `json:"last_sync_time,omitempty"`
+	LastCommit   string     `json:"last_commit,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+}
+
+func (nr *NewGitRepository) ValidateJSON(v *ejson.Validator) {
+	v.CheckStringNotEmpty("uri", nr.URI)
+
+	// Branch names are passed to git on the command line
+	if v.CheckStringNotEmpty("branch", nr.Branch) {
+		v.Check("branch", !strings.HasPrefix(nr.Branch, "-"),
+			"invalid_branch", "invalid branch name")
+	}
+
+	if nr.Directory != "" {
+		dirPath := path.Clean(nr.Directory)
+		valid := !path.IsAbs(dirPath) && dirPath != ".." &&
+			!strings.HasPrefix(dirPath, "../")
+
+		v.Check("directory", valid, "invalid_directory",
+			"directory must be a relative path inside the repository")
+	}
+
+	v.CheckIntMin("sync_interval", nr.SyncInterval,
+		MinGitRepositorySyncInterval)
+}
+
+// GitHubRepository returns the organization and name of the repository if it
+// is hosted on GitHub.
+func (r *GitRepository) GitHubRepository() (string, string, bool) {
+	uri := strings.TrimSuffix(r.URI, ".git")
+
+	idx := strings.Index(uri, "github.com")
+	if idx == -1 {
+		return "", "", false
+	}
+
+	rest := uri[idx+len("github.com"):]
+	if rest == "" || (rest[0] != '/' && rest[0] != ':') {
+		return "", "", false
+	}
+
+	parts := strings.Split(rest[1:], "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+func (r *GitRepository) Load(conn pg.Conn, projectId uuid.UUID) error {
+	query := `
+SELECT project_id, uri, branch, directory, sync_interval, next_sync_time,
+       last_sync_time, last_commit, last_error
+  FROM git_repositories
+  WHERE project_id = $1
+`
+	err := pg.QueryObject(conn, r, query, projectId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &UnknownGitRepositoryError{ProjectId: projectId}
+	}
+
+	return err
+}
+
+func (r *GitRepository) LoadForUpdate(conn pg.Conn, projectId uuid.UUID) error {
+	query := `
+SELECT project_id, uri, branch, directory, sync_interval, next_sync_time,
+       last_sync_time, last_commit, last_error
+  FROM git_repositories
+  WHERE project_id = $1
+  FOR UPDATE
+`
+	err := pg.QueryObject(conn, r, query, projectId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &UnknownGitRepositoryError{ProjectId: projectId}
+	}
+
+	return err
+}
+
+func LoadGitRepositoryForSync(conn pg.Conn) (*GitRepository, error) {
+	now := time.Now().UTC()
+
+	query := `
+SELECT project_id, uri, branch, directory, sync_interval, next_sync_time,
+       last_sync_time, last_commit, last_error
+  FROM git_repositories
+  WHERE next_sync_time <= $1
+  ORDER BY next_sync_time
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+`
+	var r GitRepository
+
+	err := pg.QueryObject(conn, &r, query, now)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// ScheduleGitRepositorySync requests an immediate synchronization of the
+// repository bound to a project, if there is one.
+func ScheduleGitRepositorySync(conn pg.Conn, projectId uuid.UUID) error {
+	now := time.Now().UTC()
+
+	query := `
+UPDATE git_repositories SET
+    next_sync_time = $2
+  WHERE project_id = $1
+`
+	return pg.Exec(conn, query, projectId, now)
+}
+
+func (r *GitRepository) Upsert(conn pg.Conn) error {
+	query := `
+INSERT INTO git_repositories
+    (project_id, uri, branch, directory, sync_interval, next_sync_time,
+     last_sync_time, last_commit, last_error)
+  VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+  ON CONFLICT (project_id) DO UPDATE SET
+    uri = EXCLUDED.uri,
+    branch = EXCLUDED.branch,
+    directory = EXCLUDED.directory,
+    sync_interval = EXCLUDED.sync_interval,
+    next_sync_time = EXCLUDED.next_sync_time,
+    last_sync_time = EXCLUDED.last_sync_time,
+    last_commit = EXCLUDED.last_commit,
+    last_error = EXCLUDED.last_error
+`
+	return pg.Exec(conn, query,
+		r.ProjectId, r.URI, r.Branch, r.Directory, r.SyncInterval,
+		r.NextSyncTime, r.LastSyncTime, r.LastCommit, r.LastError)
+}
+
+func (r *GitRepository) Delete(conn pg.Conn) error {
+	query := `
+DELETE FROM git_repositories
+  WHERE project_id = $1
+`
+	return pg.Exec(conn, query, r.ProjectId)
+}
+
+func (r *GitRepository) FromRow(row pgx.Row) error {
+	return row.Scan(&r.ProjectId, &r.URI, &r.Branch, &r.Directory,
+		&r.SyncInterval, &r.NextSyncTime, &r.LastSyncTime, &r.LastCommit,
+		&r.LastError)
+}
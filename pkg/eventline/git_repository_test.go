@@ -0,0 +1,38 @@
+package eventline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitRepositoryGitHubRepository(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		uri          string
+		organization string
+		repository   string
+	}{
+		{"https://github.com/exograd/eventline", "exograd", "eventline"},
+		{"https://github.com/exograd/eventline.git", "exograd", "eventline"},
+		{"git@github.com:exograd/eventline.git", "exograd", "eventline"},
+		{"ssh://git@github.com/exograd/eventline.git", "exograd", "eventline"},
+		{"https://gitlab.com/exograd/eventline.git", "", ""},
+		{"https://github.com/exograd", "", ""},
+		{"https://github.com/exograd/eventline/tree/main", "", ""},
+		{"https://github.company.com/exograd/eventline", "", ""},
+	}
+
+	for _, test := range tests {
+		repo := GitRepository{URI: test.uri}
+
+		organization, repository, ok := repo.GitHubRepository()
+		if test.organization == "" {
+			assert.False(ok, test.uri)
+		} else if assert.True(ok, test.uri) {
+			assert.Equal(test.organization, organization, test.uri)
+			assert.Equal(test.repository, repository, test.uri)
+		}
+	}
+}
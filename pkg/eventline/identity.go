@@ -47,6 +47,12 @@ const (
 	IdentityStatusError   IdentityStatus = "error"
 )
 
+var IdentityStatusValues = []IdentityStatus{
+	IdentityStatusPending,
+	IdentityStatusReady,
+	IdentityStatusError,
+}
+
 type NewIdentity struct {
 	Name      string          `json:"name"`
 	Connector string          `json:"connector"`
@@ -387,6 +393,40 @@ SELECT id
 	return &id, nil
 }
 
+func LoadIdentityNames(conn pg.Conn, scope Scope) ([]string, error) {
+	ctx := context.Background()
+
+	query := fmt.Sprintf(`
+SELECT name
+  FROM identities
+  WHERE %s
+  ORDER BY name
+`, scope.SQLCondition())
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
 func LoadIdentityForRefresh(conn pg.Conn) (*Identity, error) {
 	now := time.Now().UTC()
 
@@ -0,0 +1,527 @@
+package eventline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"go.n16f.net/ejson"
+)
+
+// Runner parameters, step commands and arguments and environment variables
+// can contain Go templates which are evaluated when the job execution starts.
+// Templates are evaluated with the object returned by InterpolationData.
+//
+// Templates are delimited by "${{" and "}}" so that strings containing
+// "{{", e.g. Go templates passed to commands, are left untouched.
+
+const (
+	InterpolationLeftDelim  = "${{"
+	InterpolationRightDelim = "}}"
+)
+
+func InterpolationData(ectx *ExecutionContext) map[string]interface{} {
+	parameters := make(map[string]interface{})
+	for name, value := range ectx.Parameters {
+		parameters[name] = value
+	}
+
+	var event interface{} = map[string]interface{}{}
+	if ectx.Event != nil && ectx.Event.DataValue != nil {
+		event = ectx.Event.DataValue
+	}
+
+	return map[string]interface{}{
+		"parameters": parameters,
+		"event":      event,
+	}
+}
+
+func parseInterpolationTemplate(s string) (*template.Template, error) {
+	tpl := template.New("").Option("missingkey=error")
+	tpl.Delims(InterpolationLeftDelim, InterpolationRightDelim)
+	return tpl.Parse(s)
+}
+
+func InterpolateString(s string, data interface{}) (string, error) {
+	if !strings.Contains(s, InterpolationLeftDelim) {
+		return s, nil
+	}
+
+	tpl, err := parseInterpolationTemplate(s)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func checkInterpolationString(v *ejson.Validator, token interface{}, s string) {
+	if !strings.Contains(s, InterpolationLeftDelim) {
+		return
+	}
+
+	if _, err := parseInterpolationTemplate(s); err != nil {
+		v.AddError(token, "invalid_template", "invalid template: %v", err)
+	}
+}
+
+// CheckInterpolation validates the syntax of all templates in the job
+// specification. Templates can only be evaluated once the execution context
+// is available.
+func (spec *JobSpec) CheckInterpolation(v *ejson.Validator) {
+	spec.walkInterpolationStrings(v, func(token interface{}, s string) {
+		checkInterpolationString(v, token, s)
+	})
+}
+
+// CheckInterpolationReferences validates that templates only refer to
+// parameters declared in the job specification or matrix axes, and to fields
+// of the event triggering the job. Templates which cannot be parsed are
+// ignored since they are reported by CheckInterpolation.
+func (spec *JobSpec) CheckInterpolationReferences(v *ejson.Validator) {
+	spec.walkInterpolationStrings(v, spec.interpolationReferenceChecker(v))
+}
+
+// CheckStepsInterpolation validates templates in steps which are not part of
+// the job specification but will be executed with it, e.g. the steps of a
+// step template.
+func (spec *JobSpec) CheckStepsInterpolation(v *ejson.Validator, steps Steps) {
+	checkReferences := spec.interpolationReferenceChecker(v)
+
+	walkStepsInterpolationStrings(v, steps, func(token interface{}, s string) {
+		checkInterpolationString(v, token, s)
+		checkReferences(token, s)
+	})
+}
+
+func (spec *JobSpec) interpolationReferenceChecker(v *ejson.Validator) func(interface{}, string) {
+	names := make(map[string]struct{})
+	for _, p := range spec.Parameters {
+		names[p.Name] = struct{}{}
+	}
+	if spec.Matrix != nil {
+		for _, name := range spec.Matrix.AxisNames() {
+			names[name] = struct{}{}
+		}
+	}
+
+	// Event fields are only checked if the event is known; unknown events
+	// are reported when validating the trigger.
+	var eventFields map[string]struct{}
+	checkEventFields := true
+
+	if spec.Trigger != nil {
+		if EventDefExists(spec.Trigger.Event) {
+			eventFields = eventDataFields(GetEventDef(spec.Trigger.Event).Data)
+		} else {
+			checkEventFields = false
+		}
+	}
+
+	return func(token interface{}, s string) {
+		refs, err := interpolationReferences(s)
+		if err != nil {
+			return
+		}
+
+		for _, ref := range refs {
+			if ref[0] != "parameters" && ref[0] != "event" {
+				v.AddError(token, "unknown_template_field",
+					"unknown field %q", ref[0])
+				continue
+			}
+
+			if len(ref) < 2 {
+				continue
+			}
+
+			switch ref[0] {
+			case "parameters":
+				if _, found := names[ref[1]]; !found {
+					v.AddError(token, "unknown_template_parameter",
+						"unknown parameter %q", ref[1])
+				}
+
+			case "event":
+				if !checkEventFields {
+					continue
+				}
+
+				if spec.Trigger == nil {
+					v.AddError(token, "unknown_template_event_field",
+						"job without trigger cannot refer to event "+
+							"field %q", ref[1])
+				} else if _, found := eventFields[ref[1]]; !found {
+					v.AddError(token, "unknown_template_event_field",
+						"unknown event field %q", ref[1])
+				}
+			}
+		}
+	}
+}
+
+func (spec *JobSpec) walkInterpolationStrings(v *ejson.Validator, fn func(interface{}, string)) {
+	if spec.Runner != nil && spec.Runner.Parameters != nil {
+		if value, err := jsonValue(spec.Runner.Parameters); err == nil {
+			v.WithChild("runner", func() {
+				v.WithChild("parameters", func() {
+					walkJSONStrings(value, ejson.Pointer{},
+						func(p ejson.Pointer, s string) (string, error) {
+							fn(p, s)
+							return s, nil
+						})
+				})
+			})
+		}
+	}
+
+	v.WithChild("environment", func() {
+		for name, value := range spec.Environment {
+			fn(name, value)
+		}
+	})
+
+	v.WithChild("steps", func() {
+		walkStepsInterpolationStrings(v, spec.Steps, fn)
+	})
+}
+
+func walkStepsInterpolationStrings(v *ejson.Validator, steps Steps, fn func(interface{}, string)) {
+	walkStrings := func(token interface{}, ss []string) {
+		v.WithChild(token, func() {
+			for i, s := range ss {
+				fn(i, s)
+			}
+		})
+	}
+
+	for i, step := range steps {
+		v.WithChild(i, func() {
+			if step.Command != nil {
+				v.WithChild("command", func() {
+					fn("name", step.Command.Name)
+					walkStrings("arguments", step.Command.Arguments)
+				})
+			}
+
+			if step.Script != nil {
+				v.WithChild("script", func() {
+					walkStrings("arguments", step.Script.Arguments)
+				})
+			}
+		})
+	}
+}
+
+// interpolationReferences returns the list of fields of the interpolation
+// data referenced in a template, e.g. ["parameters", "version"] for
+// "${{ .parameters.version }}". Fields accessed in range and with blocks where
+// the dot is not the interpolation data are ignored.
+func interpolationReferences(s string) ([][]string, error) {
+	if !strings.Contains(s, InterpolationLeftDelim) {
+		return nil, nil
+	}
+
+	tpl, err := parseInterpolationTemplate(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs [][]string
+
+	var walk func(parse.Node, bool)
+	walk = func(node parse.Node, root bool) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n != nil {
+				for _, child := range n.Nodes {
+					walk(child, root)
+				}
+			}
+
+		case *parse.ActionNode:
+			walk(n.Pipe, root)
+
+		case *parse.IfNode:
+			walk(n.Pipe, root)
+			walk(n.List, root)
+			walk(n.ElseList, root)
+
+		case *parse.RangeNode:
+			walk(n.Pipe, root)
+			walk(n.List, false)
+			walk(n.ElseList, root)
+
+		case *parse.WithNode:
+			walk(n.Pipe, root)
+			walk(n.List, false)
+			walk(n.ElseList, root)
+
+		case *parse.TemplateNode:
+			walk(n.Pipe, root)
+
+		case *parse.PipeNode:
+			if n != nil {
+				for _, cmd := range n.Cmds {
+					walk(cmd, root)
+				}
+			}
+
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg, root)
+			}
+
+		case *parse.FieldNode:
+			if root {
+				refs = append(refs, n.Ident)
+			}
+
+		case *parse.VariableNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "$" {
+				refs = append(refs, n.Ident[1:])
+			}
+
+		case *parse.ChainNode:
+			walk(n.Node, root)
+		}
+	}
+
+	walk(tpl.Tree.Root, true)
+
+	return refs, nil
+}
+
+// eventDataFields returns the set of top-level fields of event data as they
+// appear in the interpolation data.
+func eventDataFields(data EventData) map[string]struct{} {
+	fields := make(map[string]struct{})
+
+	t := reflect.TypeOf(data)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+
+			if tagName, _, _ := strings.Cut(tag, ","); tagName != "" {
+				name = tagName
+			}
+		}
+
+		fields[name] = struct{}{}
+	}
+
+	return fields
+}
+
+// Interpolate returns a copy of the job specification where all templates
+// have been evaluated.
+func (spec *JobSpec) Interpolate(ectx *ExecutionContext) (*JobSpec, error) {
+	data := InterpolationData(ectx)
+
+	interpolate := func(p ejson.Pointer, s string) (string, error) {
+		s2, err := InterpolateString(s, data)
+		if err != nil {
+			return "", fmt.Errorf("cannot interpolate %v: %w", p, err)
+		}
+
+		return s2, nil
+	}
+
+	interpolateStrings := func(p ejson.Pointer, ss []string) ([]string, error) {
+		if ss == nil {
+			return nil, nil
+		}
+
+		ss2 := make([]string, len(ss))
+		for i, s := range ss {
+			s2, err := interpolate(p.Child(i), s)
+			if err != nil {
+				return nil, err
+			}
+
+			ss2[i] = s2
+		}
+
+		return ss2, nil
+	}
+
+	spec2 := *spec
+
+	// Runner
+	if spec.Runner != nil && spec.Runner.Parameters != nil {
+		runner, err := spec.Runner.interpolate(interpolate)
+		if err != nil {
+			return nil, err
+		}
+
+		spec2.Runner = runner
+	}
+
+	// Environment
+	if spec.Environment != nil {
+		spec2.Environment = make(map[string]string)
+
+		for name, value := range spec.Environment {
+			p := ejson.NewPointer("environment", name)
+
+			value2, err := interpolate(p, value)
+			if err != nil {
+				return nil, err
+			}
+
+			spec2.Environment[name] = value2
+		}
+	}
+
+	// Steps
+	spec2.Steps = make(Steps, len(spec.Steps))
+
+	for i, step := range spec.Steps {
+		step2 := *step
+
+		p := ejson.NewPointer("steps", i)
+
+		var err error
+
+		if step.Command != nil {
+			command := *step.Command
+			cp := p.Child("command")
+
+			command.Name, err = interpolate(cp.Child("name"), command.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			command.Arguments, err = interpolateStrings(cp.Child("arguments"),
+				command.Arguments)
+			if err != nil {
+				return nil, err
+			}
+
+			step2.Command = &command
+		}
+
+		if step.Script != nil {
+			script := *step.Script
+			sp := p.Child("script")
+
+			script.Arguments, err = interpolateStrings(sp.Child("arguments"),
+				script.Arguments)
+			if err != nil {
+				return nil, err
+			}
+
+			step2.Script = &script
+		}
+
+		spec2.Steps[i] = &step2
+	}
+
+	return &spec2, nil
+}
+
+func (r *JobRunner) interpolate(fn func(ejson.Pointer, string) (string, error)) (*JobRunner, error) {
+	value, err := jsonValue(r.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode runner parameters: %w", err)
+	}
+
+	p := ejson.NewPointer("runner", "parameters")
+
+	value, err = walkJSONStrings(value, p, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode runner parameters: %w", err)
+	}
+
+	r2 := *r
+	r2.Parameters = nil
+	r2.RawParameters = params
+
+	data, err := json.Marshal(&r2)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode runner: %w", err)
+	}
+
+	// Interpolated values must still be valid runner parameters
+	var r3 JobRunner
+	if err := ejson.Unmarshal(data, &r3); err != nil {
+		return nil, fmt.Errorf("invalid interpolated runner parameters: %w",
+			err)
+	}
+
+	return &r3, nil
+}
+
+func jsonValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func walkJSONStrings(value interface{}, p ejson.Pointer, fn func(ejson.Pointer, string) (string, error)) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return fn(p, v)
+
+	case []interface{}:
+		for i, child := range v {
+			child2, err := walkJSONStrings(child, p.Child(i), fn)
+			if err != nil {
+				return nil, err
+			}
+
+			v[i] = child2
+		}
+
+	case map[string]interface{}:
+		for name, child := range v {
+			child2, err := walkJSONStrings(child, p.Child(name), fn)
+			if err != nil {
+				return nil, err
+			}
+
+			v[name] = child2
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,182 @@
+package eventline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.n16f.net/ejson"
+)
+
+func TestJobSpecInterpolate(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ectx := &ExecutionContext{
+		Parameters: map[string]interface{}{"version": "20"},
+		Event: &Event{
+			DataValue: map[string]interface{}{"branch": "main"},
+		},
+	}
+
+	spec := &JobSpec{
+		Environment: map[string]string{
+			"BRANCH": "${{ .event.branch }}",
+			"FOO":    "bar",
+		},
+		Steps: Steps{
+			{
+				Command: &StepCommand{
+					Name:      "node${{ .parameters.version }}",
+					Arguments: []string{"--branch", "${{ .event.branch }}"},
+				},
+			},
+			{
+				Script: &StepScript{
+					Path:      "build.sh",
+					Arguments: []string{"${{ .parameters.version }}"},
+				},
+			},
+			{
+				Command: &StepCommand{
+					Name:      "docker",
+					Arguments: []string{"ps", "--format", "{{.Names}}"},
+				},
+			},
+		},
+	}
+
+	spec2, err := spec.Interpolate(ectx)
+	require.NoError(err)
+
+	assert.Equal("main", spec2.Environment["BRANCH"])
+	assert.Equal("bar", spec2.Environment["FOO"])
+	assert.Equal("node20", spec2.Steps[0].Command.Name)
+	assert.Equal([]string{"--branch", "main"}, spec2.Steps[0].Command.Arguments)
+	assert.Equal([]string{"20"}, spec2.Steps[1].Script.Arguments)
+	assert.Equal([]string{"ps", "--format", "{{.Names}}"},
+		spec2.Steps[2].Command.Arguments)
+
+	assert.Equal("${{ .event.branch }}", spec.Environment["BRANCH"])
+	assert.Equal("node${{ .parameters.version }}", spec.Steps[0].Command.Name)
+
+	// Missing field
+	spec.Steps[0].Command.Arguments = []string{"${{ .parameters.foo }}"}
+	_, err = spec.Interpolate(ectx)
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "/steps/0/command/arguments/0")
+		assert.Contains(err.Error(), "foo")
+	}
+}
+
+func TestJobSpecCheckInterpolation(t *testing.T) {
+	assert := assert.New(t)
+
+	spec := &JobSpec{
+		Environment: map[string]string{"FOO": "${{ .parameters.foo"},
+		Steps: Steps{
+			{Command: &StepCommand{Name: "${{ .parameters.foo }}"}},
+			{Code: "docker ps --format '{{.Names}}'"},
+			{
+				Command: &StepCommand{
+					Name:      "docker",
+					Arguments: []string{"inspect", "--format", "{{ .Id"},
+				},
+			},
+		},
+	}
+
+	v := ejson.NewValidator()
+	spec.CheckInterpolation(v)
+
+	var verrs ejson.ValidationErrors
+	if assert.ErrorAs(v.Error(), &verrs) && assert.Len(verrs, 1) {
+		assert.Equal("/environment/FOO", verrs[0].Pointer.String())
+		assert.Equal("invalid_template", verrs[0].Code)
+	}
+}
+
+func TestJobSpecCheckInterpolationReferences(t *testing.T) {
+	assert := assert.New(t)
+
+	spec := &JobSpec{
+		Parameters: Parameters{
+			{Name: "version", Type: ParameterTypeString},
+		},
+		Matrix: Matrix{"os": {"linux", "freebsd"}},
+		Environment: map[string]string{
+			"VERSION": "${{ .parameters.version }}",
+			"OS":      "${{ $.parameters.os }}",
+			"BRANCH":  "${{ .event.branch }}",
+		},
+		Steps: Steps{
+			{
+				Command: &StepCommand{
+					Name: "${{ .parameters.foo }}",
+					Arguments: []string{
+						"${{ range .parameters.version }}${{ .x }}${{ end }}",
+						"${{ .foo }}",
+					},
+				},
+			},
+		},
+	}
+
+	v := ejson.NewValidator()
+	spec.CheckInterpolationReferences(v)
+
+	codes := make(map[string]string)
+
+	var verrs ejson.ValidationErrors
+	if assert.ErrorAs(v.Error(), &verrs) && assert.Len(verrs, 3) {
+		for _, verr := range verrs {
+			codes[verr.Pointer.String()] = verr.Code
+		}
+	}
+
+	assert.Equal(map[string]string{
+		"/environment/BRANCH":          "unknown_template_event_field",
+		"/steps/0/command/name":        "unknown_template_parameter",
+		"/steps/0/command/arguments/1": "unknown_template_field",
+	}, codes)
+}
+
+func TestJobSpecCheckStepsInterpolation(t *testing.T) {
+	assert := assert.New(t)
+
+	spec := &JobSpec{
+		Parameters: Parameters{
+			{Name: "version", Type: ParameterTypeString},
+		},
+	}
+
+	steps := Steps{
+		{
+			Command: &StepCommand{
+				Name: "node${{ .parameters.version }}",
+				Arguments: []string{
+					"${{ .parameters.foo }}",
+					"${{ .parameters.version",
+					"{{.Names}}",
+				},
+			},
+		},
+	}
+
+	v := ejson.NewValidator()
+	spec.CheckStepsInterpolation(v, steps)
+
+	codes := make(map[string]string)
+
+	var verrs ejson.ValidationErrors
+	if assert.ErrorAs(v.Error(), &verrs) && assert.Len(verrs, 2) {
+		for _, verr := range verrs {
+			codes[verr.Pointer.String()] = verr.Code
+		}
+	}
+
+	assert.Equal(map[string]string{
+		"/0/command/arguments/0": "unknown_template_parameter",
+		"/0/command/arguments/1": "invalid_template",
+	}, codes)
+}
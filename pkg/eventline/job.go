@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
 	"time"
 
@@ -71,6 +72,7 @@ type Job struct {
 	UpdateTime   time.Time `json:"update_time"`
 	Disabled     bool      `json:"disabled,omitempty"`
 	Spec         *JobSpec  `json:"spec"`
+	GitCommit    string    `json:"git_commit,omitempty"`
 }
 
 type Jobs []*Job
@@ -81,6 +83,7 @@ type JobSpec struct {
 
 	Trigger    *Trigger   `json:"trigger,omitempty"`
 	Parameters Parameters `json:"parameters,omitempty"`
+	Matrix     Matrix     `json:"matrix,omitempty"`
 
 	Runner     *JobRunner `json:"runner"`
 	Concurrent bool       `json:"concurrent,omitempty"`
@@ -111,15 +114,43 @@ type Trigger struct {
 	RawParameters json.RawMessage        `json:"parameters,omitempty"`
 	Identity      string                 `json:"identity,omitempty"`
 	Filters       Filters                `json:"filters,omitempty"`
+	Report        bool                   `json:"report,omitempty"`
+	Deduplication *TriggerDeduplication  `json:"deduplication,omitempty"`
+	Debounce      *TriggerDebounce       `json:"debounce,omitempty"`
+	ShareWith     []string               `json:"share_with,omitempty"` // project names
+	Source        string                 `json:"source,omitempty"`     // project name
+}
+
+// Events whose key was already seen less than window seconds ago are
+// ignored. The key is the value referenced by a JSON pointer in event data,
+// or the whole event data if there is no pointer.
+type TriggerDeduplication struct {
+	Key    ejson.Pointer `json:"key,omitempty"`
+	Window int           `json:"window"` // seconds
+}
+
+// Events are held until no event with the same key has been received for
+// delay seconds; the job is then executed once with the latest event. Without
+// a key, all events received by the job are debounced together.
+type TriggerDebounce struct {
+	Key   ejson.Pointer `json:"key,omitempty"`
+	Delay int           `json:"delay"` // seconds
 }
 
 type Step struct {
 	Label string `json:"label,omitempty"`
+	Group string `json:"group,omitempty"`
 
 	Code    string       `json:"code,omitempty"`
 	Command *StepCommand `json:"command,omitempty"`
 	Script  *StepScript  `json:"script,omitempty"`
 
+	Template *StepTemplateRef `json:"template,omitempty"`
+
+	// The reference a step was expanded from when the job was deployed
+	TemplateOrigin *StepTemplateRef `json:"template_origin,omitempty"`
+
+	When      *StepCondition    `json:"when,omitempty"`
 	OnFailure StepFailureAction `json:"on_failure,omitempty"`
 }
 
@@ -166,6 +197,18 @@ func (spec JobSpec) ValidateJSON(v *ejson.Validator) {
 	v.CheckOptionalObject("trigger", spec.Trigger)
 	v.CheckObjectArray("parameters", spec.Parameters)
 
+	if spec.Matrix != nil {
+		spec.Matrix.Check(v, "matrix")
+
+		v.WithChild("matrix", func() {
+			for _, name := range spec.Matrix.AxisNames() {
+				v.Check(name, spec.Parameters.Parameter(name) == nil,
+					"duplicate_matrix_axis",
+					"matrix axis %q conflicts with a parameter", name)
+			}
+		})
+	}
+
 	v.CheckOptionalObject("runner", spec.Runner)
 
 	if spec.Retention != 0 {
@@ -184,6 +227,37 @@ func (spec JobSpec) ValidateJSON(v *ejson.Validator) {
 			s.Label = "Step " + strconv.Itoa(i+1)
 		}
 	}
+
+	// Steps running in parallel must be consecutive
+	v.WithChild("steps", func() {
+		groups := make(map[string]struct{})
+
+		for i := 0; i < len(spec.Steps); {
+			end := spec.Steps.GroupEnd(i)
+
+			if group := spec.Steps[i].Group; group != "" {
+				_, found := groups[group]
+				v.Check(i, !found, "non_consecutive_step_group",
+					"steps of group %q must be consecutive", group)
+
+				groups[group] = struct{}{}
+			}
+
+			i = end
+		}
+	})
+
+	spec.CheckInterpolation(v)
+}
+
+// SubscriptionTrigger returns the trigger of the job if it requires a
+// subscription, i.e. if it does not receive events shared by another project.
+func (spec *JobSpec) SubscriptionTrigger() *Trigger {
+	if spec.Trigger == nil || spec.Trigger.Source != "" {
+		return nil
+	}
+
+	return spec.Trigger
 }
 
 func (r *JobRunner) ValidateJSON(v *ejson.Validator) {
@@ -250,11 +324,54 @@ func (pr *JobRunner) UnmarshalJSON(data []byte) error {
 }
 
 func (t *Trigger) ValidateJSON(v *ejson.Validator) {
-	CheckEventRef(v, "event", t.Event)
+	validEvent := CheckEventRef(v, "event", t.Event)
 
 	v.CheckOptionalObject("parameters", t.Parameters)
 
 	v.CheckObjectArray("filters", t.Filters)
+
+	v.CheckOptionalObject("deduplication", t.Deduplication)
+	v.CheckOptionalObject("debounce", t.Debounce)
+
+	v.WithChild("share_with", func() {
+		for i, name := range t.ShareWith {
+			CheckName(v, i, name)
+		}
+	})
+
+	// Triggers using another project as source receive events shared by
+	// this project and do not subscribe to anything themselves.
+	if t.Source != "" {
+		CheckName(v, "source", t.Source)
+
+		v.Check("parameters", t.RawParameters == nil, "unexpected_value",
+			"triggers with a source cannot have parameters")
+		v.Check("identity", t.Identity == "", "unexpected_value",
+			"triggers with a source cannot have an identity")
+		v.Check("share_with", len(t.ShareWith) == 0, "unexpected_value",
+			"triggers with a source cannot share events")
+	}
+
+	if t.Report {
+		if validEvent {
+			cname := t.Event.Connector
+
+			v.Check("report", ConnectorSupportsReporting(cname),
+				"unsupported_report",
+				"connector %q does not support reporting", cname)
+		}
+
+		v.Check("identity", t.Identity != "", "missing_value",
+			"reporting requires an identity")
+	}
+}
+
+func (d *TriggerDeduplication) ValidateJSON(v *ejson.Validator) {
+	v.CheckIntMin("window", d.Window, 1)
+}
+
+func (d *TriggerDebounce) ValidateJSON(v *ejson.Validator) {
+	v.CheckIntMin("delay", d.Delay, 1)
 }
 
 func (pt *Trigger) MarshalJSON() ([]byte, error) {
@@ -304,6 +421,10 @@ func (s *Step) ValidateJSON(v *ejson.Validator) {
 		CheckLabel(v, "label", s.Label)
 	}
 
+	if s.Group != "" {
+		CheckName(v, "group", s.Group)
+	}
+
 	n := 0
 	if s.Code != "" {
 		n += 1
@@ -314,17 +435,23 @@ func (s *Step) ValidateJSON(v *ejson.Validator) {
 	if s.Script != nil {
 		n += 1
 	}
+	if s.Template != nil {
+		n += 1
+	}
 
 	if n == 0 {
 		v.AddError(ejson.Pointer{}, "missing_step_content",
-			"missing code, command or script member")
+			"missing code, command, script or template member")
 	} else if n > 1 {
 		v.AddError(ejson.Pointer{}, "multiple_step_contents",
-			"multiple code, command or script members")
+			"multiple code, command, script or template members")
 	}
 
 	v.CheckOptionalObject("command", s.Command)
 	v.CheckOptionalObject("script", s.Script)
+	v.CheckOptionalObject("template", s.Template)
+
+	v.CheckOptionalObject("when", s.When)
 }
 
 func (s *StepCommand) ValidateJSON(v *ejson.Validator) {
@@ -336,6 +463,10 @@ func (s *StepScript) ValidateJSON(v *ejson.Validator) {
 }
 
 func (spec *JobSpec) ParseYAML(data []byte) error {
+	return decodeYAMLDocument(data, spec)
+}
+
+func decodeYAMLDocument(data []byte, dest interface{}) error {
 	decoder := yaml.NewDecoder(bytes.NewReader(data))
 
 	var yamlValue interface{}
@@ -355,7 +486,7 @@ func (spec *JobSpec) ParseYAML(data []byte) error {
 
 	d := json.NewDecoder(bytes.NewReader(jsonData))
 	d.DisallowUnknownFields()
-	if err := d.Decode(spec); err != nil {
+	if err := d.Decode(dest); err != nil {
 		return fmt.Errorf("cannot decode json data: %w", err)
 	}
 
@@ -378,9 +509,26 @@ func (spec *JobSpec) IdentityNames() []string {
 	return names
 }
 
+// Equal reports whether two job specifications are identical once encoded.
+// Encoded specifications are compared as JSON values since specifications
+// stored in the database do not preserve the order of object keys.
+func (spec *JobSpec) Equal(spec2 *JobSpec) (bool, error) {
+	value1, err := jsonValue(spec)
+	if err != nil {
+		return false, fmt.Errorf("cannot encode job specification: %w", err)
+	}
+
+	value2, err := jsonValue(spec2)
+	if err != nil {
+		return false, fmt.Errorf("cannot encode job specification: %w", err)
+	}
+
+	return reflect.DeepEqual(value1, value2), nil
+}
+
 func (j *Job) Load(conn pg.Conn, id uuid.UUID, scope Scope) error {
 	query := fmt.Sprintf(`
-SELECT id, project_id, creation_time, update_time, disabled, spec
+SELECT id, project_id, creation_time, update_time, disabled, spec, git_commit
   FROM jobs
   WHERE %s AND id = $1;
 `, scope.SQLCondition())
@@ -395,7 +543,7 @@ SELECT id, project_id, creation_time, update_time, disabled, spec
 
 func (j *Job) LoadForUpdate(conn pg.Conn, id uuid.UUID, scope Scope) error {
 	query := fmt.Sprintf(`
-SELECT id, project_id, creation_time, update_time, disabled, spec
+SELECT id, project_id, creation_time, update_time, disabled, spec, git_commit
   FROM jobs
   WHERE %s AND id = $1
   FOR UPDATE;
@@ -411,7 +559,7 @@ SELECT id, project_id, creation_time, update_time, disabled, spec
 
 func (j *Job) LoadByName(conn pg.Conn, name string, scope Scope) error {
 	query := fmt.Sprintf(`
-SELECT id, project_id, creation_time, update_time, disabled, spec
+SELECT id, project_id, creation_time, update_time, disabled, spec, git_commit
   FROM jobs
   WHERE %s AND spec->>'name' = $1;
 `, scope.SQLCondition())
@@ -424,9 +572,21 @@ SELECT id, project_id, creation_time, update_time, disabled, spec
 	return err
 }
 
+func (js *Jobs) LoadAllForUpdate(conn pg.Conn, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT id, project_id, creation_time, update_time, disabled, spec, git_commit
+  FROM jobs
+  WHERE %s
+  ORDER BY spec->>'name'
+  FOR UPDATE;
+`, scope.SQLCondition())
+
+	return pg.QueryObjects(conn, js, query)
+}
+
 func (js *Jobs) LoadByIdentityName(conn pg.Conn, name string, scope Scope) error {
 	query := fmt.Sprintf(`
-SELECT id, project_id, creation_time, update_time, disabled, spec
+SELECT id, project_id, creation_time, update_time, disabled, spec, git_commit
   FROM jobs
   WHERE %s
     AND (spec->'trigger'->>'identity' = $1
@@ -438,6 +598,21 @@ SELECT id, project_id, creation_time, update_time, disabled, spec
 	return pg.QueryObjects(conn, js, query, name)
 }
 
+// LoadBySource loads jobs whose trigger receives events of a specific type
+// shared by another project.
+func (js *Jobs) LoadBySource(conn pg.Conn, projectName string, event EventRef, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT id, project_id, creation_time, update_time, disabled, spec, git_commit
+  FROM jobs
+  WHERE %s
+    AND spec->'trigger'->>'source' = $1
+    AND spec->'trigger'->>'event' = $2
+  ORDER BY id
+`, scope.SQLCondition())
+
+	return pg.QueryObjects(conn, js, query, projectName, event.String())
+}
+
 func LoadJobNamesById(conn pg.Conn, ids []uuid.UUID) (map[uuid.UUID]string, error) {
 	ctx := context.Background()
 
@@ -484,7 +659,8 @@ func LoadJobPage(conn pg.Conn, options JobPageOptions, cursor *Cursor, scope Sco
 	}
 
 	query := fmt.Sprintf(`
-SELECT j.id, j.project_id, j.creation_time, j.update_time, j.disabled, j.spec
+SELECT j.id, j.project_id, j.creation_time, j.update_time, j.disabled, j.spec,
+       j.git_commit
   FROM jobs AS j
   %s
   WHERE %s AND %s AND %s;
@@ -507,18 +683,21 @@ func (j *Job) Upsert(conn pg.Conn) (*uuid.UUID, error) {
 
 	query := `
 INSERT INTO jobs
-    (id, project_id, creation_time, update_time, disabled, spec)
+    (id, project_id, creation_time, update_time, disabled, spec,
+     git_commit)
   VALUES
-    ($1, $2, $3, $4, $5, $6)
+    ($1, $2, $3, $4, $5, $6, $7)
   ON CONFLICT (project_id, (spec->>'name')) DO UPDATE SET
     update_time = EXCLUDED.update_time,
     disabled = EXCLUDED.disabled,
-    spec = EXCLUDED.spec
+    spec = EXCLUDED.spec,
+    git_commit = EXCLUDED.git_commit
   RETURNING id
 `
 	var id uuid.UUID
 	row := conn.QueryRow(ctx, query,
-		j.Id, j.ProjectId, j.CreationTime, j.UpdateTime, j.Disabled, j.Spec)
+		j.Id, j.ProjectId, j.CreationTime, j.UpdateTime, j.Disabled, j.Spec,
+		j.GitCommit)
 	if err := row.Scan(&id); err != nil {
 		return nil, err
 	}
@@ -531,12 +710,13 @@ func (j *Job) Update(conn pg.Conn, scope Scope) error {
 UPDATE jobs SET
     update_time = $2,
     disabled = $3,
-    spec = $4
+    spec = $4,
+    git_commit = $5
   WHERE %s AND id = $1;
 `, scope.SQLCondition())
 
 	return pg.Exec(conn, query,
-		j.Id, j.UpdateTime, j.Disabled, j.Spec)
+		j.Id, j.UpdateTime, j.Disabled, j.Spec, j.GitCommit)
 }
 
 func (j *Job) UpdateRename(conn pg.Conn, scope Scope) error {
@@ -581,7 +761,7 @@ func (js Jobs) Page(cursor *Cursor) *Page {
 
 func (j *Job) FromRow(row pgx.Row) error {
 	return row.Scan(&j.Id, &j.ProjectId, &j.CreationTime, &j.UpdateTime,
-		&j.Disabled, &j.Spec)
+		&j.Disabled, &j.Spec, &j.GitCommit)
 }
 
 func (js *Jobs) AddFromRow(row pgx.Row) error {
@@ -606,3 +786,20 @@ func (s *Step) AbortOnFailure() bool {
 		return true
 	}
 }
+
+// GroupEnd returns the index following the last step of the group starting at
+// index i. Steps without group are groups of their own.
+func (ss Steps) GroupEnd(i int) int {
+	group := ss[i].Group
+
+	j := i + 1
+	if group == "" {
+		return j
+	}
+
+	for j < len(ss) && ss[j].Group == group {
+		j++
+	}
+
+	return j
+}
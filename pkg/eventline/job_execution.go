@@ -2,6 +2,8 @@ package eventline
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -23,6 +25,10 @@ var JobExecutionSorts Sorts = Sorts{
 
 type JobExecutionPageOptions struct {
 	JobId *uuid.UUID
+
+	// Only select executions which have not been started yet and are
+	// scheduled in the future.
+	Delayed bool
 }
 
 type UnknownJobExecutionError struct {
@@ -96,6 +102,11 @@ type JobExecution struct {
 	RefreshTime    *time.Time             `json:"refresh_time,omitempty"`
 	ExpirationTime *time.Time             `json:"expiration_time,omitempty"`
 	FailureMessage string                 `json:"failure_message,omitempty"`
+
+	MatrixId          *uuid.UUID             `json:"matrix_id,omitempty"`
+	MatrixCombination map[string]interface{} `json:"matrix_combination,omitempty"`
+
+	JobVersionId *uuid.UUID `json:"job_version_id,omitempty"`
 }
 
 type JobExecutions []*JobExecution
@@ -122,6 +133,106 @@ func (je *JobExecution) Duration() *time.Duration {
 	return &d
 }
 
+func (je *JobExecution) MarshalJSON() ([]byte, error) {
+	type JobExecution2 JobExecution
+
+	je2 := JobExecution2(*je)
+	je2.Parameters = je.MaskedParameters()
+
+	return json.Marshal(je2)
+}
+
+// MaskedParameters returns a copy of the parameters of the job execution
+// where the values of secret parameters are masked.
+func (je *JobExecution) MaskedParameters() map[string]interface{} {
+	if je.Parameters == nil {
+		return nil
+	}
+
+	params := make(map[string]interface{}, len(je.Parameters))
+	for name, value := range je.Parameters {
+		params[name] = value
+	}
+
+	if je.JobSpec != nil {
+		for _, name := range je.JobSpec.Parameters.SecretNames() {
+			if _, found := params[name]; found {
+				params[name] = SecretParameterMask
+			}
+		}
+	}
+
+	return params
+}
+
+// SecretParameterValues returns the values of all secret parameters.
+func (je *JobExecution) SecretParameterValues() map[string]string {
+	values := make(map[string]string)
+
+	if je.JobSpec == nil {
+		return values
+	}
+
+	for _, name := range je.JobSpec.Parameters.SecretNames() {
+		if value, ok := je.Parameters[name].(string); ok {
+			values[name] = value
+		}
+	}
+
+	return values
+}
+
+// encryptedParameters returns a copy of the parameters of the job execution
+// where the values of secret parameters are encrypted so that they can be
+// stored in the database.
+func (je *JobExecution) encryptedParameters() (interface{}, error) {
+	if je.Parameters == nil {
+		return nil, nil
+	}
+
+	params := make(map[string]interface{}, len(je.Parameters))
+	for name, value := range je.Parameters {
+		params[name] = value
+	}
+
+	for name, value := range je.SecretParameterValues() {
+		encryptedValue, err := EncryptAES256([]byte(value))
+		if err != nil {
+			return nil, fmt.Errorf("cannot encrypt parameter %q: %w",
+				name, err)
+		}
+
+		params[name] = base64.StdEncoding.EncodeToString(encryptedValue)
+	}
+
+	return params, nil
+}
+
+func (je *JobExecution) decryptParameters() error {
+	for name, value := range je.SecretParameterValues() {
+		encryptedValue, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("cannot decode parameter %q: %w", name, err)
+		}
+
+		decryptedValue, err := DecryptAES256(encryptedValue)
+		if err != nil {
+			return fmt.Errorf("cannot decrypt parameter %q: %w", name, err)
+		}
+
+		je.Parameters[name] = string(decryptedValue)
+	}
+
+	return nil
+}
+
+// Delayed reports whether the job execution has not started yet and is
+// scheduled to start in the future.
+func (je *JobExecution) Delayed() bool {
+	return je.Status == JobExecutionStatusCreated &&
+		je.ScheduledTime.After(time.Now().UTC())
+}
+
 func (je *JobExecution) Finished() bool {
 	return je.Status != JobExecutionStatusCreated &&
 		je.Status != JobExecutionStatusStarted
@@ -131,7 +242,8 @@ func (je *JobExecution) Load(conn pg.Conn, id uuid.UUID, scope Scope) error {
 	query := fmt.Sprintf(`
 SELECT id, project_id, job_id, job_spec, event_id, parameters,
        creation_time, update_time, scheduled_time, status, start_time,
-       end_time, refresh_time, expiration_time, failure_message
+       end_time, refresh_time, expiration_time, failure_message,
+       matrix_id, matrix_combination, job_version_id
   FROM job_executions
   WHERE %s AND id = $1;
 `, scope.SQLCondition())
@@ -148,7 +260,8 @@ func (je *JobExecution) LoadForUpdate(conn pg.Conn, id uuid.UUID, scope Scope) e
 	query := fmt.Sprintf(`
 SELECT id, project_id, job_id, job_spec, event_id, parameters,
        creation_time, update_time, scheduled_time, status, start_time,
-       end_time, refresh_time, expiration_time, failure_message
+       end_time, refresh_time, expiration_time, failure_message,
+       matrix_id, matrix_combination, job_version_id
   FROM job_executions
   WHERE %s AND id = $1
   FOR UPDATE;
@@ -166,7 +279,8 @@ func (je *JobExecution) LoadForUpdateNoScope(conn pg.Conn, id uuid.UUID) error {
 	query := `
 SELECT id, project_id, job_id, job_spec, event_id, parameters,
        creation_time, update_time, scheduled_time, status, start_time,
-       end_time, refresh_time, expiration_time, failure_message
+       end_time, refresh_time, expiration_time, failure_message,
+       matrix_id, matrix_combination, job_version_id
   FROM job_executions
   WHERE id = $1
   FOR UPDATE;
@@ -184,7 +298,8 @@ func LoadLastJobExecutionFinishedBefore(conn pg.Conn, je *JobExecution) (*JobExe
 	query := `
 SELECT id, project_id, job_id, job_spec, event_id, parameters,
        creation_time, update_time, scheduled_time, status, start_time,
-       end_time, refresh_time, expiration_time, failure_message
+       end_time, refresh_time, expiration_time, failure_message,
+       matrix_id, matrix_combination, job_version_id
   FROM job_executions
   WHERE job_id = $1
     AND id <> $2
@@ -203,13 +318,17 @@ SELECT id, project_id, job_id, job_spec, event_id, parameters,
 }
 
 func LoadJobExecutionForScheduling(conn pg.Conn) (*JobExecution, error) {
+	now := time.Now().UTC()
+
 	query := `
 SELECT je1.id, je1.project_id, je1.job_id, je1.job_spec, je1.event_id,
        je1.parameters, je1.creation_time, je1.update_time, je1.scheduled_time,
        je1.status, je1.start_time, je1.end_time, je1.refresh_time,
-       je1.expiration_time, je1.failure_message
+       je1.expiration_time, je1.failure_message, je1.matrix_id,
+       je1.matrix_combination, je1.job_version_id
   FROM job_executions AS je1
   WHERE je1.status = 'created'
+    AND je1.scheduled_time <= $1
     AND (((je1.job_spec->'concurrent')::BOOLEAN IS TRUE)
          OR
          (NOT EXISTS
@@ -223,7 +342,7 @@ SELECT je1.id, je1.project_id, je1.job_id, je1.job_spec, je1.event_id,
   FOR UPDATE SKIP LOCKED;
 `
 	var je JobExecution
-	err := pg.QueryObject(conn, &je, query)
+	err := pg.QueryObject(conn, &je, query, now)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	} else if err != nil {
@@ -241,7 +360,8 @@ func LoadDeadJobExecution(conn pg.Conn, timeout int) (*JobExecution, error) {
 SELECT id, project_id, job_id, job_spec, event_id,
        parameters, creation_time, update_time, scheduled_time,
        status, start_time, end_time, refresh_time,
-       expiration_time, failure_message
+       expiration_time, failure_message, matrix_id, matrix_combination,
+       job_version_id
   FROM job_executions
   WHERE status = 'started'
     AND refresh_time < $1
@@ -259,30 +379,49 @@ SELECT id, project_id, job_id, job_spec, event_id,
 	return &je, nil
 }
 
-func (jes *JobExecutions) LoadByEvent(conn pg.Conn, eventId uuid.UUID) error {
-	query := `
+func (jes *JobExecutions) LoadByEvent(conn pg.Conn, eventId uuid.UUID, scope Scope) error {
+	// Events shared between projects can have job executions in several
+	// projects.
+	query := fmt.Sprintf(`
 SELECT id, project_id, job_id, job_spec, event_id, parameters,
        creation_time, update_time, scheduled_time, status, start_time,
-       end_time, refresh_time, expiration_time, failure_message
+       end_time, refresh_time, expiration_time, failure_message,
+       matrix_id, matrix_combination, job_version_id
   FROM job_executions
-  WHERE event_id = $1
+  WHERE %s AND event_id = $1
   ORDER BY scheduled_time DESC;
-`
+`, scope.SQLCondition())
 	return pg.QueryObjects(conn, jes, query, eventId)
 }
 
+func (jes *JobExecutions) LoadByMatrixId(conn pg.Conn, matrixId uuid.UUID, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT id, project_id, job_id, job_spec, event_id, parameters,
+       creation_time, update_time, scheduled_time, status, start_time,
+       end_time, refresh_time, expiration_time, failure_message,
+       matrix_id, matrix_combination, job_version_id
+  FROM job_executions
+  WHERE %s AND matrix_id = $1
+  ORDER BY id;
+`, scope.SQLCondition())
+
+	return pg.QueryObjects(conn, jes, query, matrixId)
+}
+
 func LoadLastJobExecutions(conn pg.Conn, jobIds []uuid.UUID, scope Scope) (map[uuid.UUID]*JobExecution, error) {
 	query := fmt.Sprintf(`
 WITH ranked_jobs AS
        (SELECT id, project_id, job_id, job_spec, event_id, parameters,
                creation_time, update_time, scheduled_time, status, start_time,
                end_time, refresh_time, expiration_time, failure_message,
+               matrix_id, matrix_combination, job_version_id,
                row_number() OVER (PARTITION BY job_id ORDER BY id DESC) AS rank
           FROM job_executions
           WHERE %s AND job_id = ANY ($1))
   SELECT id, project_id, job_id, job_spec, event_id, parameters,
          creation_time, update_time, scheduled_time, status, start_time,
-         end_time, refresh_time, expiration_time, failure_message
+         end_time, refresh_time, expiration_time, failure_message,
+         matrix_id, matrix_combination, job_version_id
     FROM ranked_jobs
     WHERE rank = 1;
 `, scope.SQLCondition())
@@ -308,13 +447,21 @@ func LoadJobExecutionPage(conn pg.Conn, options JobExecutionPageOptions, cursor
 		jobCond = "job_id=" + pg.QuoteString(jobId.String())
 	}
 
+	delayedCond := "TRUE"
+	if options.Delayed {
+		now := time.Now().UTC()
+		delayedCond = "status = 'created' AND scheduled_time > " +
+			pg.QuoteString(now.Format(time.RFC3339Nano))
+	}
+
 	query := fmt.Sprintf(`
 SELECT id, project_id, job_id, job_spec, event_id, parameters,
        creation_time, update_time, scheduled_time, status, start_time,
-       end_time, refresh_time, expiration_time, failure_message
+       end_time, refresh_time, expiration_time, failure_message,
+       matrix_id, matrix_combination, job_version_id
   FROM job_executions
-  WHERE %s AND %s AND %s;
-`, scope.SQLCondition(), jobCond,
+  WHERE %s AND %s AND %s AND %s;
+`, scope.SQLCondition(), jobCond, delayedCond,
 		cursor.SQLConditionOrderLimit(JobExecutionSorts))
 
 	var jes JobExecutions
@@ -344,26 +491,33 @@ SELECT COUNT(*)
 }
 
 func (je *JobExecution) Insert(conn pg.Conn) error {
-	var parameters interface{}
-	if je.Parameters != nil {
-		parameters = je.Parameters
+	parameters, err := je.encryptedParameters()
+	if err != nil {
+		return err
+	}
+
+	var matrixCombination interface{}
+	if je.MatrixCombination != nil {
+		matrixCombination = je.MatrixCombination
 	}
 
 	query := `
 INSERT INTO job_executions
     (id, project_id, job_id, job_spec, event_id, parameters,
      creation_time, update_time, scheduled_time, status, start_time,
-     end_time, refresh_time, expiration_time, failure_message)
+     end_time, refresh_time, expiration_time, failure_message,
+     matrix_id, matrix_combination, job_version_id)
   VALUES
     ($1, $2, $3, $4, $5, $6,
      $7, $8, $9, $10, $11,
-     $12, $13, $14, $15);
+     $12, $13, $14, $15,
+     $16, $17, $18);
 `
 	return pg.Exec(conn, query,
 		je.Id, je.ProjectId, je.JobId, je.JobSpec, je.EventId, parameters,
 		je.CreationTime, je.UpdateTime, je.ScheduledTime, je.Status,
 		je.StartTime, je.EndTime, je.RefreshTime, je.ExpirationTime,
-		je.FailureMessage)
+		je.FailureMessage, je.MatrixId, matrixCombination, je.JobVersionId)
 }
 
 func (je *JobExecution) Update(conn pg.Conn) error {
@@ -383,6 +537,20 @@ UPDATE job_executions SET
 		je.RefreshTime, je.ExpirationTime, je.FailureMessage)
 }
 
+func (je *JobExecution) UpdateParameters(conn pg.Conn) error {
+	parameters, err := je.encryptedParameters()
+	if err != nil {
+		return err
+	}
+
+	query := `
+UPDATE job_executions SET
+    parameters = $2
+  WHERE id = $1;
+`
+	return pg.Exec(conn, query, je.Id, parameters)
+}
+
 func (je *JobExecution) UpdateRefreshTime(conn pg.Conn) error {
 	query := `
 UPDATE job_executions SET
@@ -420,10 +588,16 @@ func (jes JobExecutions) Page(cursor *Cursor) *Page {
 }
 
 func (je *JobExecution) FromRow(row pgx.Row) error {
-	return row.Scan(&je.Id, &je.ProjectId, &je.JobId, &je.JobSpec, &je.EventId,
+	err := row.Scan(&je.Id, &je.ProjectId, &je.JobId, &je.JobSpec, &je.EventId,
 		&je.Parameters, &je.CreationTime, &je.UpdateTime, &je.ScheduledTime,
 		&je.Status, &je.StartTime, &je.EndTime, &je.RefreshTime,
-		&je.ExpirationTime, &je.FailureMessage)
+		&je.ExpirationTime, &je.FailureMessage, &je.MatrixId,
+		&je.MatrixCombination, &je.JobVersionId)
+	if err != nil {
+		return err
+	}
+
+	return je.decryptParameters()
 }
 
 func (jes *JobExecutions) AddFromRow(row pgx.Row) error {
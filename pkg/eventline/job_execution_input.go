@@ -3,11 +3,15 @@ package eventline
 import (
 	"bytes"
 	"encoding/json"
+	"time"
 )
 
 type JobExecutionInput struct {
 	Parameters    map[string]interface{} `json:"-"`
 	RawParameters json.RawMessage        `json:"parameters"`
+
+	// If set, the job is executed at this time instead of immediately.
+	ScheduledTime *time.Time `json:"scheduled_time,omitempty"`
 }
 
 func (pi *JobExecutionInput) MarshalJSON() ([]byte, error) {
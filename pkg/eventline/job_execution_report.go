@@ -0,0 +1,81 @@
+package eventline
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+// QueuedJobExecutionReport is a request to report the status of a job
+// execution to an external service. Reports are delivered asynchronously
+// with the status of the job execution at delivery time, so that failed
+// deliveries can be retried in any order.
+type QueuedJobExecutionReport struct {
+	Id               uuid.UUID
+	JobExecutionId   uuid.UUID
+	NextDeliveryTime time.Time
+	DeliveryDelay    int // seconds
+	NbAttempts       int
+}
+
+func LoadQueuedJobExecutionReportForDelivery(conn pg.Conn) (*QueuedJobExecutionReport, error) {
+	now := time.Now().UTC()
+
+	query := `
+SELECT id, job_execution_id, next_delivery_time, delivery_delay, nb_attempts
+  FROM job_execution_reports
+  WHERE next_delivery_time < $1
+  ORDER BY next_delivery_time
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED;
+`
+	var r QueuedJobExecutionReport
+	err := pg.QueryObject(conn, &r, query, now)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+func (r *QueuedJobExecutionReport) Insert(conn pg.Conn) error {
+	query := `
+INSERT INTO job_execution_reports
+    (id, job_execution_id, next_delivery_time, delivery_delay, nb_attempts)
+  VALUES
+    ($1, $2, $3, $4, $5);
+`
+	return pg.Exec(conn, query,
+		r.Id, r.JobExecutionId, r.NextDeliveryTime, r.DeliveryDelay,
+		r.NbAttempts)
+}
+
+func (r *QueuedJobExecutionReport) Update(conn pg.Conn) error {
+	query := `
+UPDATE job_execution_reports SET
+    next_delivery_time = $2,
+    delivery_delay = $3,
+    nb_attempts = $4
+  WHERE id = $1
+`
+	return pg.Exec(conn, query,
+		r.Id, r.NextDeliveryTime, r.DeliveryDelay, r.NbAttempts)
+}
+
+func (r *QueuedJobExecutionReport) Delete(conn pg.Conn) error {
+	query := `
+DELETE FROM job_execution_reports
+  WHERE id = $1;
+`
+	return pg.Exec(conn, query, r.Id)
+}
+
+func (r *QueuedJobExecutionReport) FromRow(row pgx.Row) error {
+	return row.Scan(&r.Id, &r.JobExecutionId, &r.NextDeliveryTime,
+		&r.DeliveryDelay, &r.NbAttempts)
+}
@@ -0,0 +1,67 @@
+package eventline
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobExecutionSecretParameters(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	je := JobExecution{
+		JobSpec: &JobSpec{
+			Name: "test",
+			Parameters: Parameters{
+				{Name: "region", Type: ParameterTypeString},
+				{Name: "token", Type: ParameterTypeSecret},
+			},
+		},
+		Parameters: map[string]interface{}{
+			"region": "eu-west-3",
+			"token":  "s3cr3t",
+		},
+	}
+
+	data, err := json.Marshal(&je)
+	require.NoError(err)
+
+	assert.Contains(string(data), "eu-west-3")
+	assert.NotContains(string(data), "s3cr3t")
+	assert.Contains(string(data), SecretParameterMask)
+	assert.Equal("s3cr3t", je.Parameters["token"])
+
+	assert.Equal(map[string]string{"token": "s3cr3t"},
+		je.SecretParameterValues())
+
+	rd := RunnerData{
+		JobExecution:     &je,
+		ExecutionContext: &ExecutionContext{},
+	}
+
+	assert.Equal("token: [redacted parameter token]",
+		string(rd.SecretRedactor().Redact([]byte("token: s3cr3t"))))
+}
+
+func TestJobExecutionDelayed(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now().UTC()
+
+	je := JobExecution{
+		Status:        JobExecutionStatusCreated,
+		ScheduledTime: now.Add(time.Hour),
+	}
+	assert.True(je.Delayed())
+
+	je.ScheduledTime = now.Add(-time.Second)
+	assert.False(je.Delayed())
+
+	je.ScheduledTime = now.Add(time.Hour)
+	je.Status = JobExecutionStatusAborted
+	assert.False(je.Delayed())
+}
@@ -0,0 +1,150 @@
+package eventline
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FindJobFiles returns the path of all YAML files in a set of files and
+// directories. Nested directories are only explored if recursive is true.
+func FindJobFiles(filesOrDirPaths []string, recursive bool) ([]string, error) {
+	var filePaths []string
+
+	var fn func(string, int) error
+	fn = func(currentPath string, depth int) error {
+		info, err := os.Stat(currentPath)
+		if err != nil {
+			return fmt.Errorf("cannot stat %q: %w", currentPath, err)
+		}
+
+		// Symbolic links to directories found while walking directories are
+		// ignored since they could create loops.
+		if info.IsDir() && depth > 0 {
+			linfo, err := os.Lstat(currentPath)
+			if err != nil {
+				return fmt.Errorf("cannot stat %q: %w", currentPath, err)
+			}
+
+			if linfo.Mode()&os.ModeSymlink != 0 {
+				return nil
+			}
+		}
+
+		if info.IsDir() {
+			if recursive || depth == 0 {
+				entries, err := os.ReadDir(currentPath)
+				if err != nil {
+					return fmt.Errorf("cannot list directory %q: %w",
+						currentPath, err)
+				}
+
+				for _, entry := range entries {
+					fullPath := path.Join(currentPath, entry.Name())
+					if err := fn(fullPath, depth+1); err != nil {
+						return err
+					}
+				}
+			}
+		} else {
+			ext := path.Ext(currentPath)
+			if ext == ".yml" || ext == ".yaml" {
+				filePaths = append(filePaths, currentPath)
+			}
+		}
+
+		return nil
+	}
+
+	for _, fileOrDirPath := range filesOrDirPaths {
+		if err := fn(fileOrDirPath, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	return filePaths, nil
+}
+
+// LoadJobFile loads a job specification file and the scripts referenced by
+// its steps. If rootPath is not empty, the file and its scripts must be
+// located in this directory; this is used to load files from untrusted
+// sources such as Git repositories.
+func LoadJobFile(filePath, rootPath string) (*JobSpec, error) {
+	if rootPath != "" {
+		if err := checkPathInDirectory(filePath, rootPath); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %q: %w", filePath, err)
+	}
+
+	var spec JobSpec
+	if err := spec.ParseYAML(data); err != nil {
+		return nil, fmt.Errorf("cannot decode data: %w", err)
+	}
+
+	dirPath := filepath.Dir(filePath)
+	if err := LoadSteps(&spec, dirPath, rootPath); err != nil {
+		return nil, err
+	}
+
+	return &spec, nil
+}
+
+func LoadSteps(spec *JobSpec, dirPath, rootPath string) error {
+	for i, step := range spec.Steps {
+		switch {
+		case step.Script != nil:
+			if err := LoadScriptStep(step, dirPath, rootPath); err != nil {
+				return fmt.Errorf("cannot load script for "+
+					"step %d: %w", i+1, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func LoadScriptStep(step *Step, dirPath, rootPath string) error {
+	filePath := path.Join(dirPath, step.Script.Path)
+
+	if rootPath != "" {
+		if err := checkPathInDirectory(filePath, rootPath); err != nil {
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("cannot read %q: %w", filePath, err)
+	}
+
+	step.Script.Content = string(data)
+
+	return nil
+}
+
+func checkPathInDirectory(filePath, dirPath string) error {
+	realDirPath, err := filepath.EvalSymlinks(dirPath)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %q: %w", dirPath, err)
+	}
+
+	realFilePath, err := filepath.EvalSymlinks(filePath)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %q: %w", filePath, err)
+	}
+
+	relPath, err := filepath.Rel(realDirPath, realFilePath)
+	if err != nil || relPath == ".." ||
+		strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%q is not located in %q", filePath, dirPath)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package eventline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindJobFiles(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dirPath := t.TempDir()
+
+	writeFile := func(name string) {
+		filePath := filepath.Join(dirPath, name)
+		require.NoError(os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(os.WriteFile(filePath, []byte("name: test\n"), 0644))
+	}
+
+	writeFile("a.yaml")
+	writeFile("b.txt")
+	writeFile("nested/c.yml")
+
+	// Symbolic links to directories are not followed, so loops are not a
+	// problem.
+	require.NoError(os.Symlink(dirPath, filepath.Join(dirPath, "nested/loop")))
+
+	filePaths, err := FindJobFiles([]string{dirPath}, true)
+	require.NoError(err)
+	assert.ElementsMatch([]string{
+		filepath.Join(dirPath, "a.yaml"),
+		filepath.Join(dirPath, "nested/c.yml"),
+	}, filePaths)
+
+	filePaths, err = FindJobFiles([]string{dirPath}, false)
+	require.NoError(err)
+	assert.Equal([]string{filepath.Join(dirPath, "a.yaml")}, filePaths)
+}
@@ -0,0 +1,88 @@
+package eventline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.n16f.net/ejson"
+)
+
+func TestStepsGroupEnd(t *testing.T) {
+	assert := assert.New(t)
+
+	steps := Steps{
+		{Code: "a"},
+		{Code: "b", Group: "tests"},
+		{Code: "c", Group: "tests"},
+		{Code: "d", Group: "lint"},
+		{Code: "e"},
+	}
+
+	assert.Equal(1, steps.GroupEnd(0))
+	assert.Equal(3, steps.GroupEnd(1))
+	assert.Equal(3, steps.GroupEnd(2))
+	assert.Equal(4, steps.GroupEnd(3))
+	assert.Equal(5, steps.GroupEnd(4))
+}
+
+func TestJobSpecStepGroupValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	spec := JobSpec{
+		Name: "test",
+		Steps: Steps{
+			{Code: "a", Group: "tests"},
+			{Code: "b", Group: "tests"},
+			{Code: "c"},
+		},
+	}
+
+	v := ejson.NewValidator()
+	spec.ValidateJSON(v)
+	assert.NoError(v.Error())
+
+	spec.Steps = append(spec.Steps, &Step{Code: "d", Group: "tests"})
+
+	v = ejson.NewValidator()
+	spec.ValidateJSON(v)
+	assert.Error(v.Error())
+}
+
+func TestTriggerSourceValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	// Connectors are not registered in this package, so we only look at
+	// errors unrelated to the event.
+	errorPointers := func(trigger *Trigger) []string {
+		v := ejson.NewValidator()
+		trigger.ValidateJSON(v)
+
+		var pointers []string
+		for _, err := range v.Errors {
+			if s := err.Pointer.String(); s != "/event" {
+				pointers = append(pointers, s)
+			}
+		}
+
+		return pointers
+	}
+
+	trigger := Trigger{
+		Event:  EventRef{Connector: "github", Event: "push"},
+		Source: "infrastructure",
+	}
+
+	assert.Empty(errorPointers(&trigger))
+
+	trigger.Identity = "github"
+	trigger.ShareWith = []string{"team-a"}
+
+	assert.Equal([]string{"/identity", "/share_with"},
+		errorPointers(&trigger))
+
+	spec := JobSpec{Name: "test", Trigger: &trigger}
+	assert.Nil(spec.SubscriptionTrigger())
+
+	trigger.Source = ""
+	assert.Equal(&trigger, spec.SubscriptionTrigger())
+}
@@ -0,0 +1,202 @@
+package eventline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.n16f.net/program"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+var JobVersionSorts Sorts = Sorts{
+	Sorts: map[string]string{
+		"version": "version",
+	},
+
+	Default: "version",
+}
+
+type UnknownJobVersionError struct {
+	JobId   uuid.UUID
+	Version int
+}
+
+func (err UnknownJobVersionError) Error() string {
+	return fmt.Sprintf("unknown version %d for job %q", err.Version, err.JobId)
+}
+
+type JobVersionSource string
+
+const (
+	JobVersionSourceDeployment JobVersionSource = "deployment"
+	JobVersionSourceRollback   JobVersionSource = "rollback"
+	JobVersionSourceGit        JobVersionSource = "git"
+)
+
+// JobVersionOrigin describes the operation which caused a job to be deployed.
+type JobVersionOrigin struct {
+	Source    JobVersionSource
+	AccountId *uuid.UUID
+	GitCommit string
+}
+
+// JobVersion is an immutable copy of the specification of a job created each
+// time the job is deployed with a different specification.
+type JobVersion struct {
+	Id           uuid.UUID        `json:"id"`
+	ProjectId    uuid.UUID        `json:"project_id"`
+	JobId        uuid.UUID        `json:"job_id"`
+	Version      int              `json:"version"`
+	CreationTime time.Time        `json:"creation_time"`
+	Source       JobVersionSource `json:"source"`
+	AccountId    *uuid.UUID       `json:"account_id,omitempty"`
+	AccountName  string           `json:"account_name,omitempty"` // ignored in input
+	Spec         *JobSpec         `json:"spec"`
+	GitCommit    string           `json:"git_commit,omitempty"`
+}
+
+type JobVersions []*JobVersion
+
+func (v *JobVersion) SortKey(sort string) (key string) {
+	switch sort {
+	case "version":
+		key = strconv.Itoa(v.Version)
+	default:
+		program.Panic("unknown job version sort %q", sort)
+	}
+
+	return
+}
+
+func (v *JobVersion) SameSpec(spec *JobSpec) (bool, error) {
+	return v.Spec.Equal(spec)
+}
+
+func (v *JobVersion) Load(conn pg.Conn, jobId uuid.UUID, version int, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT v.id, v.project_id, v.job_id, v.version, v.creation_time, v.source,
+       v.account_id, COALESCE(a.username, ''), v.spec, v.git_commit
+  FROM job_versions AS v
+  LEFT JOIN accounts AS a ON a.id = v.account_id
+  WHERE %s AND v.job_id = $1 AND v.version = $2;
+`, scope.SQLCondition2("v"))
+
+	err := pg.QueryObject(conn, v, query, jobId, version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &UnknownJobVersionError{JobId: jobId, Version: version}
+	}
+
+	return err
+}
+
+// LoadLast loads the most recent version of a job. It returns
+// UnknownJobVersionError with a zero version if the job does not have any
+// version.
+func (v *JobVersion) LoadLast(conn pg.Conn, jobId uuid.UUID, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT v.id, v.project_id, v.job_id, v.version, v.creation_time, v.source,
+       v.account_id, COALESCE(a.username, ''), v.spec, v.git_commit
+  FROM job_versions AS v
+  LEFT JOIN accounts AS a ON a.id = v.account_id
+  WHERE %s AND v.job_id = $1
+  ORDER BY v.version DESC
+  LIMIT 1;
+`, scope.SQLCondition2("v"))
+
+	err := pg.QueryObject(conn, v, query, jobId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &UnknownJobVersionError{JobId: jobId}
+	}
+
+	return err
+}
+
+func LoadJobVersionPage(conn pg.Conn, jobId uuid.UUID, cursor *Cursor, scope Scope) (*Page, error) {
+	query := fmt.Sprintf(`
+SELECT v.id, v.project_id, v.job_id, v.version, v.creation_time, v.source,
+       v.account_id, COALESCE(a.username, ''), v.spec, v.git_commit
+  FROM job_versions AS v
+  LEFT JOIN accounts AS a ON a.id = v.account_id
+  WHERE %s AND v.job_id = $1 AND %s;
+`, scope.SQLCondition2("v"), cursor.SQLConditionOrderLimit2(JobVersionSorts, "v"))
+
+	var versions JobVersions
+	if err := pg.QueryObjects(conn, &versions, query, jobId); err != nil {
+		return nil, err
+	}
+
+	return versions.Page(cursor), nil
+}
+
+func (v *JobVersion) Insert(conn pg.Conn) error {
+	ctx := context.Background()
+
+	query := `
+INSERT INTO job_versions
+    (id, project_id, job_id, version, creation_time, source, account_id,
+     spec, git_commit)
+  VALUES
+    ($1, $2, $3,
+     (SELECT COALESCE(MAX(version), 0) + 1
+        FROM job_versions
+        WHERE job_id = $3),
+     $4, $5, $6, $7, $8)
+  RETURNING version
+`
+	row := conn.QueryRow(ctx, query,
+		v.Id, v.ProjectId, v.JobId, v.CreationTime, v.Source, v.AccountId,
+		v.Spec, v.GitCommit)
+
+	return row.Scan(&v.Version)
+}
+
+// DeleteOldJobVersions deletes all versions of a job except the n most
+// recent ones.
+func DeleteOldJobVersions(conn pg.Conn, jobId uuid.UUID, n int, scope Scope) (int64, error) {
+	ctx := context.Background()
+
+	query := fmt.Sprintf(`
+DELETE FROM job_versions
+  WHERE %s AND job_id = $1
+    AND version <= (SELECT MAX(version) - $2
+                      FROM job_versions
+                      WHERE job_id = $1);
+`, scope.SQLCondition())
+
+	res, err := conn.Exec(ctx, query, jobId, n)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
+
+func (vs JobVersions) Page(cursor *Cursor) *Page {
+	elements := make([]PageElement, len(vs))
+	for i, v := range vs {
+		elements[i] = v
+	}
+
+	return NewPage(cursor, elements, JobVersionSorts)
+}
+
+func (v *JobVersion) FromRow(row pgx.Row) error {
+	return row.Scan(&v.Id, &v.ProjectId, &v.JobId, &v.Version,
+		&v.CreationTime, &v.Source, &v.AccountId, &v.AccountName, &v.Spec,
+		&v.GitCommit)
+}
+
+func (vs *JobVersions) AddFromRow(row pgx.Row) error {
+	var v JobVersion
+	if err := v.FromRow(row); err != nil {
+		return err
+	}
+
+	*vs = append(*vs, &v)
+	return nil
+}
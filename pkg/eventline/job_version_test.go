@@ -0,0 +1,30 @@
+package eventline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobVersionSameSpec(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	spec := func(code string) *JobSpec {
+		return &JobSpec{
+			Name:  "test",
+			Steps: Steps{{Label: "Step 1", Code: code}},
+		}
+	}
+
+	v := JobVersion{Spec: spec("echo hello")}
+
+	same, err := v.SameSpec(spec("echo hello"))
+	require.NoError(err)
+	assert.True(same)
+
+	same, err = v.SameSpec(spec("echo world"))
+	require.NoError(err)
+	assert.False(same)
+}
@@ -0,0 +1,214 @@
+package eventline
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"unicode/utf8"
+
+	"go.n16f.net/ejson"
+)
+
+// JSONSchema is the subset of JSON Schema (draft 2020-12) used to validate
+// the data of custom events. Unsupported keywords are ignored, as required
+// by the specification.
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Const                interface{}            `json:"const,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+}
+
+var JSONSchemaTypeValues = []string{
+	"null",
+	"boolean",
+	"object",
+	"array",
+	"number",
+	"integer",
+	"string",
+}
+
+func (s *JSONSchema) ValidateJSON(v *ejson.Validator) {
+	if s.Type != "" {
+		v.CheckStringValue("type", s.Type, JSONSchemaTypeValues)
+	}
+
+	v.WithChild("properties", func() {
+		for name, property := range s.Properties {
+			v.CheckObject(name, property)
+		}
+	})
+
+	v.CheckOptionalObject("items", s.Items)
+
+	if s.Pattern != "" {
+		_, err := regexp.Compile(s.Pattern)
+		v.Check("pattern", err == nil, "invalid_pattern",
+			"invalid regular expression: %v", err)
+	}
+
+	checkLength := func(token string, n *int) {
+		if n != nil {
+			v.Check(token, *n >= 0, "invalid_value",
+				"value must be greater or equal to zero")
+		}
+	}
+
+	checkLength("minItems", s.MinItems)
+	checkLength("maxItems", s.MaxItems)
+	checkLength("minLength", s.MinLength)
+	checkLength("maxLength", s.MaxLength)
+}
+
+// CheckValue validates a decoded JSON value against the schema.
+func (s *JSONSchema) CheckValue(v *ejson.Validator, token interface{}, value interface{}) {
+	if s.Type != "" && !s.checkType(value) {
+		v.AddError(token, "invalid_type", "value must be of type %q", s.Type)
+		return
+	}
+
+	if s.Enum != nil {
+		found := slices.ContainsFunc(s.Enum, func(e interface{}) bool {
+			return jsonValuesEqual(e, value)
+		})
+
+		v.Check(token, found, "invalid_value",
+			"value must be one of the values listed in the schema")
+	}
+
+	if s.Const != nil {
+		v.Check(token, jsonValuesEqual(s.Const, value), "invalid_value",
+			"value must be equal to %v", s.Const)
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		s.checkObject(v, token, value)
+
+	case []interface{}:
+		s.checkArray(v, token, value)
+
+	case float64:
+		if s.Minimum != nil {
+			v.Check(token, value >= *s.Minimum, "value_too_small",
+				"value must be greater or equal to %v", *s.Minimum)
+		}
+
+		if s.Maximum != nil {
+			v.Check(token, value <= *s.Maximum, "value_too_large",
+				"value must be lower or equal to %v", *s.Maximum)
+		}
+
+	case string:
+		length := utf8.RuneCountInString(value)
+
+		if s.MinLength != nil {
+			v.Check(token, length >= *s.MinLength, "string_too_short",
+				"string must contain at least %d characters", *s.MinLength)
+		}
+
+		if s.MaxLength != nil {
+			v.Check(token, length <= *s.MaxLength, "string_too_long",
+				"string must contain at most %d characters", *s.MaxLength)
+		}
+
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err == nil {
+				v.Check(token, re.MatchString(value), "invalid_format",
+					"string does not match pattern %q", s.Pattern)
+			}
+		}
+	}
+}
+
+func (s *JSONSchema) checkType(value interface{}) bool {
+	switch s.Type {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "string":
+		_, ok := value.(string)
+		return ok
+	}
+
+	return true
+}
+
+func (s *JSONSchema) checkObject(v *ejson.Validator, token interface{}, value map[string]interface{}) {
+	// JSON pointers cannot target missing members, so we report errors on
+	// the object itself.
+	for _, name := range s.Required {
+		if _, found := value[name]; !found {
+			v.AddError(token, "missing_value", "missing required member %q",
+				name)
+		}
+	}
+
+	v.WithChild(token, func() {
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if property, found := s.Properties[name]; found {
+				property.CheckValue(v, name, value[name])
+			} else if s.AdditionalProperties != nil {
+				v.Check(name, *s.AdditionalProperties, "unexpected_value",
+					"unexpected member %q", name)
+			}
+		}
+	})
+}
+
+func (s *JSONSchema) checkArray(v *ejson.Validator, token interface{}, value []interface{}) {
+	if s.MinItems != nil {
+		v.Check(token, len(value) >= *s.MinItems, "array_too_short",
+			"array must contain at least %d elements", *s.MinItems)
+	}
+
+	if s.MaxItems != nil {
+		v.Check(token, len(value) <= *s.MaxItems, "array_too_long",
+			"array must contain at most %d elements", *s.MaxItems)
+	}
+
+	if s.Items != nil {
+		v.WithChild(token, func() {
+			for i, element := range value {
+				s.Items.CheckValue(v, i, element)
+			}
+		})
+	}
+}
+
+func jsonValuesEqual(v1, v2 interface{}) bool {
+	return reflect.DeepEqual(v1, v2)
+}
@@ -0,0 +1,98 @@
+package eventline
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.n16f.net/ejson"
+)
+
+func TestJSONSchemaValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		data  string
+		valid bool
+	}{
+		{`{}`, true},
+		{`{"type": "object", "properties": {"a": {"type": "string"}}}`, true},
+		{`{"type": "foo"}`, false},
+		{`{"properties": {"a": {"type": "foo"}}}`, false},
+		{`{"type": "string", "pattern": "[a-z"}`, false},
+		{`{"type": "array", "minItems": -1}`, false},
+	}
+
+	for _, test := range tests {
+		var schema JSONSchema
+		err := ejson.Unmarshal([]byte(test.data), &schema)
+
+		if test.valid {
+			assert.NoError(err, test.data)
+		} else {
+			assert.Error(err, test.data)
+		}
+	}
+}
+
+func TestJSONSchemaCheckValue(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	schemaData := `{
+  "type": "object",
+  "required": ["version", "environment"],
+  "additionalProperties": false,
+  "properties": {
+    "version": {"type": "string", "pattern": "^v[0-9]+"},
+    "environment": {"enum": ["staging", "production"]},
+    "replicas": {"type": "integer", "minimum": 1, "maximum": 10},
+    "tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}}
+  }
+}`
+
+	var schema JSONSchema
+	require.NoError(ejson.Unmarshal([]byte(schemaData), &schema))
+
+	check := func(data string) ejson.ValidationErrors {
+		var value interface{}
+		require.NoError(json.Unmarshal([]byte(data), &value))
+
+		v := ejson.NewValidator()
+		schema.CheckValue(v, "data", value)
+
+		return v.Errors
+	}
+
+	assert.Empty(check(`{"version": "v1", "environment": "staging"}`))
+	assert.Empty(check(`{"version": "v2.1", "environment": "production",
+                         "replicas": 3, "tags": ["a", "b"]}`))
+
+	pointers := func(errs ejson.ValidationErrors) []string {
+		var ps []string
+		for _, err := range errs {
+			ps = append(ps, err.Pointer.String())
+		}
+		return ps
+	}
+
+	assert.Equal([]string{"/data"},
+		pointers(check(`[]`)))
+	assert.Equal([]string{"/data"},
+		pointers(check(`{"version": "v1"}`)))
+	assert.Equal([]string{"/data/environment", "/data/version"},
+		pointers(check(`{"version": "1", "environment": "dev"}`)))
+	assert.Equal([]string{"/data/replicas"},
+		pointers(check(`{"version": "v1", "environment": "staging",
+                         "replicas": 1.5}`)))
+	assert.Equal([]string{"/data/replicas"},
+		pointers(check(`{"version": "v1", "environment": "staging",
+                         "replicas": 20}`)))
+	assert.Equal([]string{"/data/tags", "/data/tags/1"},
+		pointers(check(`{"version": "v1", "environment": "staging",
+                         "tags": ["a", 2, "c"]}`)))
+	assert.Equal([]string{"/data/foo"},
+		pointers(check(`{"version": "v1", "environment": "staging",
+                         "foo": true}`)))
+}
@@ -0,0 +1,154 @@
+package eventline
+
+import (
+	"fmt"
+	"sort"
+
+	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+const MaxMatrixCombinations = 256
+
+type UnknownMatrixExecutionError struct {
+	Id uuid.UUID
+}
+
+func (err UnknownMatrixExecutionError) Error() string {
+	return fmt.Sprintf("unknown matrix execution %q", err.Id)
+}
+
+// Matrix associates axis names with the list of values each axis can take. A
+// job with a matrix is executed once per combination of values.
+type Matrix map[string][]interface{}
+
+type MatrixExecution struct {
+	Id            uuid.UUID          `json:"id"`
+	JobId         uuid.UUID          `json:"job_id"`
+	Status        JobExecutionStatus `json:"status"`
+	JobExecutions JobExecutions      `json:"job_executions"`
+}
+
+func (m Matrix) Check(v *ejson.Validator, token interface{}) {
+	v.WithChild(token, func() {
+		nbCombinations := 1
+
+		for _, name := range m.AxisNames() {
+			values := m[name]
+
+			CheckName(v, name, name)
+
+			v.WithChild(name, func() {
+				v.Check(ejson.Pointer{}, len(values) > 0, "empty_matrix_axis",
+					"matrix axis must contain at least one value")
+
+				for i, value := range values {
+					switch value.(type) {
+					case string, float64, bool:
+					default:
+						v.AddError(i, "invalid_matrix_value",
+							"matrix values must be strings, numbers or "+
+								"booleans")
+					}
+				}
+			})
+
+			nbCombinations *= len(values)
+		}
+
+		v.Check(ejson.Pointer{}, nbCombinations <= MaxMatrixCombinations,
+			"too_many_matrix_combinations",
+			"matrix cannot contain more than %d combinations",
+			MaxMatrixCombinations)
+	})
+}
+
+func (m Matrix) AxisNames() []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Combinations returns all combinations of axis values. Axes are iterated in
+// lexicographic order, and values in the order they were declared.
+func (m Matrix) Combinations() []map[string]interface{} {
+	combinations := []map[string]interface{}{{}}
+
+	for _, name := range m.AxisNames() {
+		var combinations2 []map[string]interface{}
+
+		for _, c := range combinations {
+			for _, value := range m[name] {
+				c2 := make(map[string]interface{}, len(c)+1)
+				for k, v := range c {
+					c2[k] = v
+				}
+
+				c2[name] = value
+
+				combinations2 = append(combinations2, c2)
+			}
+		}
+
+		combinations = combinations2
+	}
+
+	return combinations
+}
+
+// MatrixExecutionStatus aggregates the status of the executions of a matrix:
+// a matrix execution is running as long as at least one execution is not
+// finished; once all are finished, it fails if at least one failed.
+func MatrixExecutionStatus(jes JobExecutions) JobExecutionStatus {
+	var started, created, failed, aborted bool
+
+	for _, je := range jes {
+		switch je.Status {
+		case JobExecutionStatusCreated:
+			created = true
+		case JobExecutionStatusStarted:
+			started = true
+		case JobExecutionStatusFailed:
+			failed = true
+		case JobExecutionStatusAborted:
+			aborted = true
+		}
+	}
+
+	switch {
+	case started:
+		return JobExecutionStatusStarted
+	case created:
+		return JobExecutionStatusCreated
+	case failed:
+		return JobExecutionStatusFailed
+	case aborted:
+		return JobExecutionStatusAborted
+	default:
+		return JobExecutionStatusSuccessful
+	}
+}
+
+func (me *MatrixExecution) Load(conn pg.Conn, id uuid.UUID, scope Scope) error {
+	var jes JobExecutions
+	if err := jes.LoadByMatrixId(conn, id, scope); err != nil {
+		return err
+	}
+
+	if len(jes) == 0 {
+		return &UnknownMatrixExecutionError{Id: id}
+	}
+
+	me.Id = id
+	me.JobId = jes[0].JobId
+	me.Status = MatrixExecutionStatus(jes)
+	me.JobExecutions = jes
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package eventline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.n16f.net/ejson"
+)
+
+func TestMatrixCombinations(t *testing.T) {
+	assert := assert.New(t)
+
+	m := Matrix{
+		"os":      {"linux", "freebsd"},
+		"version": {1.0, 2.0, 3.0},
+	}
+
+	combinations := m.Combinations()
+	if assert.Len(combinations, 6) {
+		assert.Equal(map[string]interface{}{"os": "linux", "version": 1.0},
+			combinations[0])
+		assert.Equal(map[string]interface{}{"os": "freebsd", "version": 3.0},
+			combinations[5])
+	}
+
+	v := ejson.NewValidator()
+	m.Check(v, "matrix")
+	assert.NoError(v.Error())
+
+	m["empty"] = []interface{}{}
+
+	v = ejson.NewValidator()
+	m.Check(v, "matrix")
+	assert.Error(v.Error())
+}
+
+func TestMatrixExecutionStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	jes := func(statuses ...JobExecutionStatus) JobExecutions {
+		var jes JobExecutions
+		for _, status := range statuses {
+			jes = append(jes, &JobExecution{Status: status})
+		}
+		return jes
+	}
+
+	assert.Equal(JobExecutionStatusStarted,
+		MatrixExecutionStatus(jes(JobExecutionStatusFailed,
+			JobExecutionStatusStarted)))
+	assert.Equal(JobExecutionStatusFailed,
+		MatrixExecutionStatus(jes(JobExecutionStatusSuccessful,
+			JobExecutionStatusAborted, JobExecutionStatusFailed)))
+	assert.Equal(JobExecutionStatusSuccessful,
+		MatrixExecutionStatus(jes(JobExecutionStatusSuccessful,
+			JobExecutionStatusSuccessful)))
+}
@@ -1,5 +1,7 @@
 package eventline
 
+import "net/url"
+
 const (
 	MinPageSize = 1
 	MaxPageSize = 100
@@ -13,6 +15,10 @@ type Page struct {
 	Elements []PageElement `json:"elements"`
 	Previous *Cursor       `json:"previous,omitempty"`
 	Next     *Cursor       `json:"next,omitempty"`
+
+	// Additional query parameters included in previous and next URIs, e.g.
+	// search filters.
+	Query url.Values `json:"-"`
 }
 
 func ReversePageElements(elts []PageElement) {
@@ -116,7 +122,7 @@ func (p *Page) PreviousURI() string {
 		return ""
 	}
 
-	return p.Previous.URL().String()
+	return p.cursorURI(p.Previous)
 }
 
 func (p *Page) NextURI() string {
@@ -124,5 +130,20 @@ func (p *Page) NextURI() string {
 		return ""
 	}
 
-	return p.Next.URL().String()
+	return p.cursorURI(p.Next)
+}
+
+func (p *Page) cursorURI(cursor *Cursor) string {
+	uri := cursor.URL()
+
+	if len(p.Query) > 0 {
+		query := uri.Query()
+		for name, values := range p.Query {
+			query[name] = values
+		}
+
+		uri.RawQuery = query.Encode()
+	}
+
+	return uri.String()
 }
@@ -5,6 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/exograd/eventline/pkg/utils"
 	"go.n16f.net/ejson"
@@ -22,23 +26,62 @@ const (
 	ParameterTypeInteger ParameterType = "integer"
 	ParameterTypeString  ParameterType = "string"
 	ParameterTypeBoolean ParameterType = "boolean"
+	ParameterTypeSecret  ParameterType = "secret"
+
+	ParameterTypeDate     ParameterType = "date"
+	ParameterTypeDatetime ParameterType = "datetime"
+	ParameterTypeJSON     ParameterType = "json"
+	ParameterTypeArray    ParameterType = "array"
+	ParameterTypeIdentity ParameterType = "identity"
+)
+
+const (
+	ParameterDateFormat = "2006-01-02"
+
+	// Datetime values without timezone, as sent by web browsers, are
+	// interpreted as UTC.
+	ParameterLocalDatetimeFormat = "2006-01-02T15:04:05"
 )
 
+// The value displayed instead of the value of secret parameters.
+const SecretParameterMask = "********"
+
 var ParameterTypeValues = []ParameterType{
 	ParameterTypeNumber,
 	ParameterTypeInteger,
 	ParameterTypeString,
 	ParameterTypeBoolean,
+	ParameterTypeSecret,
+	ParameterTypeDate,
+	ParameterTypeDatetime,
+	ParameterTypeJSON,
+	ParameterTypeArray,
+	ParameterTypeIdentity,
+}
+
+// The types of the elements of array parameters.
+var ParameterElementTypeValues = []ParameterType{
+	ParameterTypeNumber,
+	ParameterTypeInteger,
+	ParameterTypeString,
+	ParameterTypeBoolean,
 }
 
 type Parameter struct {
 	Name        string          `json:"name"`
 	Type        ParameterType   `json:"type"`
+	ElementType ParameterType   `json:"element_type,omitempty"`
 	Values      []string        `json:"values,omitempty"`
 	Default     interface{}     `json:"-"`
 	RawDefault  json.RawMessage `json:"default,omitempty"`
 	Description string          `json:"description,omitempty"`
 	Environment string          `json:"environment,omitempty"`
+
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	MinLength *int     `json:"min_length,omitempty"`
+	MaxLength *int     `json:"max_length,omitempty"`
 }
 
 type Parameters []*Parameter
@@ -80,14 +123,77 @@ func (pp *Parameter) UnmarshalJSON(data []byte) error {
 }
 
 func (p *Parameter) ValidateJSON(v *ejson.Validator) {
+	nbErrors := len(v.Errors)
+
 	CheckName(v, "name", p.Name)
 	v.CheckStringValue("type", p.Type, ParameterTypeValues)
 
-	if p.Type != ParameterTypeString {
+	if p.Type == ParameterTypeArray {
+		if v.CheckStringNotEmpty("element_type", string(p.ElementType)) {
+			v.CheckStringValue("element_type", p.ElementType,
+				ParameterElementTypeValues)
+		}
+	} else {
+		v.Check("element_type", p.ElementType == "", "unexpected_value",
+			"non-array parameters cannot have an element type")
+	}
+
+	// Constraints apply to the elements of arrays
+	valueType := p.ValueType()
+
+	if valueType != ParameterTypeString {
 		v.Check("values", len(p.Values) == 0, "unexpected_value",
 			"non-string parameters cannot have values")
 	}
 
+	if valueType == ParameterTypeNumber || valueType == ParameterTypeInteger {
+		if p.Min != nil && p.Max != nil {
+			v.Check("max", *p.Max >= *p.Min, "invalid_value",
+				"maximum value must be greater or equal to minimum value")
+		}
+	} else {
+		v.Check("min", p.Min == nil, "unexpected_value",
+			"non-numeric parameters cannot have a minimum value")
+		v.Check("max", p.Max == nil, "unexpected_value",
+			"non-numeric parameters cannot have a maximum value")
+	}
+
+	if valueType == ParameterTypeString {
+		if p.Pattern != "" {
+			_, err := p.patternRegexp()
+			v.Check("pattern", err == nil, "invalid_pattern",
+				"invalid regular expression: %v", err)
+		}
+
+		if p.MinLength != nil {
+			v.Check("min_length", *p.MinLength >= 0, "invalid_value",
+				"minimum length must be positive")
+		}
+
+		if p.MaxLength != nil {
+			v.Check("max_length", *p.MaxLength >= 0, "invalid_value",
+				"maximum length must be positive")
+
+			if p.MinLength != nil {
+				v.Check("max_length", *p.MaxLength >= *p.MinLength,
+					"invalid_value", "maximum length must be greater or "+
+						"equal to minimum length")
+			}
+		}
+	} else {
+		v.Check("pattern", p.Pattern == "", "unexpected_value",
+			"non-string parameters cannot have a pattern")
+		v.Check("min_length", p.MinLength == nil, "unexpected_value",
+			"non-string parameters cannot have a minimum length")
+		v.Check("max_length", p.MaxLength == nil, "unexpected_value",
+			"non-string parameters cannot have a maximum length")
+	}
+
+	if p.Type == ParameterTypeSecret {
+		v.Check("default", p.Default == nil, "unexpected_value",
+			"secret parameters cannot have a default value")
+	}
+
 	if p.Default != nil {
 		switch p.Type {
 		case ParameterTypeNumber:
@@ -121,7 +227,29 @@ func (p *Parameter) ValidateJSON(v *ejson.Validator) {
 			v.Check("default", isBool, "invalid_type",
 				"default value must be a boolean")
 		}
+
+		// Constraints and other types can only be checked if the
+		// parameter itself is valid.
+		if len(v.Errors) == nbErrors {
+			p.CheckValue(v, "default", p.Default)
+		}
+	}
+}
+
+// ValueType returns the type of the values the parameter constraints apply
+// to, i.e. the type of the elements for array parameters.
+func (p *Parameter) ValueType() ParameterType {
+	if p.Type == ParameterTypeArray {
+		return p.ElementType
 	}
+
+	return p.Type
+}
+
+func (p *Parameter) patternRegexp() (*regexp.Regexp, error) {
+	// The whole value must match the pattern, as for the pattern attribute
+	// of HTML input elements.
+	return regexp.Compile("^(?:" + p.Pattern + ")$")
 }
 
 func (ps Parameters) CheckValues(v *ejson.Validator, token string, values map[string]interface{}) {
@@ -161,6 +289,18 @@ func (p *Parameter) CheckValue(v *ejson.Validator, token string, value interface
 		return p.checkValueString(v, token, value)
 	case ParameterTypeBoolean:
 		return p.checkValueBoolean(v, token, value)
+	case ParameterTypeSecret:
+		return p.checkValueString(v, token, value)
+	case ParameterTypeDate:
+		return p.checkValueDate(v, token, value)
+	case ParameterTypeDatetime:
+		return p.checkValueDatetime(v, token, value)
+	case ParameterTypeJSON:
+		return value
+	case ParameterTypeArray:
+		return p.checkValueArray(v, token, value)
+	case ParameterTypeIdentity:
+		return p.checkValueIdentity(v, token, value)
 	default:
 		program.Panic("unhandled parameter type %v", p.Type)
 	}
@@ -176,8 +316,10 @@ func (p *Parameter) checkValueNumber(v *ejson.Validator, token string, value int
 	}
 
 	if f64, err := number.Float64(); err == nil {
+		p.checkRange(v, token, f64)
 		return f64
 	} else if i64, err := number.Int64(); err == nil {
+		p.checkRange(v, token, float64(i64))
 		return i64
 	}
 
@@ -200,9 +342,23 @@ func (p *Parameter) checkValueInteger(v *ejson.Validator, token string, value in
 		return nil
 	}
 
+	p.checkRange(v, token, float64(i64))
+
 	return i64
 }
 
+func (p *Parameter) checkRange(v *ejson.Validator, token string, value float64) {
+	if p.Min != nil {
+		v.Check(token, value >= *p.Min, "value_too_small",
+			"value must be greater or equal to %v", *p.Min)
+	}
+
+	if p.Max != nil {
+		v.Check(token, value <= *p.Max, "value_too_large",
+			"value must be lower or equal to %v", *p.Max)
+	}
+}
+
 func (p *Parameter) checkValueString(v *ejson.Validator, token string, value interface{}) interface{} {
 	s, ok := value.(string)
 	v.Check(token, ok, "invalid_string", "value is not a string")
@@ -211,9 +367,128 @@ func (p *Parameter) checkValueString(v *ejson.Validator, token string, value int
 		v.CheckStringValue(token, s, p.Values)
 	}
 
+	length := utf8.RuneCountInString(s)
+
+	if p.MinLength != nil {
+		v.Check(token, length >= *p.MinLength, "string_too_short",
+			"string must contain at least %d characters", *p.MinLength)
+	}
+
+	if p.MaxLength != nil {
+		v.Check(token, length <= *p.MaxLength, "string_too_long",
+			"string must contain at most %d characters", *p.MaxLength)
+	}
+
+	if p.Pattern != "" {
+		if re, err := p.patternRegexp(); err == nil {
+			v.Check(token, re.MatchString(s), "invalid_format",
+				"string does not match pattern %q", p.Pattern)
+		}
+	}
+
+	return s
+}
+
+func (p *Parameter) checkValueDate(v *ejson.Validator, token string, value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		v.AddError(token, "invalid_date", "value is not a string")
+		return nil
+	}
+
+	_, err := time.Parse(ParameterDateFormat, s)
+	v.Check(token, err == nil, "invalid_date",
+		"value is not a date in the YYYY-MM-DD format")
+
+	return s
+}
+
+func (p *Parameter) checkValueDatetime(v *ejson.Validator, token string, value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		v.AddError(token, "invalid_datetime", "value is not a string")
+		return nil
+	}
+
+	t, err := ParseParameterDatetime(s)
+	if err != nil {
+		v.AddError(token, "invalid_datetime",
+			"value is not an RFC 3339 datetime")
+		return s
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// ParseParameterDatetime parses the value of a datetime parameter. Values are
+// RFC 3339 datetimes; the timezone can be omitted, in which case it is UTC.
+func ParseParameterDatetime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		var err2 error
+
+		// Seconds are optional in the value of datetime-local inputs
+		t, err2 = time.Parse(ParameterLocalDatetimeFormat, s)
+		if err2 != nil {
+			t, err2 = time.Parse("2006-01-02T15:04", s)
+		}
+
+		if err2 != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return t.UTC(), nil
+}
+
+func (p *Parameter) checkValueArray(v *ejson.Validator, token string, value interface{}) interface{} {
+	elements, ok := value.([]interface{})
+	if !ok {
+		v.AddError(token, "invalid_array", "value is not an array")
+		return nil
+	}
+
+	elementParameter := p.ElementParameter()
+
+	values := make([]interface{}, len(elements))
+
+	v.WithChild(token, func() {
+		for i, element := range elements {
+			values[i] = elementParameter.CheckValue(v, strconv.Itoa(i),
+				element)
+		}
+	})
+
+	return values
+}
+
+func (p *Parameter) checkValueIdentity(v *ejson.Validator, token string, value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		v.AddError(token, "invalid_identity", "value is not a string")
+		return nil
+	}
+
+	v.Check(token, s != "", "invalid_identity", "empty identity name")
+
 	return s
 }
 
+// ElementParameter returns a parameter representing the elements of an array
+// parameter, with the same constraints.
+func (p *Parameter) ElementParameter() *Parameter {
+	return &Parameter{
+		Name:      p.Name,
+		Type:      p.ElementType,
+		Values:    p.Values,
+		Min:       p.Min,
+		Max:       p.Max,
+		Pattern:   p.Pattern,
+		MinLength: p.MinLength,
+		MaxLength: p.MaxLength,
+	}
+}
+
 func (p *Parameter) checkValueBoolean(v *ejson.Validator, token string, value interface{}) interface{} {
 	b, ok := value.(bool)
 	v.Check(token, ok, "invalid_boolean", "value is not a boolean")
@@ -221,6 +496,87 @@ func (p *Parameter) checkValueBoolean(v *ejson.Validator, token string, value in
 	return b
 }
 
+// IdentityNames returns the names of the identities referenced by identity
+// parameters in a set of values.
+func (ps Parameters) IdentityNames(values map[string]interface{}) []string {
+	var names []string
+
+	for _, p := range ps {
+		if p.Type != ParameterTypeIdentity {
+			continue
+		}
+
+		if name, ok := values[p.Name].(string); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// DefaultIdentityNames returns the names of the identities used as default
+// values of identity parameters.
+func (ps Parameters) DefaultIdentityNames() []string {
+	var names []string
+
+	for _, p := range ps {
+		if p.Type != ParameterTypeIdentity {
+			continue
+		}
+
+		if name, ok := p.Default.(string); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// CheckRestartValues updates the parameter values of a job execution being
+// restarted. Secret values are never reused and must be provided again in
+// inputValues, which cannot contain any other parameter.
+func (ps Parameters) CheckRestartValues(v *ejson.Validator, token string, values, inputValues map[string]interface{}) {
+	for _, name := range ps.SecretNames() {
+		delete(values, name)
+	}
+
+	v.WithChild(token, func() {
+		for name, value := range inputValues {
+			param := ps.Parameter(name)
+			if param == nil || param.Type != ParameterTypeSecret {
+				v.AddError(name, "unexpected_parameter",
+					"only secret parameters can be provided when restarting "+
+						"a job execution")
+				continue
+			}
+
+			values[name] = param.CheckValue(v, name, value)
+		}
+	})
+
+	// Secret parameters cannot have a default value, so they are all
+	// mandatory.
+	for _, name := range ps.SecretNames() {
+		if _, found := inputValues[name]; !found {
+			v.AddError(token, "missing_parameter", "missing parameter %q",
+				name)
+		}
+	}
+}
+
+// SecretNames returns the names of all secret parameters.
+func (ps Parameters) SecretNames() []string {
+	var names []string
+
+	for _, p := range ps {
+		if p.Type == ParameterTypeSecret {
+			names = append(names, p.Name)
+		}
+	}
+
+	return names
+}
+
 func (ps Parameters) Parameter(name string) *Parameter {
 	for _, p := range ps {
 		if p.Name == name {
@@ -237,6 +593,53 @@ func (p *Parameter) Label() string {
 	return utils.Capitalize(label)
 }
 
+// InputValue returns the default value of the parameter formatted for the
+// input element of the execution form.
+func (p *Parameter) InputValue() string {
+	// Used in the form template
+	if p.Default == nil {
+		return ""
+	}
+
+	switch p.Type {
+	case ParameterTypeDatetime:
+		s, _ := p.Default.(string)
+		if t, err := ParseParameterDatetime(s); err == nil {
+			return t.Format(ParameterLocalDatetimeFormat)
+		}
+
+		return s
+
+	case ParameterTypeJSON:
+		data, err := json.MarshalIndent(p.Default, "", "  ")
+		if err != nil {
+			return ""
+		}
+
+		return string(data)
+
+	case ParameterTypeArray:
+		return strings.Join(p.DefaultStrings(), ", ")
+
+	default:
+		return fmt.Sprintf("%v", p.Default)
+	}
+}
+
+// DefaultStrings returns the elements of the default value of an array
+// parameter as strings.
+func (p *Parameter) DefaultStrings() []string {
+	// Used in the form template
+	elements, _ := p.Default.([]interface{})
+
+	ss := make([]string, len(elements))
+	for i, element := range elements {
+		ss[i] = fmt.Sprintf("%v", element)
+	}
+
+	return ss
+}
+
 func (p *Parameter) ValueString(value interface{}) (s string) {
 	switch p.Type {
 	case ParameterTypeNumber:
@@ -245,9 +648,20 @@ func (p *Parameter) ValueString(value interface{}) (s string) {
 	case ParameterTypeInteger:
 		return fmt.Sprintf("%v", value)
 
-	case ParameterTypeString:
+	case ParameterTypeString, ParameterTypeSecret:
 		return value.(string)
 
+	case ParameterTypeDate, ParameterTypeDatetime, ParameterTypeIdentity:
+		return value.(string)
+
+	case ParameterTypeJSON, ParameterTypeArray:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprintf("%v", value)
+		}
+
+		return string(data)
+
 	case ParameterTypeBoolean:
 		if value.(bool) == true {
 			return "true"
@@ -0,0 +1,218 @@
+package eventline
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.n16f.net/ejson"
+)
+
+func TestParameterValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		data  string
+		valid bool
+	}{
+		{`{"name": "a", "type": "integer", "min": 1, "max": 10}`, true},
+		{`{"name": "a", "type": "integer", "min": 10, "max": 1}`, false},
+		{`{"name": "a", "type": "string", "min": 1}`, false},
+		{`{"name": "a", "type": "string", "pattern": "[a-z]+"}`, true},
+		{`{"name": "a", "type": "string", "pattern": "[a-z"}`, false},
+		{`{"name": "a", "type": "number", "pattern": "[a-z]+"}`, false},
+		{`{"name": "a", "type": "string", "min_length": 3, "max_length": 2}`,
+			false},
+		{`{"name": "a", "type": "string", "max_length": 2, "default": "abc"}`,
+			false},
+		{`{"name": "a", "type": "array"}`, false},
+		{`{"name": "a", "type": "array", "element_type": "json"}`, false},
+		{`{"name": "a", "type": "array", "element_type": "integer",
+           "max": 5, "default": [1, 2]}`, true},
+		{`{"name": "a", "type": "array", "element_type": "integer",
+           "max": 5, "default": [1, 6]}`, false},
+		{`{"name": "a", "type": "array", "element_type": "string",
+           "values": ["x", "y"]}`, true},
+		{`{"name": "a", "type": "string", "element_type": "string"}`, false},
+		{`{"name": "a", "type": "date", "default": "2026-10-19"}`, true},
+		{`{"name": "a", "type": "date", "default": "19/10/2026"}`, false},
+		{`{"name": "a", "type": "datetime",
+           "default": "2026-10-19T10:00:00Z"}`, true},
+		{`{"name": "a", "type": "json", "default": {"a": [1, null]}}`, true},
+		{`{"name": "a", "type": "identity", "default": "github"}`, true},
+	}
+
+	for _, test := range tests {
+		var p Parameter
+		if !assert.NoError(json.Unmarshal([]byte(test.data), &p)) {
+			continue
+		}
+
+		v := ejson.NewValidator()
+		p.ValidateJSON(v)
+
+		if test.valid {
+			assert.NoError(v.Error(), test.data)
+		} else {
+			assert.Error(v.Error(), test.data)
+		}
+	}
+}
+
+func TestParametersDefaultValidation(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// The default value of each parameter is checked even if another
+	// parameter is invalid.
+	var spec JobSpec
+	err := json.Unmarshal([]byte(`{
+  "name": "foo",
+  "parameters": [
+    {"name": "a", "type": "integer", "min": 10, "max": 1},
+    {"name": "b", "type": "array", "element_type": "integer",
+     "max": 5, "default": [1, 6]}
+  ]
+}`), &spec)
+	require.NoError(err)
+
+	v := ejson.NewValidator()
+	spec.ValidateJSON(v)
+
+	if assert.Len(v.Errors, 2) {
+		assert.Equal("/parameters/0/max", v.Errors[0].Pointer.String())
+		assert.Equal("/parameters/1/default/1", v.Errors[1].Pointer.String())
+	}
+}
+
+func TestParameterCheckValue(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var ps Parameters
+	err := json.Unmarshal([]byte(`[
+  {"name": "count", "type": "integer", "min": 1, "max": 10},
+  {"name": "version", "type": "string", "pattern": "v[0-9]+"},
+  {"name": "day", "type": "date"},
+  {"name": "start", "type": "datetime"},
+  {"name": "options", "type": "json"},
+  {"name": "ports", "type": "array", "element_type": "integer", "min": 1},
+  {"name": "identity", "type": "identity"}
+]`), &ps)
+	require.NoError(err)
+
+	check := func(name string, value interface{}) (interface{}, error) {
+		v := ejson.NewValidator()
+		value = ps.Parameter(name).CheckValue(v, name, value)
+		return value, v.Error()
+	}
+
+	_, err = check("count", json.Number("5"))
+	assert.NoError(err)
+	_, err = check("count", json.Number("11"))
+	assert.Error(err)
+
+	_, err = check("version", "v12")
+	assert.NoError(err)
+	_, err = check("version", "version v12")
+	assert.Error(err)
+
+	_, err = check("day", "2026-10-19")
+	assert.NoError(err)
+	_, err = check("day", "2026-10-19T10:00:00Z")
+	assert.Error(err)
+
+	value, err := check("start", "2026-10-19T12:00:00+02:00")
+	assert.NoError(err)
+	assert.Equal("2026-10-19T10:00:00Z", value)
+
+	value, err = check("start", "2026-10-19T10:00")
+	assert.NoError(err)
+	assert.Equal("2026-10-19T10:00:00Z", value)
+
+	_, err = check("options", map[string]interface{}{"a": true})
+	assert.NoError(err)
+
+	value, err = check("ports",
+		[]interface{}{json.Number("80"), json.Number("443")})
+	assert.NoError(err)
+	assert.Equal([]interface{}{int64(80), int64(443)}, value)
+	_, err = check("ports", []interface{}{json.Number("0")})
+	assert.Error(err)
+	_, err = check("ports", json.Number("80"))
+	assert.Error(err)
+
+	_, err = check("identity", "github")
+	assert.NoError(err)
+
+	assert.Equal([]string{"github"},
+		ps.IdentityNames(map[string]interface{}{"identity": "github"}))
+
+	assert.Equal(`[80,443]`, ps.Parameter("ports").ValueString(
+		[]interface{}{int64(80), int64(443)}))
+}
+
+func TestParametersCheckRestartValues(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var ps Parameters
+	err := json.Unmarshal([]byte(`[
+  {"name": "count", "type": "integer"},
+  {"name": "token", "type": "secret"},
+  {"name": "password", "type": "secret"}
+]`), &ps)
+	require.NoError(err)
+
+	check := func(inputValues map[string]interface{}) (map[string]interface{}, ejson.ValidationErrors) {
+		values := map[string]interface{}{
+			"count":    int64(3),
+			"token":    "abc",
+			"password": "def",
+		}
+
+		v := ejson.NewValidator()
+		ps.CheckRestartValues(v, "parameters", values, inputValues)
+		return values, v.Errors
+	}
+
+	// Secret values are replaced by the new ones
+	values, errs := check(map[string]interface{}{
+		"token":    "ghi",
+		"password": "jkl",
+	})
+	assert.Empty(errs)
+	assert.Equal(map[string]interface{}{
+		"count":    int64(3),
+		"token":    "ghi",
+		"password": "jkl",
+	}, values)
+
+	// Each missing secret parameter is reported
+	values, errs = check(nil)
+	if assert.Len(errs, 2) {
+		for _, err := range errs {
+			assert.Equal("missing_parameter", err.Code)
+			assert.Equal("/parameters", err.Pointer.String())
+		}
+	}
+	assert.Equal(map[string]interface{}{"count": int64(3)}, values)
+
+	_, errs = check(map[string]interface{}{"token": "ghi"})
+	if assert.Len(errs, 1) {
+		assert.Equal("missing_parameter", errs[0].Code)
+		assert.Contains(errs[0].Message, `"password"`)
+	}
+
+	// Other parameters cannot be provided
+	_, errs = check(map[string]interface{}{
+		"token":    "ghi",
+		"password": "jkl",
+		"count":    json.Number("4"),
+	})
+	if assert.Len(errs, 1) {
+		assert.Equal("unexpected_parameter", errs[0].Code)
+		assert.Equal("/parameters/count", errs[0].Pointer.String())
+	}
+}
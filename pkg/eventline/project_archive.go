@@ -0,0 +1,333 @@
+package eventline
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exograd/eventline/pkg/cryptoutils"
+	"go.n16f.net/ejson"
+)
+
+const ProjectArchiveVersion = 1
+
+// Encrypted with the key derived from the passphrase to detect invalid
+// passphrases before decrypting identity data.
+const projectArchiveSecretCheck = "eventline"
+
+var ErrInvalidPassphrase = errors.New("invalid passphrase")
+
+type ProjectImportConflictMode string
+
+const (
+	ProjectImportConflictModeFail      ProjectImportConflictMode = "fail"
+	ProjectImportConflictModeSkip      ProjectImportConflictMode = "skip"
+	ProjectImportConflictModeOverwrite ProjectImportConflictMode = "overwrite"
+)
+
+var ProjectImportConflictModeValues = []ProjectImportConflictMode{
+	ProjectImportConflictModeFail,
+	ProjectImportConflictModeSkip,
+	ProjectImportConflictModeOverwrite,
+}
+
+// ProjectArchive contains the content of a project so that it can be imported
+// in another project, possibly on another Eventline instance. Identity data
+// and the values of secret project variables are only included if the archive
+// was exported with a passphrase; they are then encrypted with a key derived
+// from this passphrase.
+type ProjectArchive struct {
+	Version                     int                          `json:"version"`
+	CreationTime                time.Time                    `json:"creation_time"`
+	ProjectName                 string                       `json:"project_name"`
+	ProjectSettings             *ProjectSettings             `json:"project_settings"`
+	ProjectNotificationSettings *ProjectNotificationSettings `json:"project_notification_settings"`
+	Jobs                        JobSpecs                     `json:"jobs"`
+	StepTemplates               []*StepTemplateSpec          `json:"step_templates,omitempty"`
+	Identities                  ProjectArchiveIdentities     `json:"identities"`
+	Variables                   ProjectArchiveVariables      `json:"variables,omitempty"`
+	SecretSalt                  string                       `json:"secret_salt,omitempty"`  // base64
+	SecretCheck                 string                       `json:"secret_check,omitempty"` // base64
+}
+
+type ProjectArchiveIdentity struct {
+	Name          string         `json:"name"`
+	Connector     string         `json:"connector"`
+	Type          string         `json:"type"`
+	Status        IdentityStatus `json:"status"`
+	EncryptedData string         `json:"encrypted_data,omitempty"` // base64
+}
+
+type ProjectArchiveIdentities []*ProjectArchiveIdentity
+
+type ProjectArchiveVariable struct {
+	Name           string `json:"name"`
+	Secret         bool   `json:"secret,omitempty"`
+	Value          string `json:"value,omitempty"`
+	EncryptedValue string `json:"encrypted_value,omitempty"` // base64
+}
+
+type ProjectArchiveVariables []*ProjectArchiveVariable
+
+type ProjectExportOptions struct {
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+type ProjectImportOptions struct {
+	Archive    *ProjectArchive           `json:"archive"`
+	Passphrase string                    `json:"passphrase,omitempty"`
+	OnConflict ProjectImportConflictMode `json:"on_conflict,omitempty"`
+}
+
+func (a *ProjectArchive) ValidateJSON(v *ejson.Validator) {
+	v.Check("version", a.Version == ProjectArchiveVersion,
+		"unsupported_version", "unsupported archive version %d", a.Version)
+
+	v.CheckObject("project_settings", a.ProjectSettings)
+
+	v.CheckObjectArray("jobs", a.Jobs)
+	v.WithChild("jobs", func() {
+		names := make(map[string]struct{})
+
+		for i, job := range a.Jobs {
+			if _, found := names[job.Name]; found {
+				v.AddError(i, "duplicate_job_name",
+					"duplicate job name %q", job.Name)
+			}
+
+			names[job.Name] = struct{}{}
+		}
+	})
+
+	v.CheckObjectArray("step_templates", a.StepTemplates)
+	v.WithChild("step_templates", func() {
+		names := make(map[string]struct{})
+
+		for i, template := range a.StepTemplates {
+			if _, found := names[template.Name]; found {
+				v.AddError(i, "duplicate_step_template_name",
+					"duplicate step template name %q", template.Name)
+			}
+
+			names[template.Name] = struct{}{}
+		}
+	})
+
+	v.CheckObjectArray("identities", a.Identities)
+	v.WithChild("identities", func() {
+		names := make(map[string]struct{})
+
+		for i, identity := range a.Identities {
+			if _, found := names[identity.Name]; found {
+				v.AddError(i, "duplicate_identity_name",
+					"duplicate identity name %q", identity.Name)
+			}
+
+			names[identity.Name] = struct{}{}
+		}
+	})
+
+	v.CheckObjectArray("variables", a.Variables)
+	v.WithChild("variables", func() {
+		names := make(map[string]struct{})
+
+		for i, variable := range a.Variables {
+			if _, found := names[variable.Name]; found {
+				v.AddError(i, "duplicate_variable_name",
+					"duplicate variable name %q", variable.Name)
+			}
+
+			names[variable.Name] = struct{}{}
+		}
+	})
+}
+
+func (av *ProjectArchiveVariable) ValidateJSON(v *ejson.Validator) {
+	nv := NewProjectVariable{Name: av.Name}
+	nv.ValidateJSON(v)
+
+	if !av.Secret {
+		v.Check("encrypted_value", av.EncryptedValue == "",
+			"unexpected_value", "only secret variables can have an "+
+				"encrypted value")
+	}
+}
+
+func (i *ProjectArchiveIdentity) ValidateJSON(v *ejson.Validator) {
+	CheckName(v, "name", i.Name)
+
+	if CheckConnectorName(v, "connector", i.Connector) {
+		CheckIdentityName(v, "type", i.Connector, i.Type)
+	}
+
+	v.CheckStringValue("status", i.Status, IdentityStatusValues)
+}
+
+func (o *ProjectImportOptions) ValidateJSON(v *ejson.Validator) {
+	v.CheckObject("archive", o.Archive)
+
+	if o.OnConflict != "" {
+		v.CheckStringValue("on_conflict", o.OnConflict,
+			ProjectImportConflictModeValues)
+	}
+}
+
+// HasSecrets indicates whether the archive contains identity data.
+func (a *ProjectArchive) HasSecrets() bool {
+	return a.SecretSalt != ""
+}
+
+// InitSecrets generates the salt used to derive the encryption key from the
+// passphrase and returns this key.
+func (a *ProjectArchive) InitSecrets(passphrase string) (cryptoutils.AES256Key, error) {
+	salt := GenerateSalt()
+	key := projectArchiveKey(passphrase, salt)
+
+	check, err := cryptoutils.EncryptAES256([]byte(projectArchiveSecretCheck),
+		key)
+	if err != nil {
+		return key, err
+	}
+
+	a.SecretSalt = base64.StdEncoding.EncodeToString(salt)
+	a.SecretCheck = base64.StdEncoding.EncodeToString(check)
+
+	return key, nil
+}
+
+// SecretKey returns the key used to encrypt identity data. It returns
+// ErrInvalidPassphrase if the passphrase is not the one used during export.
+func (a *ProjectArchive) SecretKey(passphrase string) (cryptoutils.AES256Key, error) {
+	var key cryptoutils.AES256Key
+
+	salt, err := base64.StdEncoding.DecodeString(a.SecretSalt)
+	if err != nil {
+		return key, fmt.Errorf("invalid secret salt: %w", err)
+	}
+
+	encryptedCheck, err := base64.StdEncoding.DecodeString(a.SecretCheck)
+	if err != nil {
+		return key, fmt.Errorf("invalid secret check: %w", err)
+	}
+
+	key = projectArchiveKey(passphrase, salt)
+
+	check, err := cryptoutils.DecryptAES256(encryptedCheck, key)
+	if err != nil || string(check) != projectArchiveSecretCheck {
+		return key, ErrInvalidPassphrase
+	}
+
+	return key, nil
+}
+
+func projectArchiveKey(passphrase string, salt []byte) cryptoutils.AES256Key {
+	var key cryptoutils.AES256Key
+	copy(key[:], HashPassword(passphrase, salt))
+	return key
+}
+
+func NewProjectArchiveIdentity(identity *Identity, key *cryptoutils.AES256Key) (*ProjectArchiveIdentity, error) {
+	ai := ProjectArchiveIdentity{
+		Name:      identity.Name,
+		Connector: identity.Connector,
+		Type:      identity.Type,
+		Status:    identity.Status,
+	}
+
+	if key != nil {
+		data, err := json.Marshal(identity.Data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode data: %w", err)
+		}
+
+		ai.EncryptedData, err = encryptArchiveSecret(data, *key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encrypt data: %w", err)
+		}
+	}
+
+	return &ai, nil
+}
+
+// Data decrypts and decodes the data of the identity. It returns nil if the
+// archive does not contain identity data.
+func (ai *ProjectArchiveIdentity) Data(key *cryptoutils.AES256Key) (IdentityData, error) {
+	if ai.EncryptedData == "" || key == nil {
+		return nil, nil
+	}
+
+	data, err := decryptArchiveSecret(ai.EncryptedData, *key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt data: %w", err)
+	}
+
+	idef := GetConnectorDef(ai.Connector).Identity(ai.Type)
+
+	idata, err := idef.DecodeData(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode data: %w", err)
+	}
+
+	return idata, nil
+}
+
+// NewProjectArchiveVariable exports a project variable. The value of secret
+// variables is only included, encrypted, if a key is provided.
+func NewProjectArchiveVariable(variable *ProjectVariable, key *cryptoutils.AES256Key) (*ProjectArchiveVariable, error) {
+	av := ProjectArchiveVariable{
+		Name:   variable.Name,
+		Secret: variable.Secret,
+	}
+
+	if !variable.Secret {
+		av.Value = variable.Value
+	} else if key != nil {
+		var err error
+		av.EncryptedValue, err = encryptArchiveSecret([]byte(variable.Value),
+			*key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encrypt value: %w", err)
+		}
+	}
+
+	return &av, nil
+}
+
+// ProjectVariableValue returns the value of the variable. It returns false if
+// the variable is secret and the archive does not contain its value.
+func (av *ProjectArchiveVariable) ProjectVariableValue(key *cryptoutils.AES256Key) (string, bool, error) {
+	if !av.Secret {
+		return av.Value, true, nil
+	}
+
+	if av.EncryptedValue == "" || key == nil {
+		return "", false, nil
+	}
+
+	value, err := decryptArchiveSecret(av.EncryptedValue, *key)
+	if err != nil {
+		return "", false, fmt.Errorf("cannot decrypt value: %w", err)
+	}
+
+	return string(value), true, nil
+}
+
+func encryptArchiveSecret(data []byte, key cryptoutils.AES256Key) (string, error) {
+	encryptedData, err := cryptoutils.EncryptAES256(data, key)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(encryptedData), nil
+}
+
+func decryptArchiveSecret(s string, key cryptoutils.AES256Key) ([]byte, error) {
+	encryptedData, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 data: %w", err)
+	}
+
+	return cryptoutils.DecryptAES256(encryptedData, key)
+}
@@ -0,0 +1,104 @@
+package eventline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.n16f.net/ejson"
+)
+
+func TestProjectArchiveSecrets(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var archive ProjectArchive
+	assert.False(archive.HasSecrets())
+
+	key, err := archive.InitSecrets("foo bar")
+	require.NoError(err)
+	assert.True(archive.HasSecrets())
+
+	key2, err := archive.SecretKey("foo bar")
+	require.NoError(err)
+	assert.Equal(key, key2)
+
+	_, err = archive.SecretKey("foo baz")
+	assert.ErrorIs(err, ErrInvalidPassphrase)
+
+	_, err = archive.SecretKey("")
+	assert.ErrorIs(err, ErrInvalidPassphrase)
+}
+
+func TestProjectArchiveVariables(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var archive ProjectArchive
+	key, err := archive.InitSecrets("foo bar")
+	require.NoError(err)
+
+	// Plain variables are exported as-is
+	av, err := NewProjectArchiveVariable(&ProjectVariable{
+		Name:  "FOO",
+		Value: "foo",
+	}, nil)
+	require.NoError(err)
+	assert.Equal("foo", av.Value)
+
+	value, available, err := av.ProjectVariableValue(nil)
+	require.NoError(err)
+	assert.True(available)
+	assert.Equal("foo", value)
+
+	// Secret variables are only exported with a key
+	secret := ProjectVariable{Name: "TOKEN", Value: "secret", Secret: true}
+
+	av, err = NewProjectArchiveVariable(&secret, nil)
+	require.NoError(err)
+	assert.Equal("", av.Value)
+	assert.Equal("", av.EncryptedValue)
+
+	_, available, err = av.ProjectVariableValue(&key)
+	require.NoError(err)
+	assert.False(available)
+
+	av, err = NewProjectArchiveVariable(&secret, &key)
+	require.NoError(err)
+	assert.Equal("", av.Value)
+	assert.NotEqual("", av.EncryptedValue)
+
+	_, available, err = av.ProjectVariableValue(nil)
+	require.NoError(err)
+	assert.False(available)
+
+	value, available, err = av.ProjectVariableValue(&key)
+	require.NoError(err)
+	assert.True(available)
+	assert.Equal("secret", value)
+}
+
+func TestProjectArchiveIdentityValidateJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	identity := ProjectArchiveIdentity{
+		Name:   "foo",
+		Status: "active",
+	}
+
+	v := ejson.NewValidator()
+	identity.ValidateJSON(v)
+
+	var statusErr *ejson.ValidationError
+
+	var verrs ejson.ValidationErrors
+	if assert.ErrorAs(v.Error(), &verrs) {
+		for _, verr := range verrs {
+			if verr.Pointer.String() == "/status" {
+				statusErr = verr
+			}
+		}
+	}
+
+	assert.NotNil(statusErr)
+}
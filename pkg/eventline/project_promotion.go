@@ -0,0 +1,180 @@
+package eventline
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/exograd/eventline/pkg/utils"
+	"go.n16f.net/ejson"
+	"go.n16f.net/uuid"
+)
+
+type ProjectCloningData struct {
+	Name string `json:"name"`
+}
+
+// JobOverrides contains modifications applied to job specifications when
+// they are promoted from a project to another one.
+type JobOverrides struct {
+	// Identity names in the source project associated with identity names in
+	// the target project.
+	Identities map[string]string `json:"identities,omitempty"`
+
+	// Environment variables added to the job, replacing existing ones.
+	Environment map[string]string `json:"environment,omitempty"`
+
+	// Runner parameters replacing existing ones. The new set of parameters
+	// must be valid for the runner used by the job.
+	RunnerParameters map[string]interface{} `json:"runner_parameters,omitempty"`
+}
+
+// ProjectPromotionMapping describes how jobs are modified when promoted.
+// Overrides at the top level apply to all jobs; job-specific overrides are
+// applied afterward.
+type ProjectPromotionMapping struct {
+	JobOverrides
+
+	Jobs map[string]*JobOverrides `json:"jobs,omitempty"`
+}
+
+type JobPromotionData struct {
+	SourceProjectId uuid.UUID                `json:"source_project_id"`
+	JobNames        []string                 `json:"jobs,omitempty"`
+	Mapping         *ProjectPromotionMapping `json:"mapping,omitempty"`
+}
+
+func (data *ProjectCloningData) ValidateJSON(v *ejson.Validator) {
+	CheckName(v, "name", data.Name)
+}
+
+func (o *JobOverrides) ValidateJSON(v *ejson.Validator) {
+	v.WithChild("identities", func() {
+		for _, name := range utils.SortedKeys(o.Identities) {
+			CheckName(v, name, o.Identities[name])
+		}
+	})
+}
+
+func (m *ProjectPromotionMapping) ValidateJSON(v *ejson.Validator) {
+	m.JobOverrides.ValidateJSON(v)
+
+	v.CheckObjectMap("jobs", m.Jobs)
+}
+
+func (m *ProjectPromotionMapping) ParseYAML(data []byte) error {
+	return decodeYAMLDocument(data, m)
+}
+
+func (data *JobPromotionData) ValidateJSON(v *ejson.Validator) {
+	v.WithChild("jobs", func() {
+		for i, name := range data.JobNames {
+			CheckName(v, i, name)
+		}
+	})
+
+	v.CheckOptionalObject("mapping", data.Mapping)
+}
+
+// Apply modifies a job specification according to the mapping.
+func (m *ProjectPromotionMapping) Apply(spec *JobSpec) error {
+	if err := m.JobOverrides.Apply(spec); err != nil {
+		return err
+	}
+
+	if o := m.Jobs[spec.Name]; o != nil {
+		if err := o.Apply(spec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Apply modifies a job specification according to the overrides.
+func (o *JobOverrides) Apply(spec *JobSpec) error {
+	renameIdentity := func(name string) string {
+		if name2, found := o.Identities[name]; found {
+			return name2
+		}
+
+		return name
+	}
+
+	if spec.Trigger != nil && spec.Trigger.Identity != "" {
+		spec.Trigger.Identity = renameIdentity(spec.Trigger.Identity)
+	}
+
+	if spec.Runner != nil && spec.Runner.Identity != "" {
+		spec.Runner.Identity = renameIdentity(spec.Runner.Identity)
+	}
+
+	for i, name := range spec.Identities {
+		spec.Identities[i] = renameIdentity(name)
+	}
+
+	if len(o.Environment) > 0 {
+		if spec.Environment == nil {
+			spec.Environment = make(map[string]string)
+		}
+
+		for name, value := range o.Environment {
+			spec.Environment[name] = value
+		}
+	}
+
+	if len(o.RunnerParameters) > 0 {
+		if spec.Runner == nil {
+			return fmt.Errorf("cannot override runner parameters of a job " +
+				"without runner")
+		}
+
+		runner, err := spec.Runner.WithParameters(o.RunnerParameters)
+		if err != nil {
+			return err
+		}
+
+		spec.Runner = runner
+	}
+
+	return nil
+}
+
+// WithParameters returns a copy of the runner where parameters have been
+// replaced by a set of values. Parameters are decoded again so that invalid
+// parameters are detected.
+func (r *JobRunner) WithParameters(values map[string]interface{}) (*JobRunner, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode runner: %w", err)
+	}
+
+	var value struct {
+		Name       string                 `json:"name"`
+		Parameters map[string]interface{} `json:"parameters"`
+		Identity   string                 `json:"identity,omitempty"`
+	}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("cannot decode runner: %w", err)
+	}
+
+	if value.Parameters == nil {
+		value.Parameters = make(map[string]interface{})
+	}
+
+	for name, parameterValue := range values {
+		value.Parameters[name] = parameterValue
+	}
+
+	data, err = json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode runner: %w", err)
+	}
+
+	var r2 JobRunner
+	if err := json.Unmarshal(data, &r2); err != nil {
+		return nil, err
+	}
+
+	return &r2, nil
+}
@@ -0,0 +1,72 @@
+package eventline
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectPromotionMappingApply(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mappingData := `
+identities:
+  staging-aws: production-aws
+  staging-ssh: production-ssh
+environment:
+  ENV: production
+runner_parameters:
+  host: production.example.com
+jobs:
+  deploy:
+    environment:
+      REPLICAS: "3"
+    runner_parameters:
+      port: 2222
+`
+
+	var mapping ProjectPromotionMapping
+	require.NoError(mapping.ParseYAML([]byte(mappingData)))
+
+	newSpec := func(name string) *JobSpec {
+		return &JobSpec{
+			Name: name,
+			Runner: &JobRunner{
+				Name:          "ssh",
+				RawParameters: json.RawMessage(`{"host":"staging.example.com","user":"root"}`),
+				Identity:      "staging-ssh",
+			},
+			Identities:  []string{"staging-aws", "github"},
+			Environment: map[string]string{"ENV": "staging", "DEBUG": "1"},
+			Steps:       Steps{{Code: "true"}},
+		}
+	}
+
+	spec := newSpec("deploy")
+	require.NoError(mapping.Apply(spec))
+
+	assert.Equal("production-ssh", spec.Runner.Identity)
+	assert.Equal([]string{"production-aws", "github"}, spec.Identities)
+	assert.Equal(map[string]string{
+		"ENV":      "production",
+		"DEBUG":    "1",
+		"REPLICAS": "3",
+	}, spec.Environment)
+	assert.JSONEq(`{"host":"production.example.com","port":2222,"user":"root"}`,
+		string(spec.Runner.RawParameters))
+
+	spec = newSpec("test")
+	require.NoError(mapping.Apply(spec))
+
+	assert.Equal(map[string]string{"ENV": "production", "DEBUG": "1"},
+		spec.Environment)
+	assert.JSONEq(`{"host":"production.example.com","user":"root"}`,
+		string(spec.Runner.RawParameters))
+
+	spec = newSpec("test")
+	spec.Runner = nil
+	assert.Error(mapping.Apply(spec))
+}
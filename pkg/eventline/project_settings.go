@@ -10,8 +10,9 @@ import (
 )
 
 type ProjectSettings struct {
-	Id         uuid.UUID `json:"id"` // Ignored in input
-	CodeHeader string    `json:"code_header"`
+	Id                  uuid.UUID `json:"id"` // Ignored in input
+	CodeHeader          string    `json:"code_header"`
+	JobVersionRetention int       `json:"job_version_retention,omitempty"` // number of versions, 0 = unlimited
 }
 
 func (ps *ProjectSettings) ValidateJSON(v *ejson.Validator) {
@@ -19,11 +20,57 @@ func (ps *ProjectSettings) ValidateJSON(v *ejson.Validator) {
 	err := shebang.Parse(ps.CodeHeader)
 	v.Check("code_header", err == nil, "invalid_shebang",
 		"invalid shebang: %v", err)
+
+	v.CheckIntMin("job_version_retention", ps.JobVersionRetention, 0)
+}
+
+func (ps *ProjectSettings) ParseYAML(data []byte) error {
+	return decodeYAMLDocument(data, ps)
+}
+
+// ProjectSettingsUpdate contains project settings provided during a
+// synchronization. Settings which are not provided keep their current value.
+type ProjectSettingsUpdate struct {
+	CodeHeader          *string `json:"code_header,omitempty"`
+	JobVersionRetention *int    `json:"job_version_retention,omitempty"`
+}
+
+func (u *ProjectSettingsUpdate) ValidateJSON(v *ejson.Validator) {
+	if u.CodeHeader != nil {
+		var shebang Shebang
+		err := shebang.Parse(*u.CodeHeader)
+		v.Check("code_header", err == nil, "invalid_shebang",
+			"invalid shebang: %v", err)
+	}
+
+	if u.JobVersionRetention != nil {
+		v.CheckIntMin("job_version_retention", *u.JobVersionRetention, 0)
+	}
+}
+
+func (u *ProjectSettingsUpdate) ParseYAML(data []byte) error {
+	return decodeYAMLDocument(data, u)
+}
+
+// Apply returns a copy of project settings where settings provided in the
+// update have been replaced.
+func (u *ProjectSettingsUpdate) Apply(ps *ProjectSettings) ProjectSettings {
+	ps2 := *ps
+
+	if u.CodeHeader != nil {
+		ps2.CodeHeader = *u.CodeHeader
+	}
+
+	if u.JobVersionRetention != nil {
+		ps2.JobVersionRetention = *u.JobVersionRetention
+	}
+
+	return ps2
 }
 
 func (ps *ProjectSettings) Load(conn pg.Conn, id uuid.UUID) error {
 	query := `
-SELECT id, code_header
+SELECT id, code_header, job_version_retention
   FROM project_settings
   WHERE id = $1
 `
@@ -38,24 +85,25 @@ SELECT id, code_header
 func (ps *ProjectSettings) Insert(conn pg.Conn) error {
 	query := `
 INSERT INTO project_settings
-    (id, code_header)
+    (id, code_header, job_version_retention)
   VALUES
-    ($1, $2);
+    ($1, $2, $3);
 `
 	return pg.Exec(conn, query,
-		ps.Id, ps.CodeHeader)
+		ps.Id, ps.CodeHeader, ps.JobVersionRetention)
 }
 
 func (ps *ProjectSettings) Update(conn pg.Conn) error {
 	query := `
 UPDATE project_settings SET
-    code_header = $2
+    code_header = $2,
+    job_version_retention = $3
   WHERE id = $1
 `
 	return pg.Exec(conn, query,
-		ps.Id, ps.CodeHeader)
+		ps.Id, ps.CodeHeader, ps.JobVersionRetention)
 }
 
 func (ps *ProjectSettings) FromRow(row pgx.Row) error {
-	return row.Scan(&ps.Id, &ps.CodeHeader)
+	return row.Scan(&ps.Id, &ps.CodeHeader, &ps.JobVersionRetention)
 }
@@ -0,0 +1,147 @@
+package eventline
+
+import (
+	"go.n16f.net/ejson"
+)
+
+// ProjectSyncSpec is the desired state of a project. Synchronizing a project
+// creates, updates and deletes jobs so that the project contains exactly the
+// jobs of the specification.
+//
+// Identities cannot be created without their data, so they are only
+// described by their metadata: each identity listed must already exist, and
+// identities which are not listed are deleted. Identities are only
+// synchronized if the identities member is present. In the same way, project
+// settings are only updated if the project_settings member is present.
+type ProjectSyncSpec struct {
+	Jobs            JobSpecs               `json:"jobs"`
+	Identities      IdentitySyncSpecs      `json:"identities,omitempty"`
+	ProjectSettings *ProjectSettingsUpdate `json:"project_settings,omitempty"`
+}
+
+type IdentitySyncSpec struct {
+	Name      string `json:"name"`
+	Connector string `json:"connector"`
+	Type      string `json:"type"`
+}
+
+type IdentitySyncSpecs []*IdentitySyncSpec
+
+type ProjectSyncOperation string
+
+const (
+	ProjectSyncOperationCreate ProjectSyncOperation = "create"
+	ProjectSyncOperationUpdate ProjectSyncOperation = "update"
+	ProjectSyncOperationDelete ProjectSyncOperation = "delete"
+
+	// Only used for project imports, when an object is left unmodified
+	ProjectSyncOperationSkip ProjectSyncOperation = "skip"
+)
+
+type ProjectSyncObjectType string
+
+const (
+	ProjectSyncObjectTypeJob             ProjectSyncObjectType = "job"
+	ProjectSyncObjectTypeIdentity        ProjectSyncObjectType = "identity"
+	ProjectSyncObjectTypeProjectSettings ProjectSyncObjectType = "project_settings"
+
+	ProjectSyncObjectTypeProjectNotificationSettings ProjectSyncObjectType = "project_notification_settings"
+
+	ProjectSyncObjectTypeStepTemplate    ProjectSyncObjectType = "step_template"
+	ProjectSyncObjectTypeProjectVariable ProjectSyncObjectType = "project_variable"
+)
+
+type ProjectSyncChange struct {
+	Operation  ProjectSyncOperation  `json:"operation"`
+	ObjectType ProjectSyncObjectType `json:"object_type"`
+	Name       string                `json:"name,omitempty"`
+}
+
+type ProjectSyncChanges []*ProjectSyncChange
+
+// ProjectSyncPlan is the list of changes required to synchronize a project.
+type ProjectSyncPlan struct {
+	Changes ProjectSyncChanges `json:"changes"`
+}
+
+func (spec *ProjectSyncSpec) ValidateJSON(v *ejson.Validator) {
+	v.CheckObjectArray("jobs", spec.Jobs)
+	v.WithChild("jobs", func() {
+		names := make(map[string]struct{})
+
+		for i, job := range spec.Jobs {
+			if _, found := names[job.Name]; found {
+				v.AddError(i, "duplicate_job_name",
+					"duplicate job name %q", job.Name)
+			}
+
+			names[job.Name] = struct{}{}
+		}
+	})
+
+	v.CheckObjectArray("identities", spec.Identities)
+	v.WithChild("identities", func() {
+		names := make(map[string]struct{})
+
+		for i, identity := range spec.Identities {
+			if _, found := names[identity.Name]; found {
+				v.AddError(i, "duplicate_identity_name",
+					"duplicate identity name %q", identity.Name)
+			}
+
+			names[identity.Name] = struct{}{}
+		}
+	})
+
+	v.CheckOptionalObject("project_settings", spec.ProjectSettings)
+}
+
+func (spec *IdentitySyncSpec) ValidateJSON(v *ejson.Validator) {
+	CheckName(v, "name", spec.Name)
+	v.CheckStringNotEmpty("connector", spec.Connector)
+	v.CheckStringNotEmpty("type", spec.Type)
+}
+
+func (specs *IdentitySyncSpecs) ParseYAML(data []byte) error {
+	return decodeYAMLDocument(data, specs)
+}
+
+// IdentityNames returns the set of identities described by the
+// specification, or nil if identities are not synchronized.
+func (spec *ProjectSyncSpec) IdentityNames() map[string]struct{} {
+	if spec.Identities == nil {
+		return nil
+	}
+
+	names := make(map[string]struct{})
+	for _, identity := range spec.Identities {
+		names[identity.Name] = struct{}{}
+	}
+
+	return names
+}
+
+func (p *ProjectSyncPlan) AddChange(op ProjectSyncOperation, objectType ProjectSyncObjectType, name string) {
+	change := ProjectSyncChange{
+		Operation:  op,
+		ObjectType: objectType,
+		Name:       name,
+	}
+
+	p.Changes = append(p.Changes, &change)
+}
+
+func (p *ProjectSyncPlan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
+
+func (p *ProjectSyncPlan) Count(op ProjectSyncOperation) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Operation == op {
+			n++
+		}
+	}
+
+	return n
+}
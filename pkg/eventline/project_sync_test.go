@@ -0,0 +1,69 @@
+package eventline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.n16f.net/ejson"
+)
+
+func TestProjectSyncSpecValidateJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	data := `{
+  "jobs": [
+    {"name": "a", "steps": [{"code": "true"}]},
+    {"name": "b", "steps": [{"code": "true"}]},
+    {"name": "a", "steps": [{"code": "false"}]}
+  ],
+  "identities": [
+    {"name": "x", "connector": "generic", "type": "password"},
+    {"name": "x", "connector": "generic", "type": "password"}
+  ]
+}`
+
+	var spec ProjectSyncSpec
+	err := ejson.Unmarshal([]byte(data), &spec)
+
+	var verrs ejson.ValidationErrors
+	if assert.ErrorAs(err, &verrs) && assert.Len(verrs, 2) {
+		assert.Equal("/jobs/2", verrs[0].Pointer.String())
+		assert.Equal("duplicate_job_name", verrs[0].Code)
+		assert.Equal("/identities/1", verrs[1].Pointer.String())
+		assert.Equal("duplicate_identity_name", verrs[1].Code)
+	}
+}
+
+func TestProjectSyncSpecIdentityNames(t *testing.T) {
+	assert := assert.New(t)
+
+	var spec ProjectSyncSpec
+	assert.Nil(spec.IdentityNames())
+
+	spec.Identities = IdentitySyncSpecs{}
+	assert.NotNil(spec.IdentityNames())
+	assert.Empty(spec.IdentityNames())
+}
+
+func TestProjectSettingsUpdateApply(t *testing.T) {
+	assert := assert.New(t)
+
+	settings := ProjectSettings{
+		CodeHeader:          "#!/bin/sh",
+		JobVersionRetention: 10,
+	}
+
+	var update ProjectSettingsUpdate
+	err := ejson.Unmarshal([]byte(`{"code_header": "#!/bin/bash"}`), &update)
+	assert.NoError(err)
+
+	assert.Equal(ProjectSettings{
+		CodeHeader:          "#!/bin/bash",
+		JobVersionRetention: 10,
+	}, update.Apply(&settings))
+
+	assert.Equal("#!/bin/sh", settings.CodeHeader)
+
+	err = ejson.Unmarshal([]byte(`{"job_version_retention": -1}`), &update)
+	assert.Error(err)
+}
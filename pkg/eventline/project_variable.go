@@ -0,0 +1,188 @@
+package eventline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+type UnknownProjectVariableError struct {
+	Name string
+}
+
+func (err UnknownProjectVariableError) Error() string {
+	return fmt.Sprintf("unknown project variable %q", err.Name)
+}
+
+type NewProjectVariable struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Secret bool   `json:"secret,omitempty"`
+}
+
+// ProjectVariable is an environment variable set in all job executions of a
+// project. The value of secret variables is encrypted in the database and is
+// never returned by the API.
+type ProjectVariable struct {
+	ProjectId    uuid.UUID `json:"project_id"`
+	Name         string    `json:"name"`
+	CreationTime time.Time `json:"creation_time"`
+	UpdateTime   time.Time `json:"update_time"`
+	Secret       bool      `json:"secret"`
+	Value        string    `json:"value,omitempty"`
+}
+
+type ProjectVariables []*ProjectVariable
+
+func (nv *NewProjectVariable) ValidateJSON(v *ejson.Validator) {
+	CheckEnvironmentVariableName(v, "name", nv.Name)
+
+	v.Check("name", !strings.HasPrefix(nv.Name, "EVENTLINE"),
+		"reserved_name", "variable names starting with EVENTLINE are "+
+			"reserved")
+}
+
+func (pv *ProjectVariable) MarshalJSON() ([]byte, error) {
+	type ProjectVariable2 ProjectVariable
+
+	v := ProjectVariable2(*pv)
+	if v.Secret {
+		v.Value = ""
+	}
+
+	return json.Marshal(v)
+}
+
+// Environment returns the set of environment variables defined by project
+// variables.
+func (pvs ProjectVariables) Environment() map[string]string {
+	env := make(map[string]string, len(pvs))
+
+	for _, pv := range pvs {
+		env[pv.Name] = pv.Value
+	}
+
+	return env
+}
+
+// SecretValues returns the values of all secret variables.
+func (pvs ProjectVariables) SecretValues() map[string]string {
+	values := make(map[string]string)
+
+	for _, pv := range pvs {
+		if pv.Secret {
+			values[pv.Name] = pv.Value
+		}
+	}
+
+	return values
+}
+
+func (pv *ProjectVariable) LoadForUpdate(conn pg.Conn, name string, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT project_id, name, creation_time, update_time, secret, value,
+       encrypted_value
+  FROM project_variables
+  WHERE %s AND name = $1
+  FOR UPDATE;
+`, scope.SQLCondition())
+
+	err := pg.QueryObject(conn, pv, query, name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &UnknownProjectVariableError{Name: name}
+	}
+
+	return err
+}
+
+func (pvs *ProjectVariables) LoadAll(conn pg.Conn, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT project_id, name, creation_time, update_time, secret, value,
+       encrypted_value
+  FROM project_variables
+  WHERE %s
+  ORDER BY name;
+`, scope.SQLCondition())
+
+	return pg.QueryObjects(conn, pvs, query)
+}
+
+func (pv *ProjectVariable) Upsert(conn pg.Conn) error {
+	query := `
+INSERT INTO project_variables
+    (project_id, name, creation_time, update_time, secret, value,
+     encrypted_value)
+  VALUES
+    ($1, $2, $3, $4, $5, $6, $7)
+  ON CONFLICT (project_id, name) DO UPDATE SET
+    update_time = EXCLUDED.update_time,
+    secret = EXCLUDED.secret,
+    value = EXCLUDED.value,
+    encrypted_value = EXCLUDED.encrypted_value
+`
+	var value *string
+	var encryptedValue []byte
+
+	if pv.Secret {
+		var err error
+		encryptedValue, err = EncryptAES256([]byte(pv.Value))
+		if err != nil {
+			return fmt.Errorf("cannot encrypt value: %w", err)
+		}
+	} else {
+		value = &pv.Value
+	}
+
+	return pg.Exec(conn, query,
+		pv.ProjectId, pv.Name, pv.CreationTime, pv.UpdateTime, pv.Secret,
+		value, encryptedValue)
+}
+
+func (pv *ProjectVariable) Delete(conn pg.Conn) error {
+	query := `
+DELETE FROM project_variables
+  WHERE project_id = $1 AND name = $2
+`
+	return pg.Exec(conn, query, pv.ProjectId, pv.Name)
+}
+
+func (pv *ProjectVariable) FromRow(row pgx.Row) error {
+	var value *string
+	var encryptedValue []byte
+
+	err := row.Scan(&pv.ProjectId, &pv.Name, &pv.CreationTime,
+		&pv.UpdateTime, &pv.Secret, &value, &encryptedValue)
+	if err != nil {
+		return err
+	}
+
+	if pv.Secret {
+		decryptedValue, err := DecryptAES256(encryptedValue)
+		if err != nil {
+			return fmt.Errorf("cannot decrypt value: %w", err)
+		}
+
+		pv.Value = string(decryptedValue)
+	} else if value != nil {
+		pv.Value = *value
+	}
+
+	return nil
+}
+
+func (pvs *ProjectVariables) AddFromRow(row pgx.Row) error {
+	var pv ProjectVariable
+	if err := pv.FromRow(row); err != nil {
+		return err
+	}
+
+	*pvs = append(*pvs, &pv)
+	return nil
+}
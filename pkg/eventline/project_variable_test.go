@@ -0,0 +1,61 @@
+package eventline
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectVariableJSON(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	variables := ProjectVariables{
+		{Name: "REGION", Value: "eu-west-3"},
+		{Name: "TOKEN", Value: "s3cr3t", Secret: true},
+	}
+
+	data, err := json.Marshal(variables)
+	require.NoError(err)
+
+	assert.Contains(string(data), "eu-west-3")
+	assert.NotContains(string(data), "s3cr3t")
+	assert.Equal("s3cr3t", variables[1].Value)
+}
+
+func TestRunnerDataEnvironmentPrecedence(t *testing.T) {
+	assert := assert.New(t)
+
+	rd := RunnerData{
+		JobExecution: &JobExecution{
+			JobSpec: &JobSpec{
+				Name: "test",
+				Parameters: Parameters{
+					{Name: "level", Type: ParameterTypeString,
+						Environment: "LEVEL"},
+				},
+				Environment: map[string]string{
+					"REGION": "us-east-1",
+					"LEVEL":  "job",
+				},
+			},
+			Parameters: map[string]interface{}{"level": "parameter"},
+		},
+		ExecutionContext: &ExecutionContext{},
+		Project:          &Project{Name: "test"},
+		ProjectVariables: ProjectVariables{
+			{Name: "REGION", Value: "eu-west-3"},
+			{Name: "LEVEL", Value: "project"},
+			{Name: "TOKEN", Value: "secret", Secret: true},
+		},
+	}
+
+	env := rd.Environment()
+
+	assert.Equal("us-east-1", env["REGION"])
+	assert.Equal("parameter", env["LEVEL"])
+	assert.Equal("secret", env["TOKEN"])
+	assert.Equal("test", env["EVENTLINE_PROJECT_NAME"])
+}
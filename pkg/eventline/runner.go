@@ -71,6 +71,7 @@ type RunnerData struct {
 	ExecutionContext *ExecutionContext
 	Project          *Project
 	ProjectSettings  *ProjectSettings
+	ProjectVariables ProjectVariables
 }
 
 type RunnerBehaviour interface {
@@ -100,6 +101,8 @@ type Runner struct {
 	FileSet     *FileSet
 	Scope       Scope
 
+	SecretRedactor *SecretRedactor
+
 	// Keep a private copy to avoid potential concurrency issues with
 	// JobExecution since we need the id in both the main goroutine and the
 	// refresh goroutine.
@@ -134,6 +137,8 @@ func NewRunner(data RunnerInitData) (*Runner, error) {
 		FileSet:     fileSet,
 		Scope:       NewProjectScope(data.Data.Project.Id),
 
+		SecretRedactor: data.Data.SecretRedactor(),
+
 		jeId: data.Data.JobExecution.Id,
 
 		refreshInterval: data.RefreshInterval,
@@ -236,35 +241,110 @@ func (r *Runner) main() {
 
 	r.Log.Info("starting execution")
 
-	for i, se := range r.StepExecutions {
+	// If a step fails and aborts the job, we keep going to execute steps
+	// whose condition requires it (e.g. cleanup steps); the job execution
+	// fails once all steps have been considered.
+	var stepErr error
+	failed := false
+
+	steps := r.JobExecution.JobSpec.Steps
+
+	for start := 0; start < len(r.StepExecutions); {
 		if r.Stopping() {
 			r.HandleInterruption()
 			return
 		}
 
-		step := r.JobExecution.JobSpec.Steps[i]
+		// Steps of the same group are executed in parallel; conditions are
+		// evaluated before the group starts, so they only depend on steps
+		// outside the group.
+		end := steps.GroupEnd(start)
 
-		r.Log.Info("executing step %d", se.Position)
+		data := StepConditionData(r.ExecutionContext, r.StepExecutions,
+			r.StepExecutions[start].Position)
 
-		// We update the step execution here and not in Runner.executeStep
-		// because we want to set the current step execution (cse) after the
-		// start but before calling executeStep, to make sure the recovery
-		// function works as intended.
-		_, _, err := r.updateStepExecutionStart(r.jeId, se.Id, r.Scope)
-		if err != nil {
-			r.HandleError(fmt.Errorf("cannot update step %d: %w",
-				se.Position, err))
-			return
+		var groupSes StepExecutions
+		var groupSteps Steps
+
+		for i := start; i < end; i++ {
+			se := r.StepExecutions[i]
+			step := steps[i]
+
+			if stepErr != nil && !step.When.RunsAfterAbortion() {
+				continue
+			}
+
+			if !step.When.Match(data, failed) {
+				r.Log.Info("skipping step %d", se.Position)
+
+				_, _, err := r.updateStepExecutionSkipped(r.jeId, se.Id,
+					r.Scope)
+				if err != nil {
+					r.HandleError(fmt.Errorf("cannot update step %d: %w",
+						se.Position, err))
+					return
+				}
+
+				se.Status = StepExecutionStatusSkipped
+				continue
+			}
+
+			groupSes = append(groupSes, se)
+			groupSteps = append(groupSteps, step)
 		}
 
-		cse = se
+		start = end
 
-		if err := r.executeStep(ctx, se, step); err != nil {
-			r.HandleError(err)
-			return
+		var errs []error
+
+		if len(groupSes) == 1 {
+			se := groupSes[0]
+
+			r.Log.Info("executing step %d", se.Position)
+
+			// We update the step execution here and not in
+			// Runner.executeStep because we want to set the current step
+			// execution (cse) after the start but before calling
+			// executeStep, to make sure the recovery function works as
+			// intended.
+			_, _, err := r.updateStepExecutionStart(r.jeId, se.Id, r.Scope)
+			if err != nil {
+				r.HandleError(fmt.Errorf("cannot update step %d: %w",
+					se.Position, err))
+				return
+			}
+
+			cse = se
+
+			errs = []error{r.executeStep(ctx, se, groupSteps[0])}
+		} else if len(groupSes) > 1 {
+			errs = r.executeStepGroup(ctx, groupSes, groupSteps)
+		}
+
+		for i, err := range errs {
+			if err != nil {
+				var stepFailureErr *StepFailureError
+				if !errors.As(err, &stepFailureErr) {
+					r.HandleError(err)
+					return
+				}
+
+				if stepErr == nil {
+					stepErr = err
+				}
+			}
+
+			if groupSes[i].Status == StepExecutionStatusFailed {
+				failed = true
+			}
 		}
 	}
 
+	if stepErr != nil {
+		r.HandleError(stepErr)
+		return
+	}
+
 	r.Log.Info("execution finished")
 
 	if _, err := r.updateJobExecutionSuccess(r.jeId, r.Scope); err != nil {
@@ -315,6 +395,57 @@ func (r *Runner) initExecution(ctx context.Context) error {
 	return nil
 }
 
+func (r *Runner) executeStepGroup(ctx context.Context, ses StepExecutions, steps Steps) []error {
+	errs := make([]error, len(ses))
+
+	var wg sync.WaitGroup
+
+	for i, se := range ses {
+		r.Log.Info("executing step %d", se.Position)
+
+		wg.Add(1)
+		go func(i int, se *StepExecution, step *Step) {
+			defer wg.Done()
+
+			_, _, err := r.updateStepExecutionStart(r.jeId, se.Id, r.Scope)
+			if err != nil {
+				errs[i] = fmt.Errorf("cannot update step %d: %w",
+					se.Position, err)
+				return
+			}
+
+			// Panics in the main goroutine are handled by Runner.main, but
+			// steps of a group are executed in their own goroutine.
+			defer func() {
+				if value := recover(); value != nil {
+					msg := program.RecoverValueString(value)
+					trace := program.StackTrace(0, 20, true)
+
+					r.Log.Error("panic: %s\n%s", msg, trace)
+
+					panicErr := fmt.Errorf("panic: %s", msg)
+
+					_, _, err := r.updateStepExecutionFailure(r.jeId, se.Id,
+						panicErr, r.Scope)
+					if err != nil {
+						errs[i] = fmt.Errorf("cannot update step %d: %w",
+							se.Position, err)
+						return
+					}
+
+					errs[i] = panicErr
+				}
+			}()
+
+			errs[i] = r.executeStep(ctx, se, step)
+		}(i, se, steps[i])
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
 func (r *Runner) executeStep(ctx context.Context, se *StepExecution, step *Step) error {
 	jeId := r.JobExecution.Id
 
@@ -376,6 +507,8 @@ func (r *Runner) executeStep(ctx context.Context, se *StepExecution, step *Step)
 					se.Id, err)
 			}
 
+			se.Status = StepExecutionStatusFailed
+
 			if step.AbortOnFailure() {
 				return fmt.Errorf("cannot execute step %d: %w",
 					se.Position, err)
@@ -398,6 +531,8 @@ func (r *Runner) executeStep(ctx context.Context, se *StepExecution, step *Step)
 		return fmt.Errorf("cannot update step %d: %w", se.Position, err)
 	}
 
+	se.Status = StepExecutionStatusSuccessful
+
 	return nil
 }
 
@@ -406,7 +541,7 @@ func (r *Runner) readOutput(se *StepExecution, output io.ReadCloser, name string
 
 	bufferedOutput := bufio.NewReader(output)
 	var outputSize int
-	var line []byte
+	var line, currentLine []byte
 
 	lastUpdate := time.Now()
 	updatePeriod := time.Duration(1 * time.Second)
@@ -419,11 +554,16 @@ func (r *Runner) readOutput(se *StepExecution, output io.ReadCloser, name string
 			return
 		}
 
+		// Secrets are redacted line by line before the output is stored, so
+		// that they never reach the database.
 		if err == nil {
-			line = append(line, data...)
+			currentLine = append(currentLine, data...)
 			if isPrefix {
 				continue
 			}
+
+			line = append(line, r.SecretRedactor.Redact(currentLine)...)
+			currentLine = currentLine[:0]
 		}
 
 		if len(line) > 0 && line[len(line)-1] != '\n' {
@@ -483,6 +623,10 @@ func (r *Runner) HandleError(err error) {
 	r.StepExecutions = ses
 }
 
+// Environment returns the environment variables of the job execution. When
+// the same variable is defined several times, the following order of
+// precedence applies, from lowest to highest: project variables, identity
+// variables, job environment variables and parameters.
 func (rd *RunnerData) Environment() map[string]string {
 	env := map[string]string{
 		"EVENTLINE":                  "true",
@@ -493,6 +637,10 @@ func (rd *RunnerData) Environment() map[string]string {
 		"EVENTLINE_JOB_EXECUTION_ID": rd.JobExecution.Id.String(),
 	}
 
+	for name, value := range rd.ProjectVariables.Environment() {
+		env[name] = value
+	}
+
 	for _, i := range rd.ExecutionContext.Identities {
 		for name, value := range i.Data.Environment() {
 			env[name] = value
@@ -516,6 +664,32 @@ func (rd *RunnerData) Environment() map[string]string {
 	return env
 }
 
+// SecretRedactor returns a redactor for all secret values available to the
+// job execution.
+func (rd *RunnerData) SecretRedactor() *SecretRedactor {
+	r := NewSecretRedactor()
+
+	for _, name := range utils.SortedKeys(rd.ExecutionContext.Identities) {
+		identity := rd.ExecutionContext.Identities[name]
+
+		for _, entry := range identity.Data.Def().Entries {
+			if value, ok := entry.Value.(string); ok && entry.Secret {
+				r.AddSecret(value, "identity "+identity.Name)
+			}
+		}
+	}
+
+	for name, value := range rd.ProjectVariables.SecretValues() {
+		r.AddSecret(value, "variable "+name)
+	}
+
+	for name, value := range rd.JobExecution.SecretParameterValues() {
+		r.AddSecret(value, "parameter "+name)
+	}
+
+	return r
+}
+
 func (rd *RunnerData) FileSet() (*FileSet, error) {
 	fs := NewFileSet()
 
@@ -776,6 +950,15 @@ func (r *Runner) updateStepExecutionAborted(jeId, seId uuid.UUID, scope Scope) (
 	}, scope)
 }
 
+func (r *Runner) updateStepExecutionSkipped(jeId, seId uuid.UUID, scope Scope) (*JobExecution, *StepExecution, error) {
+	return r.updateStepExecution(jeId, seId, func(se *StepExecution) {
+		se.Status = StepExecutionStatusSkipped
+		se.StartTime = nil
+		se.EndTime = nil
+		se.FailureMessage = ""
+	}, scope)
+}
+
 func (r *Runner) updateStepExecutionSuccess(jeId, seId uuid.UUID, scope Scope) (*JobExecution, *StepExecution, error) {
 	return r.updateStepExecution(jeId, seId, func(se *StepExecution) {
 		now := time.Now().UTC()
@@ -0,0 +1,91 @@
+package eventline
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Secrets shorter than this length are not redacted: they would match too
+// many unrelated strings and make the output unreadable.
+const MinRedactedSecretLength = 4
+
+// The length of lines produced by base64(1) and most MIME encoders.
+const base64LineLength = 76
+
+// SecretRedactor replaces secret values in step output by a placeholder
+// indicating where the secret comes from. Each secret is also matched in
+// base64-encoded form, and secrets containing multiple lines are matched line
+// by line since output is processed one line at a time.
+type SecretRedactor struct {
+	secrets []redactedSecret
+}
+
+type redactedSecret struct {
+	value       []byte
+	placeholder []byte
+}
+
+func NewSecretRedactor() *SecretRedactor {
+	return &SecretRedactor{}
+}
+
+// AddSecret registers a secret value. The source describes the object
+// containing the secret, e.g. `identity github`, and is used in the
+// placeholder.
+func (r *SecretRedactor) AddSecret(value, source string) {
+	placeholder := []byte(fmt.Sprintf("[redacted %s]", source))
+
+	add := func(s string) {
+		s = strings.TrimSpace(s)
+		if len(s) < MinRedactedSecretLength {
+			return
+		}
+
+		r.secrets = append(r.secrets, redactedSecret{
+			value:       []byte(s),
+			placeholder: placeholder,
+		})
+	}
+
+	add(value)
+
+	if strings.Contains(value, "\n") {
+		for _, line := range strings.Split(value, "\n") {
+			add(line)
+		}
+	}
+
+	add(base64.RawStdEncoding.EncodeToString([]byte(value)))
+
+	encodedValue := base64.StdEncoding.EncodeToString([]byte(value))
+	add(encodedValue)
+
+	if len(encodedValue) > base64LineLength {
+		for len(encodedValue) > 0 {
+			n := min(base64LineLength, len(encodedValue))
+			add(encodedValue[:n])
+			encodedValue = encodedValue[n:]
+		}
+	}
+
+	// Longer secrets must be replaced first so that a secret containing
+	// another one is entirely redacted.
+	sort.SliceStable(r.secrets, func(i, j int) bool {
+		return len(r.secrets[i].value) > len(r.secrets[j].value)
+	})
+}
+
+// Redact returns data with all secrets replaced. The original slice is never
+// modified.
+func (r *SecretRedactor) Redact(data []byte) []byte {
+	for _, secret := range r.secrets {
+		if bytes.Contains(data, secret.value) {
+			data = bytes.ReplaceAll(data, secret.value, secret.placeholder)
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,98 @@
+package eventline
+
+import (
+	"strconv"
+
+	"go.n16f.net/ejson"
+)
+
+type StepConditionStatus string
+
+const (
+	StepConditionStatusSuccess StepConditionStatus = "success"
+	StepConditionStatusFailure StepConditionStatus = "failure"
+	StepConditionStatusAlways  StepConditionStatus = "always"
+)
+
+var StepConditionStatusValues = []StepConditionStatus{
+	StepConditionStatusSuccess,
+	StepConditionStatusFailure,
+	StepConditionStatusAlways,
+}
+
+type StepCondition struct {
+	Status  StepConditionStatus `json:"status,omitempty"`
+	Filters Filters             `json:"filters,omitempty"`
+}
+
+func (c *StepCondition) ValidateJSON(v *ejson.Validator) {
+	if c.Status != "" {
+		v.CheckStringValue("status", c.Status, StepConditionStatusValues)
+	}
+
+	v.CheckObjectArray("filters", c.Filters)
+}
+
+// RunsAfterAbortion indicates whether the step must still be considered for
+// execution after a previous step failed and aborted the job.
+func (c *StepCondition) RunsAfterAbortion() bool {
+	if c == nil {
+		return false
+	}
+
+	return c.Status == StepConditionStatusFailure ||
+		c.Status == StepConditionStatusAlways
+}
+
+// Match evaluates the condition. The data object is the value returned by
+// StepConditionData; failed indicates whether at least one previous step
+// failed.
+func (c *StepCondition) Match(data interface{}, failed bool) bool {
+	if c == nil {
+		return true
+	}
+
+	switch c.Status {
+	case StepConditionStatusSuccess:
+		if failed {
+			return false
+		}
+
+	case StepConditionStatusFailure:
+		if !failed {
+			return false
+		}
+	}
+
+	return c.Filters.Match(data)
+}
+
+// StepConditionData returns the object step condition filters are applied
+// to. Previous steps are indexed by position; steps which have not been
+// executed yet are not included.
+func StepConditionData(ectx *ExecutionContext, ses StepExecutions, position int) interface{} {
+	parameters := make(map[string]interface{})
+	for name, value := range ectx.Parameters {
+		parameters[name] = value
+	}
+
+	var event interface{} = map[string]interface{}{}
+	if ectx.Event != nil && ectx.Event.DataValue != nil {
+		event = ectx.Event.DataValue
+	}
+
+	steps := make(map[string]interface{})
+	for _, se := range ses {
+		if se.Position >= position {
+			break
+		}
+
+		steps[strconv.Itoa(se.Position)] = string(se.Status)
+	}
+
+	return map[string]interface{}{
+		"parameters": parameters,
+		"event":      event,
+		"steps":      steps,
+	}
+}
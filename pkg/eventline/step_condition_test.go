@@ -0,0 +1,54 @@
+package eventline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.n16f.net/ejson"
+)
+
+func TestStepConditionMatch(t *testing.T) {
+	assert := assert.New(t)
+
+	ectx := &ExecutionContext{
+		Parameters: map[string]interface{}{"env": "production"},
+		Event: &Event{
+			DataValue: map[string]interface{}{"branch": "main"},
+		},
+	}
+
+	ses := StepExecutions{
+		{Position: 1, Status: StepExecutionStatusSuccessful},
+		{Position: 2, Status: StepExecutionStatusFailed},
+		{Position: 3, Status: StepExecutionStatusCreated},
+	}
+
+	data := StepConditionData(ectx, ses, 3)
+
+	var c *StepCondition
+	assert.True(c.Match(data, true))
+	assert.False(c.RunsAfterAbortion())
+
+	c = &StepCondition{Status: StepConditionStatusSuccess}
+	assert.True(c.Match(data, false))
+	assert.False(c.Match(data, true))
+
+	c = &StepCondition{Status: StepConditionStatusFailure}
+	assert.False(c.Match(data, false))
+	assert.True(c.Match(data, true))
+	assert.True(c.RunsAfterAbortion())
+
+	c = &StepCondition{
+		Filters: Filters{
+			{Path: ejson.NewPointer("event", "branch"), IsEqualTo: "main"},
+			{Path: ejson.NewPointer("parameters", "env"),
+				IsEqualTo: "production"},
+			{Path: ejson.NewPointer("steps", "2"), IsEqualTo: "failed"},
+		},
+	}
+	assert.True(c.Match(data, true))
+
+	c.Filters = append(c.Filters,
+		&Filter{Path: ejson.NewPointer("steps", "3"), IsEqualTo: "created"})
+	assert.False(c.Match(data, true))
+}
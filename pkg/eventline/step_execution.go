@@ -26,6 +26,7 @@ const (
 	StepExecutionStatusAborted    StepExecutionStatus = "aborted"
 	StepExecutionStatusSuccessful StepExecutionStatus = "successful"
 	StepExecutionStatusFailed     StepExecutionStatus = "failed"
+	StepExecutionStatusSkipped    StepExecutionStatus = "skipped"
 )
 
 var StepExecutionStatusValues = []StepExecutionStatus{
@@ -34,6 +35,7 @@ var StepExecutionStatusValues = []StepExecutionStatus{
 	StepExecutionStatusAborted,
 	StepExecutionStatusSuccessful,
 	StepExecutionStatusFailed,
+	StepExecutionStatusSkipped,
 }
 
 type StepExecution struct {
@@ -0,0 +1,492 @@
+package eventline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.n16f.net/ejson"
+	"go.n16f.net/program"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+var StepTemplateSorts Sorts = Sorts{
+	Sorts: map[string]string{
+		"id":   "id",
+		"name": "name",
+	},
+
+	Default: "name",
+}
+
+type UnknownStepTemplateNameError struct {
+	Name string
+}
+
+func (err UnknownStepTemplateNameError) Error() string {
+	return fmt.Sprintf("unknown step template %q", err.Name)
+}
+
+type StepTemplate struct {
+	Id           uuid.UUID         `json:"id"`
+	ProjectId    uuid.UUID         `json:"project_id"`
+	CreationTime time.Time         `json:"creation_time"`
+	UpdateTime   time.Time         `json:"update_time"`
+	Spec         *StepTemplateSpec `json:"spec"`
+}
+
+type StepTemplates []*StepTemplate
+
+type StepTemplateSpec struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Parameters  Parameters `json:"parameters,omitempty"`
+	Steps       Steps      `json:"steps"`
+}
+
+// StepTemplateRef is the content of a step referencing a step template.
+type StepTemplateRef struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+func (t *StepTemplate) SortKey(sort string) (key string) {
+	switch sort {
+	case "id":
+		key = t.Id.String()
+	case "name":
+		key = t.Spec.Name
+	default:
+		program.Panic("unknown step template sort %q", sort)
+	}
+
+	return
+}
+
+func (spec *StepTemplateSpec) ValidateJSON(v *ejson.Validator) {
+	CheckName(v, "name", spec.Name)
+	if spec.Description != "" {
+		CheckDescription(v, "description", spec.Description)
+	}
+
+	v.CheckObjectArray("parameters", spec.Parameters)
+	v.WithChild("parameters", func() {
+		for i, p := range spec.Parameters {
+			v.Check(i, p.Environment == "", "unexpected_environment",
+				"step template parameters cannot have an environment "+
+					"variable")
+		}
+	})
+
+	v.CheckArrayNotEmpty("steps", spec.Steps)
+	v.CheckObjectArray("steps", spec.Steps)
+	v.WithChild("steps", func() {
+		for i, s := range spec.Steps {
+			v.Check(i, s.Template == nil, "nested_step_template",
+				"step templates cannot reference other step templates")
+		}
+	})
+}
+
+func (ref *StepTemplateRef) ValidateJSON(v *ejson.Validator) {
+	CheckName(v, "name", ref.Name)
+}
+
+// Equal reports whether two step template specifications are identical once
+// encoded.
+func (spec *StepTemplateSpec) Equal(spec2 *StepTemplateSpec) (bool, error) {
+	value1, err := jsonValue(spec)
+	if err != nil {
+		return false, fmt.Errorf("cannot encode step template "+
+			"specification: %w", err)
+	}
+
+	value2, err := jsonValue(spec2)
+	if err != nil {
+		return false, fmt.Errorf("cannot encode step template "+
+			"specification: %w", err)
+	}
+
+	return reflect.DeepEqual(value1, value2), nil
+}
+
+func (spec *StepTemplateSpec) ParseYAML(data []byte) error {
+	return decodeYAMLDocument(data, spec)
+}
+
+// Expand returns the steps of the template instantiated for a specific
+// reference. Arguments are substituted in labels, code, commands and scripts
+// using Go templates delimited by "[[" and "]]", so that they do not
+// interfere with other uses of braces.
+//
+// Conditions, groups and failure actions of the referencing step apply to all
+// steps which do not define them. Each step records the reference it was
+// expanded from.
+func (spec *StepTemplateSpec) Expand(step *Step) (Steps, error) {
+	ref := step.Template
+
+	arguments := make(map[string]interface{})
+	for name, value := range ref.Arguments {
+		arguments[name] = value
+	}
+
+	v := ejson.NewValidator()
+	spec.Parameters.CheckValues(v, "arguments", arguments)
+	if err := v.Error(); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	expand := func(s string) (string, error) {
+		return ExpandStepTemplateString(s, arguments)
+	}
+
+	expandStrings := func(ss []string) ([]string, error) {
+		if ss == nil {
+			return nil, nil
+		}
+
+		ss2 := make([]string, len(ss))
+		for i, s := range ss {
+			s2, err := expand(s)
+			if err != nil {
+				return nil, err
+			}
+
+			ss2[i] = s2
+		}
+
+		return ss2, nil
+	}
+
+	steps := make(Steps, len(spec.Steps))
+
+	for i, tstep := range spec.Steps {
+		s := *tstep
+
+		var err error
+
+		if s.Label == "" {
+			if len(spec.Steps) == 1 {
+				s.Label = step.Label
+			} else if step.Label != "" {
+				s.Label = step.Label + " (" + strconv.Itoa(i+1) + ")"
+			}
+		}
+
+		if s.Label, err = expand(s.Label); err != nil {
+			return nil, fmt.Errorf("step %d: invalid label: %w", i+1, err)
+		}
+
+		if s.Code, err = expand(s.Code); err != nil {
+			return nil, fmt.Errorf("step %d: invalid code: %w", i+1, err)
+		}
+
+		if tstep.Command != nil {
+			command := *tstep.Command
+
+			if command.Name, err = expand(command.Name); err != nil {
+				return nil, fmt.Errorf("step %d: invalid command: %w",
+					i+1, err)
+			}
+
+			command.Arguments, err = expandStrings(command.Arguments)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: invalid command: %w",
+					i+1, err)
+			}
+
+			s.Command = &command
+		}
+
+		if tstep.Script != nil {
+			script := *tstep.Script
+
+			script.Arguments, err = expandStrings(script.Arguments)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: invalid script: %w",
+					i+1, err)
+			}
+
+			if script.Content, err = expand(script.Content); err != nil {
+				return nil, fmt.Errorf("step %d: invalid script: %w",
+					i+1, err)
+			}
+
+			s.Script = &script
+		}
+
+		if s.When == nil {
+			s.When = step.When
+		}
+
+		if s.Group == "" {
+			s.Group = step.Group
+		}
+
+		if s.OnFailure == "" {
+			s.OnFailure = step.OnFailure
+		}
+
+		s.TemplateOrigin = ref
+
+		steps[i] = &s
+	}
+
+	return steps, nil
+}
+
+// CheckJobSpec verifies that the steps of a deployed job specification which
+// were expanded from the step template could still be expanded with the same
+// arguments.
+func (spec *StepTemplateSpec) CheckJobSpec(jobSpec *JobSpec) error {
+	for i, step := range jobSpec.Steps {
+		ref := step.TemplateOrigin
+		if ref == nil || ref.Name != spec.Name {
+			continue
+		}
+
+		if _, err := spec.Expand(&Step{Template: ref}); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func ExpandStepTemplateString(s string, arguments map[string]interface{}) (string, error) {
+	tpl := template.New("").Delims("[[", "]]").Option("missingkey=error")
+
+	if _, err := tpl.Parse(s); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, arguments); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func (spec *JobSpec) StepTemplateNames() []string {
+	var names []string
+
+	for _, step := range spec.Steps {
+		if step.Template != nil {
+			names = append(names, step.Template.Name)
+		}
+	}
+
+	return names
+}
+
+// ExpandStepTemplates returns a copy of the job specification where steps
+// referencing step templates are replaced by the steps of these templates.
+func (spec *JobSpec) ExpandStepTemplates(templates map[string]*StepTemplate) (*JobSpec, error) {
+	if len(spec.StepTemplateNames()) == 0 {
+		return spec, nil
+	}
+
+	spec2 := *spec
+	spec2.Steps = nil
+
+	for i, step := range spec.Steps {
+		if step.Template == nil {
+			step2 := *step
+			spec2.Steps = append(spec2.Steps, &step2)
+			continue
+		}
+
+		name := step.Template.Name
+
+		stepTemplate, found := templates[name]
+		if !found {
+			return nil, &UnknownStepTemplateNameError{Name: name}
+		}
+
+		steps, err := stepTemplate.Spec.Expand(step)
+		if err != nil {
+			return nil, fmt.Errorf("cannot expand step template %q in "+
+				"step %d: %w", name, i+1, err)
+		}
+
+		spec2.Steps = append(spec2.Steps, steps...)
+	}
+
+	for i, s := range spec2.Steps {
+		if s.Label == "" {
+			s.Label = "Step " + strconv.Itoa(i+1)
+		}
+	}
+
+	return &spec2, nil
+}
+
+func (t *StepTemplate) LoadByName(conn pg.Conn, name string, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT id, project_id, creation_time, update_time, spec
+  FROM step_templates
+  WHERE %s AND name = $1;
+`, scope.SQLCondition())
+
+	err := pg.QueryObject(conn, t, query, name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &UnknownStepTemplateNameError{Name: name}
+	}
+
+	return err
+}
+
+func (t *StepTemplate) LoadByNameForUpdate(conn pg.Conn, name string, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT id, project_id, creation_time, update_time, spec
+  FROM step_templates
+  WHERE %s AND name = $1
+  FOR UPDATE;
+`, scope.SQLCondition())
+
+	err := pg.QueryObject(conn, t, query, name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &UnknownStepTemplateNameError{Name: name}
+	}
+
+	return err
+}
+
+func (ts *StepTemplates) LoadAll(conn pg.Conn, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT id, project_id, creation_time, update_time, spec
+  FROM step_templates
+  WHERE %s
+  ORDER BY name;
+`, scope.SQLCondition())
+
+	return pg.QueryObjects(conn, ts, query)
+}
+
+func (ts *StepTemplates) LoadByNames(conn pg.Conn, names []string, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT id, project_id, creation_time, update_time, spec
+  FROM step_templates
+  WHERE %s AND name = ANY ($1);
+`, scope.SQLCondition())
+
+	return pg.QueryObjects(conn, ts, query, names)
+}
+
+func LoadStepTemplatePage(conn pg.Conn, cursor *Cursor, scope Scope) (*Page, error) {
+	query := fmt.Sprintf(`
+SELECT id, project_id, creation_time, update_time, spec
+  FROM step_templates
+  WHERE %s AND %s;
+`, scope.SQLCondition(), cursor.SQLConditionOrderLimit(StepTemplateSorts))
+
+	var templates StepTemplates
+	if err := pg.QueryObjects(conn, &templates, query); err != nil {
+		return nil, err
+	}
+
+	return templates.Page(cursor), nil
+}
+
+func (t *StepTemplate) IsUsed(conn pg.Conn, scope Scope) (bool, error) {
+	ctx := context.Background()
+
+	query := fmt.Sprintf(`
+SELECT 1
+  WHERE EXISTS
+          (SELECT id
+             FROM jobs
+             WHERE %s
+               AND spec->'steps' @> jsonb_build_array(
+                     jsonb_build_object('template_origin',
+                       jsonb_build_object('name', $1::TEXT))));
+`, scope.SQLCondition())
+
+	var n int64
+	err := conn.QueryRow(ctx, query, t.Spec.Name).Scan(&n)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (js *Jobs) LoadByStepTemplateName(conn pg.Conn, name string, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT id, project_id, creation_time, update_time, disabled, spec, git_commit
+  FROM jobs
+  WHERE %s
+    AND spec->'steps' @> jsonb_build_array(
+          jsonb_build_object('template_origin',
+            jsonb_build_object('name', $1::TEXT)))
+  ORDER BY id
+`, scope.SQLCondition())
+
+	return pg.QueryObjects(conn, js, query, name)
+}
+
+func (t *StepTemplate) Upsert(conn pg.Conn) (*uuid.UUID, error) {
+	ctx := context.Background()
+
+	query := `
+INSERT INTO step_templates
+    (id, project_id, name, creation_time, update_time, spec)
+  VALUES
+    ($1, $2, $3, $4, $5, $6)
+  ON CONFLICT (project_id, name) DO UPDATE SET
+    update_time = EXCLUDED.update_time,
+    spec = EXCLUDED.spec
+  RETURNING id
+`
+	var id uuid.UUID
+	row := conn.QueryRow(ctx, query,
+		t.Id, t.ProjectId, t.Spec.Name, t.CreationTime, t.UpdateTime, t.Spec)
+	if err := row.Scan(&id); err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
+
+func (t *StepTemplate) Delete(conn pg.Conn, scope Scope) error {
+	query := fmt.Sprintf(`
+DELETE FROM step_templates
+  WHERE %s AND id = $1;
+`, scope.SQLCondition())
+
+	return pg.Exec(conn, query, t.Id)
+}
+
+func (ts StepTemplates) Page(cursor *Cursor) *Page {
+	elements := make([]PageElement, len(ts))
+	for i, t := range ts {
+		elements[i] = t
+	}
+
+	return NewPage(cursor, elements, StepTemplateSorts)
+}
+
+func (t *StepTemplate) FromRow(row pgx.Row) error {
+	return row.Scan(&t.Id, &t.ProjectId, &t.CreationTime, &t.UpdateTime,
+		&t.Spec)
+}
+
+func (ts *StepTemplates) AddFromRow(row pgx.Row) error {
+	var t StepTemplate
+	if err := t.FromRow(row); err != nil {
+		return err
+	}
+
+	*ts = append(*ts, &t)
+	return nil
+}
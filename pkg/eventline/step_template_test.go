@@ -0,0 +1,98 @@
+package eventline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobSpecExpandStepTemplates(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	template := &StepTemplate{
+		Spec: &StepTemplateSpec{
+			Name: "checkout",
+			Parameters: Parameters{
+				{Name: "repository", Type: ParameterTypeString},
+				{Name: "branch", Type: ParameterTypeString, Default: "main"},
+			},
+			Steps: Steps{
+				{
+					Label: "Clone [[.repository]]",
+					Command: &StepCommand{
+						Name:      "git",
+						Arguments: []string{"clone", "[[.repository]]"},
+					},
+				},
+				{
+					Code: "git checkout [[.branch]]",
+				},
+			},
+		},
+	}
+
+	templates := map[string]*StepTemplate{"checkout": template}
+
+	spec := &JobSpec{
+		Steps: Steps{
+			{Code: "echo start"},
+			{
+				Label: "Checkout",
+				Group: "setup",
+				Template: &StepTemplateRef{
+					Name: "checkout",
+					Arguments: map[string]interface{}{
+						"repository": "https://example.com/repo.git",
+					},
+				},
+			},
+		},
+	}
+
+	spec2, err := spec.ExpandStepTemplates(templates)
+	require.NoError(err)
+	require.Len(spec2.Steps, 3)
+
+	assert.Len(spec.Steps, 2)
+	assert.NotNil(spec.Steps[1].Template)
+
+	assert.Equal("Step 1", spec2.Steps[0].Label)
+	assert.Equal("", spec.Steps[0].Label)
+	assert.Nil(spec2.Steps[0].TemplateOrigin)
+
+	s := spec2.Steps[1]
+	assert.Equal("Clone https://example.com/repo.git", s.Label)
+	assert.Equal("setup", s.Group)
+	assert.Equal([]string{"clone", "https://example.com/repo.git"},
+		s.Command.Arguments)
+	assert.Equal([]string{"clone", "[[.repository]]"},
+		template.Spec.Steps[0].Command.Arguments)
+
+	s = spec2.Steps[2]
+	assert.Equal("Checkout (2)", s.Label)
+	assert.Equal("git checkout main", s.Code)
+	require.NotNil(s.TemplateOrigin)
+	assert.Equal("checkout", s.TemplateOrigin.Name)
+
+	// Dependent jobs
+	assert.NoError(template.Spec.CheckJobSpec(spec2))
+
+	template2 := *template.Spec
+	template2.Parameters = Parameters{
+		{Name: "repository", Type: ParameterTypeString},
+		{Name: "revision", Type: ParameterTypeString},
+	}
+	assert.Error(template2.CheckJobSpec(spec2))
+
+	// Missing mandatory argument
+	spec.Steps[1].Template.Arguments = nil
+	_, err = spec.ExpandStepTemplates(templates)
+	assert.Error(err)
+
+	// Unknown template
+	spec.Steps[1].Template.Name = "foo"
+	_, err = spec.ExpandStepTemplates(templates)
+	assert.ErrorAs(err, new(*UnknownStepTemplateNameError))
+}
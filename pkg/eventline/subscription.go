@@ -78,7 +78,6 @@ func (s *Subscription) NewEvent(cname, ename string, etime *time.Time, data Even
 	return &Event{
 		Id:           uuid.MustGenerate(uuid.V7),
 		ProjectId:    *s.ProjectId,
-		JobId:        *s.JobId,
 		CreationTime: now,
 		EventTime:    *etime,
 		Connector:    cname,
@@ -131,6 +130,21 @@ SELECT id, project_id, job_id, identity_id, connector, event, parameters,
 	return err
 }
 
+func (ss *Subscriptions) LoadByEvent(conn pg.Conn, cname, ename string, scope Scope) error {
+	query := fmt.Sprintf(`
+SELECT id, project_id, job_id, identity_id, connector, event, parameters,
+       creation_time, status, update_delay, last_update_time, next_update_time
+  FROM subscriptions
+  WHERE %s
+    AND connector = $1
+    AND event = $2
+    AND status <> 'terminating'
+  ORDER BY creation_time;
+`, scope.SQLCondition())
+
+	return pg.QueryObjects(conn, ss, query, cname, ename)
+}
+
 func LoadSubscriptionForProcessing(conn pg.Conn) (*Subscription, error) {
 	now := time.Now().UTC()
 
@@ -138,7 +152,7 @@ func LoadSubscriptionForProcessing(conn pg.Conn) (*Subscription, error) {
 SELECT id, project_id, job_id, identity_id, connector, event, parameters,
        creation_time, status, update_delay, last_update_time, next_update_time
   FROM subscriptions
-  WHERE status = 'inactive' OR status = 'terminating'
+  WHERE (status = 'inactive' OR status = 'terminating')
     AND next_update_time < $1
   ORDER BY op
   LIMIT 1
@@ -198,6 +212,18 @@ UPDATE subscriptions SET
 	return pg.Exec(conn, query, s.Id)
 }
 
+// ScheduleIdentitySubscriptions schedules the processing of inactive
+// subscriptions using an identity. It is used when the identity becomes ready
+// since these subscriptions are not processed while it is pending.
+func ScheduleIdentitySubscriptions(conn pg.Conn, identityId uuid.UUID) error {
+	query := `
+UPDATE subscriptions SET
+    next_update_time = current_timestamp
+  WHERE identity_id = $1 AND status = 'inactive'
+`
+	return pg.Exec(conn, query, identityId)
+}
+
 func (s *Subscription) Delete(conn pg.Conn) error {
 	query := `
 DELETE FROM subscriptions
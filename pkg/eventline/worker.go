@@ -110,6 +110,8 @@ func (w *Worker) WakeUp() {
 
 func (w *Worker) main() {
 	defer func() {
+		w.Cfg.Behaviour.Stop()
+
 		close(w.wakeUpChan)
 		w.wg.Done()
 	}()
@@ -2,10 +2,15 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
 
 	"go.n16f.net/service/pkg/shttp"
 )
 
+// Returned in transactions to roll back modifications made to validate dry
+// run requests.
+var errDryRun = errors.New("dry run")
+
 type APIError struct {
 	Message string          `json:"error"`
 	Code    string          `json:"code,omitempty"`
@@ -49,6 +54,12 @@ func (s *APIHTTPServer) initHTTPServer() {
 	s.setupProjectRoutes()
 	s.setupIdentityRoutes()
 	s.setupJobRoutes()
+	s.setupStepTemplateRoutes()
+	s.setupProjectSyncRoutes()
+	s.setupProjectArchiveRoutes()
+	s.setupProjectPromotionRoutes()
+	s.setupProjectVariableRoutes()
+	s.setupGitRepositoryRoutes()
 	s.setupJobExecutionRoutes()
 	s.setupEventRoutes()
 }
@@ -5,7 +5,9 @@ import (
 	"fmt"
 
 	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
 	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
 )
 
 func (s *APIHTTPServer) setupEventRoutes() {
@@ -13,6 +15,14 @@ func (s *APIHTTPServer) setupEventRoutes() {
 		s.hEventsGET,
 		HTTPRouteOptions{Project: true})
 
+	s.route("/events", "POST",
+		s.hEventsPOST,
+		HTTPRouteOptions{Project: true})
+
+	s.route("/events/replay", "POST",
+		s.hEventsReplayPOST,
+		HTTPRouteOptions{Project: true})
+
 	s.route("/events/id/{id}", "GET",
 		s.hEventsIdGET,
 		HTTPRouteOptions{Project: true})
@@ -30,10 +40,27 @@ func (s *APIHTTPServer) hEventsGET(h *HTTPHandler) {
 		return
 	}
 
+	pageOptions, err := h.ParseEventPageOptions()
+	if err != nil {
+		return
+	}
+
+	if s := h.QueryParameter("job_id"); s != "" {
+		var jobId uuid.UUID
+		if err := jobId.Parse(s); err != nil {
+			h.ReplyError(400, "invalid_query_parameter",
+				"invalid job id: %v", err)
+			return
+		}
+
+		pageOptions.JobId = &jobId
+	}
+
 	var page *eventline.Page
 
 	err = s.Pg.WithConn(func(conn pg.Conn) (err error) {
-		page, err = eventline.LoadEventPage(conn, cursor, scope)
+		page, err = eventline.LoadEventPage(conn, *pageOptions, cursor,
+			scope)
 		if err != nil {
 			err = fmt.Errorf("cannot load events: %w", err)
 		}
@@ -47,6 +74,60 @@ func (s *APIHTTPServer) hEventsGET(h *HTTPHandler) {
 	h.ReplyJSON(200, page)
 }
 
+func (s *APIHTTPServer) hEventsPOST(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	var ne eventline.NewCustomEvent
+	if err := h.JSONRequestData(&ne); err != nil {
+		return
+	}
+
+	events, err := s.Service.CreateCustomEvents(&ne, scope)
+	if err != nil {
+		var validationErrors ejson.ValidationErrors
+
+		if errors.As(err, &validationErrors) {
+			h.ReplyValidationErrors(validationErrors)
+		} else {
+			h.ReplyInternalError(500, "cannot create events: %v", err)
+		}
+
+		return
+	}
+
+	if events == nil {
+		events = eventline.Events{}
+	}
+
+	h.ReplyJSON(201, events)
+}
+
+func (s *APIHTTPServer) hEventsReplayPOST(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	var selection eventline.EventReplaySelection
+	if err := h.JSONRequestData(&selection); err != nil {
+		return
+	}
+
+	dryRun := h.HasQueryParameter("dry-run")
+
+	result, err := s.Service.ReplayEvents(&selection, dryRun, scope)
+	if err != nil {
+		var tooManyEventsErr *eventline.TooManyEventsError
+
+		if errors.As(err, &tooManyEventsErr) {
+			h.ReplyError(400, "too_many_events", "%v", err)
+		} else {
+			h.ReplyInternalError(500, "cannot replay events: %v", err)
+		}
+
+		return
+	}
+
+	h.ReplyJSON(200, result)
+}
+
 func (s *APIHTTPServer) hEventsIdGET(h *HTTPHandler) {
 	scope := h.Context.ProjectScope()
 
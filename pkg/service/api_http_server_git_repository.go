@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
+)
+
+func (s *APIHTTPServer) setupGitRepositoryRoutes() {
+	s.route("/git_repository", "GET", s.hGitRepositoryGET,
+		HTTPRouteOptions{Project: true})
+
+	s.route("/git_repository", "PUT", s.hGitRepositoryPUT,
+		HTTPRouteOptions{Project: true})
+
+	s.route("/git_repository", "DELETE", s.hGitRepositoryDELETE,
+		HTTPRouteOptions{Project: true})
+
+	s.route("/git_repository/sync", "POST", s.hGitRepositorySyncPOST,
+		HTTPRouteOptions{Project: true})
+}
+
+func (s *APIHTTPServer) hGitRepositoryGET(h *HTTPHandler) {
+	projectId := *h.Context.ProjectId
+
+	var repo eventline.GitRepository
+
+	err := s.Pg.WithConn(func(conn pg.Conn) error {
+		return repo.Load(conn, projectId)
+	})
+	if err != nil {
+		var unknownGitRepositoryErr *eventline.UnknownGitRepositoryError
+
+		if errors.As(err, &unknownGitRepositoryErr) {
+			h.ReplyError(404, "unknown_git_repository", "%v", err)
+		} else {
+			h.ReplyInternalError(500, "cannot load git repository: %v", err)
+		}
+
+		return
+	}
+
+	h.ReplyJSON(200, &repo)
+}
+
+func (s *APIHTTPServer) hGitRepositoryPUT(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	var newRepo eventline.NewGitRepository
+	if err := h.JSONRequestData(&newRepo); err != nil {
+		return
+	}
+
+	var repo *eventline.GitRepository
+
+	err := s.Pg.WithTx(func(conn pg.Conn) (err error) {
+		repo, err = s.Service.UpdateGitRepository(conn, &newRepo, scope)
+		return
+	})
+	if err != nil {
+		var validationErrors ejson.ValidationErrors
+
+		if errors.As(err, &validationErrors) {
+			h.ReplyValidationErrors(validationErrors)
+		} else {
+			h.ReplyInternalError(500, "cannot update git repository: %v",
+				err)
+		}
+
+		return
+	}
+
+	if w := s.Service.FindWorker("git-repository-worker"); w != nil {
+		w.WakeUp()
+	}
+
+	h.ReplyJSON(200, repo)
+}
+
+func (s *APIHTTPServer) hGitRepositoryDELETE(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	err := s.Pg.WithTx(func(conn pg.Conn) error {
+		return s.Service.DeleteGitRepository(conn, scope)
+	})
+	if err != nil {
+		var unknownGitRepositoryErr *eventline.UnknownGitRepositoryError
+
+		if errors.As(err, &unknownGitRepositoryErr) {
+			h.ReplyError(404, "unknown_git_repository", "%v", err)
+		} else {
+			h.ReplyInternalError(500, "cannot delete git repository: %v", err)
+		}
+
+		return
+	}
+
+	h.ReplyEmpty(204)
+}
+
+func (s *APIHTTPServer) hGitRepositorySyncPOST(h *HTTPHandler) {
+	projectId := *h.Context.ProjectId
+
+	err := s.Pg.WithTx(func(conn pg.Conn) error {
+		var repo eventline.GitRepository
+		if err := repo.LoadForUpdate(conn, projectId); err != nil {
+			return err
+		}
+
+		err := eventline.ScheduleGitRepositorySync(conn, projectId)
+		if err != nil {
+			return fmt.Errorf("cannot schedule synchronization: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		var unknownGitRepositoryErr *eventline.UnknownGitRepositoryError
+
+		if errors.As(err, &unknownGitRepositoryErr) {
+			h.ReplyError(404, "unknown_git_repository", "%v", err)
+		} else {
+			h.ReplyInternalError(500, "%v", err)
+		}
+
+		return
+	}
+
+	if w := s.Service.FindWorker("git-repository-worker"); w != nil {
+		w.WakeUp()
+	}
+
+	h.ReplyEmpty(204)
+}
@@ -1,6 +1,17 @@
 package service
 
+import (
+	"fmt"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
 func (s *APIHTTPServer) setupJobExecutionRoutes() {
+	s.route("/job_executions", "GET", s.hJobExecutionsGET,
+		HTTPRouteOptions{Project: true})
+
 	s.route("/job_executions/id/{id}", "GET", s.hJobExecutionsIdGET,
 		HTTPRouteOptions{Project: true})
 
@@ -11,6 +22,50 @@ func (s *APIHTTPServer) setupJobExecutionRoutes() {
 	s.route("/job_executions/id/{id}/restart", "POST",
 		s.hJobExecutionsIdRestartPOST,
 		HTTPRouteOptions{Project: true})
+
+	s.route("/matrix_executions/id/{id}", "GET", s.hMatrixExecutionsIdGET,
+		HTTPRouteOptions{Project: true})
+}
+
+func (s *APIHTTPServer) hJobExecutionsGET(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	cursor, err := h.ParseCursor(eventline.JobExecutionSorts)
+	if err != nil {
+		return
+	}
+
+	pageOptions := eventline.JobExecutionPageOptions{
+		Delayed: h.HasQueryParameter("delayed"),
+	}
+
+	if s := h.QueryParameter("job_id"); s != "" {
+		var jobId uuid.UUID
+		if err := jobId.Parse(s); err != nil {
+			h.ReplyError(400, "invalid_query_parameter",
+				"invalid job id: %v", err)
+			return
+		}
+
+		pageOptions.JobId = &jobId
+	}
+
+	var page *eventline.Page
+
+	err = s.Pg.WithConn(func(conn pg.Conn) (err error) {
+		page, err = eventline.LoadJobExecutionPage(conn, pageOptions, cursor,
+			scope)
+		if err != nil {
+			err = fmt.Errorf("cannot load job executions: %w", err)
+		}
+		return
+	})
+	if err != nil {
+		h.ReplyInternalError(500, "%v", err)
+		return
+	}
+
+	h.ReplyJSON(200, page)
 }
 
 func (s *APIHTTPServer) hJobExecutionsIdGET(h *HTTPHandler) {
@@ -46,9 +101,33 @@ func (s *APIHTTPServer) hJobExecutionsIdRestartPOST(h *HTTPHandler) {
 		return
 	}
 
-	if err := s.RestartJobExecution(h, jeId); err != nil {
+	// The request body is optional; it is only used to provide the values
+	// of secret parameters.
+	var input *eventline.JobExecutionInput
+	if h.Request.ContentLength != 0 {
+		input = &eventline.JobExecutionInput{}
+		if err := h.JSONRequestData(input); err != nil {
+			return
+		}
+	}
+
+	if err := s.RestartJobExecution(h, jeId, input); err != nil {
 		return
 	}
 
 	h.ReplyEmpty(204)
 }
+
+func (s *APIHTTPServer) hMatrixExecutionsIdGET(h *HTTPHandler) {
+	matrixId, err := h.IdPathVariable("id")
+	if err != nil {
+		return
+	}
+
+	me, err := s.LoadMatrixExecution(h, matrixId)
+	if err != nil {
+		return
+	}
+
+	h.ReplyJSON(200, me)
+}
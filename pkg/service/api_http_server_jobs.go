@@ -40,6 +40,17 @@ func (s *APIHTTPServer) setupJobRoutes() {
 
 	s.route("/jobs/id/{id}/execute", "POST", s.hJobsIdExecutePOST,
 		HTTPRouteOptions{Project: true})
+
+	s.route("/jobs/id/{id}/versions", "GET", s.hJobsIdVersionsGET,
+		HTTPRouteOptions{Project: true})
+
+	s.route("/jobs/id/{id}/versions/{version}", "GET",
+		s.hJobsIdVersionsVersionGET,
+		HTTPRouteOptions{Project: true})
+
+	s.route("/jobs/id/{id}/versions/{version}/rollback", "POST",
+		s.hJobsIdVersionsVersionRollbackPOST,
+		HTTPRouteOptions{Project: true})
 }
 
 func (s *APIHTTPServer) hJobsGET(h *HTTPHandler) {
@@ -118,10 +129,15 @@ func (s *APIHTTPServer) hJobsPUT(h *HTTPHandler) {
 			return nil
 		}
 
+		origin := eventline.JobVersionOrigin{
+			Source:    eventline.JobVersionSourceDeployment,
+			AccountId: h.Context.AccountId,
+		}
+
 		for i, spec := range specs {
 			var err error
 			job, subscriptionCreatedOrUpdated, err :=
-				s.Service.CreateOrUpdateJob(conn, spec, scope)
+				s.Service.CreateOrUpdateJob(conn, spec, &origin, scope)
 			if err != nil {
 				return fmt.Errorf("cannot create or update job: %w", err)
 			}
@@ -228,9 +244,14 @@ func (s *APIHTTPServer) hJobsNamePUT(h *HTTPHandler) {
 			return nil
 		}
 
+		origin := eventline.JobVersionOrigin{
+			Source:    eventline.JobVersionSourceDeployment,
+			AccountId: h.Context.AccountId,
+		}
+
 		var err error
 		job, subscriptionCreatedOrUpdated, err =
-			s.Service.CreateOrUpdateJob(conn, &spec, scope)
+			s.Service.CreateOrUpdateJob(conn, &spec, &origin, scope)
 		if err != nil {
 			return fmt.Errorf("cannot create or update job: %w", err)
 		}
@@ -325,3 +346,75 @@ func (s *APIHTTPServer) hJobsIdExecutePOST(h *HTTPHandler) {
 
 	h.ReplyJSON(200, jobExecution)
 }
+
+func (s *APIHTTPServer) hJobsIdVersionsGET(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	jobId, err := h.IdPathVariable("id")
+	if err != nil {
+		return
+	}
+
+	cursor, err := h.ParseCursor(eventline.JobVersionSorts)
+	if err != nil {
+		return
+	}
+
+	if _, err := s.LoadJob(h, jobId); err != nil {
+		return
+	}
+
+	var page *eventline.Page
+
+	err = s.Pg.WithConn(func(conn pg.Conn) (err error) {
+		page, err = eventline.LoadJobVersionPage(conn, jobId, cursor, scope)
+		if err != nil {
+			err = fmt.Errorf("cannot load job versions: %w", err)
+		}
+		return
+	})
+	if err != nil {
+		h.ReplyInternalError(500, "%v", err)
+		return
+	}
+
+	h.ReplyJSON(200, page)
+}
+
+func (s *APIHTTPServer) hJobsIdVersionsVersionGET(h *HTTPHandler) {
+	jobId, err := h.IdPathVariable("id")
+	if err != nil {
+		return
+	}
+
+	version, err := h.IntPathVariable("version")
+	if err != nil {
+		return
+	}
+
+	jobVersion, err := s.LoadJobVersion(h, jobId, version)
+	if err != nil {
+		return
+	}
+
+	h.ReplyJSON(200, jobVersion)
+}
+
+func (s *APIHTTPServer) hJobsIdVersionsVersionRollbackPOST(h *HTTPHandler) {
+	jobId, err := h.IdPathVariable("id")
+	if err != nil {
+		return
+	}
+
+	version, err := h.IntPathVariable("version")
+	if err != nil {
+		return
+	}
+
+	job, err := s.RollbackJob(h, jobId, version)
+	if err != nil {
+		return
+	}
+
+	h.ReplyJSON(200, job)
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
+)
+
+func (s *APIHTTPServer) setupProjectArchiveRoutes() {
+	s.route("/export", "POST", s.hExportPOST,
+		HTTPRouteOptions{Project: true})
+
+	s.route("/import", "POST", s.hImportPOST,
+		HTTPRouteOptions{Project: true})
+}
+
+func (s *APIHTTPServer) hExportPOST(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	var options eventline.ProjectExportOptions
+	if err := h.JSONRequestData(&options); err != nil {
+		return
+	}
+
+	// Secrets can only be exported by administrators, in the same way as
+	// project settings can only be imported by administrators.
+	if options.Passphrase != "" {
+		role := h.Context.AccountRole
+		if role == nil || *role != eventline.AccountRoleAdmin {
+			h.ReplyError(403, "permission_denied",
+				"admin role required to export secrets")
+			return
+		}
+	}
+
+	var archive *eventline.ProjectArchive
+
+	err := s.Service.Pg.WithTx(func(conn pg.Conn) (err error) {
+		archive, err = s.Service.ExportProject(conn, &options, scope)
+		return
+	})
+	if err != nil {
+		h.ReplyInternalError(500, "cannot export project: %v", err)
+		return
+	}
+
+	h.ReplyJSON(200, archive)
+}
+
+func (s *APIHTTPServer) hImportPOST(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	var options eventline.ProjectImportOptions
+
+	extraChecks := func(v *ejson.Validator) error {
+		ns := options.Archive.ProjectNotificationSettings
+		if ns == nil {
+			return nil
+		}
+
+		allowedDomains := s.Service.Cfg.Notifications.AllowedDomains
+
+		v.WithChild("archive", func() {
+			v.WithChild("project_notification_settings", func() {
+				ns.CheckEmailAddresses(v, allowedDomains)
+			})
+		})
+
+		return nil
+	}
+
+	data, err := h.RequestData()
+	if err != nil {
+		return
+	}
+
+	if err := h.JSONRequestDataExt(data, &options, extraChecks); err != nil {
+		return
+	}
+
+	// Project settings and project variables can only be modified by
+	// administrators, as in the project configuration page.
+	role := h.Context.AccountRole
+	importSettings := role != nil && *role == eventline.AccountRoleAdmin
+
+	dryRun := h.HasQueryParameter("dry-run")
+
+	var plan *eventline.ProjectSyncPlan
+	var subscriptionsCreatedOrUpdated bool
+
+	err = s.Service.Pg.WithTx(func(conn pg.Conn) error {
+		id1 := PgAdvisoryLockId1
+		id2 := PgAdvisoryLockId2JobDeployment
+
+		if err := pg.TakeAdvisoryTxLock(conn, id1, id2); err != nil {
+			return fmt.Errorf("cannot take advisory lock: %w", err)
+		}
+
+		var err error
+		plan, subscriptionsCreatedOrUpdated, err =
+			s.Service.ImportProject(conn, &options, importSettings,
+				h.Context.AccountId, scope)
+		if err != nil {
+			return fmt.Errorf("cannot import project: %w", err)
+		}
+
+		// Importing identities and jobs requires the database to be
+		// modified, so dry runs are executed entirely before being rolled
+		// back.
+		if dryRun {
+			return errDryRun
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		var validationErrors ejson.ValidationErrors
+		var conflictErr *ProjectImportConflictError
+
+		if errors.As(err, &validationErrors) {
+			h.ReplyValidationErrors(validationErrors)
+		} else if errors.As(err, &conflictErr) {
+			h.ReplyError(400, "project_import_conflict", "%v", err)
+		} else if errors.Is(err, eventline.ErrInvalidPassphrase) {
+			h.ReplyError(400, "invalid_passphrase", "%v", err)
+		} else {
+			h.ReplyInternalError(500, "%v", err)
+		}
+
+		return
+	}
+
+	if subscriptionsCreatedOrUpdated && !dryRun {
+		if w := s.Service.FindWorker("subscription-worker"); w != nil {
+			w.WakeUp()
+		}
+	}
+
+	h.ReplyJSON(200, plan)
+}
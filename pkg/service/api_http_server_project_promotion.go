@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
+)
+
+func (s *APIHTTPServer) setupProjectPromotionRoutes() {
+	s.route("/promote", "POST", s.hPromotePOST,
+		HTTPRouteOptions{Project: true})
+}
+
+func (s *APIHTTPServer) hPromotePOST(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	var data eventline.JobPromotionData
+	if err := h.JSONRequestData(&data); err != nil {
+		return
+	}
+
+	dryRun := h.HasQueryParameter("dry-run")
+
+	var plan *eventline.ProjectSyncPlan
+	var subscriptionsCreatedOrUpdated bool
+
+	err := s.Service.Pg.WithTx(func(conn pg.Conn) error {
+		id1 := PgAdvisoryLockId1
+		id2 := PgAdvisoryLockId2JobDeployment
+
+		if err := pg.TakeAdvisoryTxLock(conn, id1, id2); err != nil {
+			return fmt.Errorf("cannot take advisory lock: %w", err)
+		}
+
+		var err error
+		plan, subscriptionsCreatedOrUpdated, err =
+			s.Service.PromoteJobs(conn, &data, h.Context.AccountId, scope)
+		if err != nil {
+			return fmt.Errorf("cannot promote jobs: %w", err)
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		var validationErrors ejson.ValidationErrors
+		var unknownProjectErr *eventline.UnknownProjectError
+
+		if errors.As(err, &validationErrors) {
+			h.ReplyValidationErrors(validationErrors)
+		} else if errors.As(err, &unknownProjectErr) {
+			h.ReplyError(404, "unknown_project", "%v", err)
+		} else {
+			h.ReplyInternalError(500, "%v", err)
+		}
+
+		return
+	}
+
+	if subscriptionsCreatedOrUpdated && !dryRun {
+		if w := s.Service.FindWorker("subscription-worker"); w != nil {
+			w.WakeUp()
+		}
+	}
+
+	h.ReplyJSON(200, plan)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
+)
+
+func (s *APIHTTPServer) setupProjectSyncRoutes() {
+	s.route("/sync", "POST", s.hSyncPOST,
+		HTTPRouteOptions{Project: true})
+}
+
+func (s *APIHTTPServer) hSyncPOST(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	var spec eventline.ProjectSyncSpec
+	if err := h.JSONRequestData(&spec); err != nil {
+		return
+	}
+
+	// Project settings can only be modified by administrators, as in the
+	// project configuration page.
+	if spec.ProjectSettings != nil {
+		role := h.Context.AccountRole
+		if role == nil || *role != eventline.AccountRoleAdmin {
+			h.ReplyError(403, "permission_denied",
+				"admin role required to update project settings")
+			return
+		}
+	}
+
+	dryRun := h.HasQueryParameter("dry-run")
+
+	var plan *eventline.ProjectSyncPlan
+	var subscriptionsCreatedOrUpdated bool
+
+	err := s.Service.Pg.WithTx(func(conn pg.Conn) error {
+		id1 := PgAdvisoryLockId1
+		id2 := PgAdvisoryLockId2JobDeployment
+
+		if err := pg.TakeAdvisoryTxLock(conn, id1, id2); err != nil {
+			return fmt.Errorf("cannot take advisory lock: %w", err)
+		}
+
+		var err error
+		plan, subscriptionsCreatedOrUpdated, err =
+			s.Service.SyncProject(conn, &spec, dryRun, h.Context.AccountId,
+				scope)
+		if err != nil {
+			return fmt.Errorf("cannot synchronize project: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		var validationErrors ejson.ValidationErrors
+
+		if errors.As(err, &validationErrors) {
+			h.ReplyValidationErrors(validationErrors)
+		} else {
+			h.ReplyInternalError(500, "%v", err)
+		}
+
+		return
+	}
+
+	if subscriptionsCreatedOrUpdated {
+		if w := s.Service.FindWorker("subscription-worker"); w != nil {
+			w.WakeUp()
+		}
+	}
+
+	h.ReplyJSON(200, plan)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/service/pkg/pg"
+)
+
+func (s *APIHTTPServer) setupProjectVariableRoutes() {
+	s.route("/project_variables", "GET", s.hProjectVariablesGET,
+		HTTPRouteOptions{Project: true})
+
+	s.route("/project_variables", "POST", s.hProjectVariablesPOST,
+		HTTPRouteOptions{Project: true, Admin: true})
+
+	s.route("/project_variables/name/{name}", "DELETE",
+		s.hProjectVariablesNameDELETE,
+		HTTPRouteOptions{Project: true, Admin: true})
+}
+
+func (s *APIHTTPServer) hProjectVariablesGET(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	var variables eventline.ProjectVariables
+
+	err := s.Pg.WithConn(func(conn pg.Conn) error {
+		return variables.LoadAll(conn, scope)
+	})
+	if err != nil {
+		h.ReplyInternalError(500, "cannot load project variables: %v", err)
+		return
+	}
+
+	if variables == nil {
+		variables = eventline.ProjectVariables{}
+	}
+
+	h.ReplyJSON(200, variables)
+}
+
+func (s *APIHTTPServer) hProjectVariablesPOST(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	var nv eventline.NewProjectVariable
+	if err := h.JSONRequestData(&nv); err != nil {
+		return
+	}
+
+	var variable *eventline.ProjectVariable
+
+	err := s.Pg.WithTx(func(conn pg.Conn) (err error) {
+		variable, err = s.Service.SetProjectVariable(conn, &nv, scope)
+		return
+	})
+	if err != nil {
+		h.ReplyInternalError(500, "%v", err)
+		return
+	}
+
+	h.ReplyJSON(200, variable)
+}
+
+func (s *APIHTTPServer) hProjectVariablesNameDELETE(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	name := h.PathVariable("name")
+
+	err := s.Pg.WithTx(func(conn pg.Conn) error {
+		return s.Service.DeleteProjectVariable(conn, name, scope)
+	})
+	if err != nil {
+		var unknownProjectVariableErr *eventline.UnknownProjectVariableError
+
+		if errors.As(err, &unknownProjectVariableErr) {
+			h.ReplyError(404, "unknown_project_variable", "%v", err)
+		} else {
+			h.ReplyInternalError(500, "%v", err)
+		}
+
+		return
+	}
+
+	h.ReplyEmpty(204)
+}
@@ -22,6 +22,9 @@ func (s *APIHTTPServer) setupProjectRoutes() {
 
 	s.route("/projects/id/{id}", "DELETE", s.hProjectsIdDELETE,
 		HTTPRouteOptions{Admin: true})
+
+	s.route("/projects/id/{id}/clone", "POST", s.hProjectsIdClonePOST,
+		HTTPRouteOptions{Admin: true})
 }
 
 func (s *APIHTTPServer) hProjectsGET(h *HTTPHandler) {
@@ -103,3 +106,22 @@ func (s *APIHTTPServer) hProjectsIdDELETE(h *HTTPHandler) {
 
 	h.ReplyEmpty(204)
 }
+
+func (s *APIHTTPServer) hProjectsIdClonePOST(h *HTTPHandler) {
+	projectId, err := h.IdPathVariable("id")
+	if err != nil {
+		return
+	}
+
+	var data eventline.ProjectCloningData
+	if err := h.JSONRequestData(&data); err != nil {
+		return
+	}
+
+	project, err := s.CloneProject(h, projectId, &data)
+	if err != nil {
+		return
+	}
+
+	h.ReplyJSON(201, project)
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
+)
+
+func (s *APIHTTPServer) setupStepTemplateRoutes() {
+	s.route("/step_templates", "GET", s.hStepTemplatesGET,
+		HTTPRouteOptions{Project: true})
+
+	s.route("/step_templates/name/{name}", "GET", s.hStepTemplatesNameGET,
+		HTTPRouteOptions{Project: true})
+
+	s.route("/step_templates/name/{name}", "PUT", s.hStepTemplatesNamePUT,
+		HTTPRouteOptions{Project: true})
+
+	s.route("/step_templates/name/{name}", "DELETE",
+		s.hStepTemplatesNameDELETE,
+		HTTPRouteOptions{Project: true})
+}
+
+func (s *APIHTTPServer) hStepTemplatesGET(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	cursor, err := h.ParseCursor(eventline.StepTemplateSorts)
+	if err != nil {
+		return
+	}
+
+	var page *eventline.Page
+
+	err = s.Pg.WithConn(func(conn pg.Conn) (err error) {
+		page, err = eventline.LoadStepTemplatePage(conn, cursor, scope)
+		if err != nil {
+			err = fmt.Errorf("cannot load step templates: %w", err)
+		}
+		return
+	})
+	if err != nil {
+		h.ReplyInternalError(500, "%v", err)
+		return
+	}
+
+	h.ReplyJSON(200, page)
+}
+
+func (s *APIHTTPServer) hStepTemplatesNameGET(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	name := h.PathVariable("name")
+
+	var template eventline.StepTemplate
+
+	err := s.Pg.WithConn(func(conn pg.Conn) error {
+		return template.LoadByName(conn, name, scope)
+	})
+	if err != nil {
+		var unknownStepTemplateNameErr *eventline.UnknownStepTemplateNameError
+
+		if errors.As(err, &unknownStepTemplateNameErr) {
+			h.ReplyError(404, "unknown_step_template", "%v", err)
+		} else {
+			h.ReplyInternalError(500, "cannot load step template: %v", err)
+		}
+
+		return
+	}
+
+	h.ReplyJSON(200, &template)
+}
+
+func (s *APIHTTPServer) hStepTemplatesNamePUT(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	var spec eventline.StepTemplateSpec
+	if err := h.JSONRequestData(&spec); err != nil {
+		return
+	}
+
+	if name := h.PathVariable("name"); spec.Name != name {
+		h.ReplyError(400, "step_template_name_mismatch",
+			"step template name %q does not match path name %q",
+			spec.Name, name)
+		return
+	}
+
+	dryRun := h.HasQueryParameter("dry-run")
+
+	var template *eventline.StepTemplate
+
+	err := s.Service.Pg.WithTx(func(conn pg.Conn) error {
+		// Deploying a step template affects jobs using it, so we use the
+		// same lock as job deployment.
+		id1 := PgAdvisoryLockId1
+		id2 := PgAdvisoryLockId2JobDeployment
+
+		if err := pg.TakeAdvisoryTxLock(conn, id1, id2); err != nil {
+			return fmt.Errorf("cannot take advisory lock: %w", err)
+		}
+
+		var err error
+		template, err = s.Service.CreateOrUpdateStepTemplate(conn, &spec,
+			scope)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		var validationErrors ejson.ValidationErrors
+
+		if errors.As(err, &validationErrors) {
+			h.ReplyValidationErrors(validationErrors)
+		} else {
+			h.ReplyInternalError(500,
+				"cannot create or update step template: %v", err)
+		}
+
+		return
+	}
+
+	if dryRun {
+		h.ReplyEmpty(204)
+		return
+	}
+
+	h.ReplyJSON(200, template)
+}
+
+func (s *APIHTTPServer) hStepTemplatesNameDELETE(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	name := h.PathVariable("name")
+
+	err := s.Service.Pg.WithTx(func(conn pg.Conn) error {
+		return s.Service.DeleteStepTemplate(conn, name, scope)
+	})
+	if err != nil {
+		var unknownStepTemplateNameErr *eventline.UnknownStepTemplateNameError
+		var stepTemplateInUseErr *StepTemplateInUseError
+
+		if errors.As(err, &unknownStepTemplateNameErr) {
+			h.ReplyError(404, "unknown_step_template", "%v", err)
+		} else if errors.As(err, &stepTemplateInUseErr) {
+			h.ReplyError(400, "step_template_in_use", "%v", err)
+		} else {
+			h.ReplyInternalError(500, "cannot delete step template: %v", err)
+		}
+
+		return
+	}
+
+	h.ReplyEmpty(204)
+}
@@ -46,6 +46,8 @@ type ServiceCfg struct {
 	AllowedRunners []string                   `json:"allowed_runners"`
 	Runners        map[string]json.RawMessage `json:"runners"`
 
+	AllowedGitProtocols []string `json:"allowed_git_protocols"`
+
 	Notifications *NotificationsCfg `json:"notifications"`
 
 	ProCfg ejson.Validatable `json:"pro"`
@@ -82,6 +84,8 @@ func DefaultServiceCfg() ServiceCfg {
 		JobExecutionRefreshInterval: 10,
 		JobExecutionTimeout:         120,
 
+		AllowedGitProtocols: []string{"https", "ssh"},
+
 		Notifications: DefaultNotificationsCfg(),
 	}
 }
@@ -146,5 +150,11 @@ func (cfg *ServiceCfg) Check(v *ejson.Validator, s *Service) {
 		}
 	})
 
+	v.WithChild("allowed_git_protocols", func() {
+		for i, p := range cfg.AllowedGitProtocols {
+			v.CheckStringValue(i, p, GitProtocolValues)
+		}
+	})
+
 	v.CheckObject("notifications", cfg.Notifications)
 }
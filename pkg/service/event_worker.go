@@ -2,7 +2,9 @@ package service
 
 import (
 	"fmt"
+	"time"
 
+	cgithub "github.com/exograd/eventline/pkg/connectors/github"
 	"github.com/exograd/eventline/pkg/eventline"
 	"go.n16f.net/log"
 	"go.n16f.net/service/pkg/pg"
@@ -30,39 +32,108 @@ func (ew *EventWorker) Start() error {
 func (ew *EventWorker) Stop() {
 }
 
+// ProcessJob processes both an event delivery and a debounced event so that
+// a continuous flow of events cannot prevent debounced events from being
+// processed.
 func (ew *EventWorker) ProcessJob() (bool, error) {
-	var processed bool
-	var jeCreated bool
+	deliveryProcessed, jeCreated, gitSyncScheduled, err :=
+		ew.processEventDelivery()
+	if err != nil {
+		return false, err
+	}
+
+	var deProcessed, deJeCreated bool
+
+	err = ew.Service.Pg.WithTx(func(conn pg.Conn) (err error) {
+		deProcessed, deJeCreated, err = ew.processDebouncedEvent(conn)
+		return
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if jeCreated || deJeCreated {
+		if w := ew.Service.FindWorker("job-scheduler"); w != nil {
+			w.WakeUp()
+		}
+	}
+
+	if gitSyncScheduled {
+		if w := ew.Service.FindWorker("git-repository-worker"); w != nil {
+			w.WakeUp()
+		}
+	}
+
+	return deliveryProcessed || deProcessed, nil
+}
+
+func (ew *EventWorker) processEventDelivery() (bool, bool, bool, error) {
+	var processed, jeCreated, gitSyncScheduled bool
 
 	err := ew.Service.Pg.WithTx(func(conn pg.Conn) error {
-		event, err := eventline.LoadEventForProcessing(conn)
+		delivery, err := eventline.LoadEventDeliveryForProcessing(conn)
 		if err != nil {
-			return fmt.Errorf("cannot load event: %w", err)
-		} else if event == nil {
+			return fmt.Errorf("cannot load event delivery: %w", err)
+		} else if delivery == nil {
 			return nil
 		}
 
-		ew.Log.Info("processing event %q", event.Id)
+		ew.Log.Info("processing event %q for job %q",
+			delivery.EventId, delivery.JobId)
 
-		scope := eventline.NewProjectScope(event.ProjectId)
+		scope := eventline.NewProjectScope(delivery.ProjectId)
 
-		jeCreated, err = ew.Service.ProcessEvent(conn, event, scope)
+		var event eventline.Event
+		if err := event.Load(conn, delivery.EventId, scope); err != nil {
+			return fmt.Errorf("cannot load event %q: %w",
+				delivery.EventId, err)
+		}
+
+		jeCreated, err = ew.Service.ProcessEventDelivery(conn, &event,
+			delivery, scope)
 		if err != nil {
 			return fmt.Errorf("cannot process event %q: %w", event.Id, err)
 		}
 
+		// Push events can trigger the synchronization of the Git repository
+		// bound to the project.
+		if pushEvent, ok := event.Data.(*cgithub.PushEvent); ok {
+			gitSyncScheduled, err = ew.Service.scheduleGitRepositorySyncForPush(
+				conn, delivery.ProjectId, pushEvent.Organization,
+				pushEvent.Repository, pushEvent.Branch)
+			if err != nil {
+				return err
+			}
+		}
+
 		processed = true
 		return nil
 	})
 	if err != nil {
-		return false, err
+		return false, false, false, err
 	}
 
-	if jeCreated {
-		if w := ew.Service.FindWorker("job-scheduler"); w != nil {
-			w.WakeUp()
-		}
+	return processed, jeCreated, gitSyncScheduled, nil
+}
+
+func (ew *EventWorker) processDebouncedEvent(conn pg.Conn) (bool, bool, error) {
+	now := time.Now().UTC()
+
+	de, err := eventline.LoadDebouncedEventForProcessing(conn, now)
+	if err != nil {
+		return false, false, fmt.Errorf("cannot load debounced event: %w", err)
+	} else if de == nil {
+		return false, false, nil
+	}
+
+	ew.Log.Info("processing debounced event %q for job %q",
+		de.EventId, de.JobId)
+
+	jeCreated, err := ew.Service.ProcessDebouncedEvent(conn, de)
+	if err != nil {
+		return false, false, fmt.Errorf("cannot process debounced event "+
+			"%q: %w", de.EventId, err)
 	}
 
-	return processed, nil
+	return true, jeCreated, nil
 }
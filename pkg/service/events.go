@@ -4,13 +4,15 @@ import (
 	"fmt"
 	"time"
 
+	ceventline "github.com/exograd/eventline/pkg/connectors/eventline"
 	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
 	"go.n16f.net/service/pkg/pg"
 	"go.n16f.net/uuid"
 )
 
 func (s *Service) ReplayEvent(eventId uuid.UUID, scope eventline.Scope) (*eventline.Event, error) {
-	var event eventline.Event
+	var event *eventline.Event
 
 	now := time.Now().UTC()
 
@@ -20,15 +22,49 @@ func (s *Service) ReplayEvent(eventId uuid.UUID, scope eventline.Scope) (*eventl
 			return fmt.Errorf("cannot load event: %w", err)
 		}
 
-		event = originalEvent
+		var err error
+		event, err = replayEvent(conn, &originalEvent, nil, false, now,
+			scope)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// ReplayEvents replays all events matching a selection. In dry run mode,
+// events are only counted. Selections matching more than
+// eventline.MaxEventReplayCount events are rejected.
+func (s *Service) ReplayEvents(selection *eventline.EventReplaySelection, dryRun bool, scope eventline.Scope) (*eventline.EventReplayResult, error) {
+	var result eventline.EventReplayResult
+
+	now := time.Now().UTC()
+
+	err := s.Pg.WithTx(func(conn pg.Conn) error {
+		originalEvents, err := eventline.LoadEvents(conn,
+			selection.PageOptions(), eventline.MaxEventReplayCount, scope)
+		if err != nil {
+			return fmt.Errorf("cannot load events: %w", err)
+		}
+
+		result.Count = len(originalEvents)
+
+		if dryRun {
+			return nil
+		}
+
+		result.Events = make(eventline.Events, len(originalEvents))
 
-		event.Id = uuid.MustGenerate(uuid.V7)
-		event.CreationTime = now
-		event.Processed = false
-		event.OriginalEventId = &eventId
+		for i, originalEvent := range originalEvents {
+			event, err := replayEvent(conn, originalEvent, selection.JobId,
+				selection.Failed, now, scope)
+			if err != nil {
+				return err
+			}
 
-		if err := event.Insert(conn); err != nil {
-			return fmt.Errorf("cannot insert event: %w", err)
+			result.Events[i] = event
 		}
 
 		return nil
@@ -37,16 +73,145 @@ func (s *Service) ReplayEvent(eventId uuid.UUID, scope eventline.Scope) (*eventl
 		return nil, err
 	}
 
+	if len(result.Events) > 0 {
+		if w := s.FindWorker("event-worker"); w != nil {
+			w.WakeUp()
+		}
+	}
+
+	return &result, nil
+}
+
+// replayEvent creates a copy of an event delivered to the jobs of the
+// original event which are visible in the scope, or only to a specific job
+// if one is provided. If failed is true, the event is only delivered to jobs
+// whose last execution for the original event failed.
+func replayEvent(conn pg.Conn, originalEvent *eventline.Event, jobId *uuid.UUID, failed bool, now time.Time, scope eventline.Scope) (*eventline.Event, error) {
+	var deliveries eventline.EventDeliveries
+
+	if failed {
+		err := deliveries.LoadFailedByEvent(conn, originalEvent.Id, scope)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load event deliveries: %w", err)
+		}
+	} else {
+		err := deliveries.LoadByEvent(conn, originalEvent.Id, scope)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load event deliveries: %w", err)
+		}
+	}
+
+	event := *originalEvent
+
+	event.Id = uuid.MustGenerate(uuid.V7)
+	event.JobIds = nil
+	event.CreationTime = now
+	event.Processed = false
+	event.OriginalEventId = &originalEvent.Id
+
+	// Events replayed from another project belong to the project replaying
+	// them.
+	if projectScope, ok := scope.(*eventline.ProjectScope); ok {
+		event.ProjectId = projectScope.ProjectId
+	}
+
+	if err := event.Insert(conn); err != nil {
+		return nil, fmt.Errorf("cannot insert event: %w", err)
+	}
+
+	for _, originalDelivery := range deliveries {
+		if jobId != nil && originalDelivery.JobId != *jobId {
+			continue
+		}
+
+		delivery := eventline.EventDelivery{
+			EventId:   event.Id,
+			JobId:     originalDelivery.JobId,
+			ProjectId: originalDelivery.ProjectId,
+		}
+
+		inserted, err := delivery.Insert(conn)
+		if err != nil {
+			return nil, fmt.Errorf("cannot insert event delivery: %w", err)
+		}
+
+		if inserted {
+			event.JobIds = append(event.JobIds, delivery.JobId)
+		}
+	}
+
 	return &event, nil
 }
 
-func (s *Service) ProcessEvent(conn pg.Conn, event *eventline.Event, scope eventline.Scope) (bool, error) {
+// CreateCustomEvents creates an eventline/custom event delivered to each job
+// of the project subscribed to the name of the event. Event data must match
+// the schema of every subscription; if one of them does not, no event is
+// created.
+func (s *Service) CreateCustomEvents(ne *eventline.NewCustomEvent, scope eventline.Scope) (eventline.Events, error) {
+	var events eventline.Events
+
+	now := time.Now().UTC()
+
+	eventTime := now
+	if ne.EventTime != nil {
+		eventTime = ne.EventTime.UTC()
+	}
+
+	err := s.Pg.WithTx(func(conn pg.Conn) error {
+		var subs eventline.Subscriptions
+		err := subs.LoadByEvent(conn, "eventline", "custom", scope)
+		if err != nil {
+			return fmt.Errorf("cannot load subscriptions: %w", err)
+		}
+
+		v := ejson.NewValidator()
+
+		var matchingSubs eventline.Subscriptions
+
+		for _, sub := range subs {
+			params := sub.Parameters.(*ceventline.CustomEventParameters)
+			if params.Name != ne.Name || sub.JobId == nil {
+				continue
+			}
+
+			params.CheckData(v, "data", ne.Data)
+
+			matchingSubs = append(matchingSubs, sub)
+		}
+
+		if err := v.Error(); err != nil {
+			return err
+		}
+
+		eventData := ceventline.CustomEvent{
+			Name: ne.Name,
+			Data: ne.Data,
+		}
+
+		events, err = eventline.DispatchEvent(conn, matchingSubs,
+			"eventline", "custom", &eventTime, &eventData)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(events) > 0 {
+		if w := s.FindWorker("event-worker"); w != nil {
+			w.WakeUp()
+		}
+	}
+
+	return events, nil
+}
+
+func (s *Service) ProcessEventDelivery(conn pg.Conn, event *eventline.Event, delivery *eventline.EventDelivery, scope eventline.Scope) (bool, error) {
 	var jeCreated bool
 
 	// Load the job
 	var job eventline.Job
-	if err := job.Load(conn, event.JobId, scope); err != nil {
-		return false, fmt.Errorf("cannot load job %q: %w", event.JobId, err)
+	if err := job.Load(conn, delivery.JobId, scope); err != nil {
+		return false, fmt.Errorf("cannot load job %q: %w", delivery.JobId, err)
 	}
 
 	// Instantiate the job if the job is enabled and filters match
@@ -57,27 +222,140 @@ func (s *Service) ProcessEvent(conn pg.Conn, event *eventline.Event, scope event
 	// the database. If the update removes the trigger, we end up with an
 	// event referencing a job which does not have a trigger anymore.
 	if !job.Disabled {
-		filtersMatch := true
-		if trigger := job.Spec.Trigger; trigger != nil {
-			filtersMatch = trigger.Filters.Match(event.DataValue)
+		trigger := job.Spec.Trigger
+
+		instantiate := true
+		if trigger != nil {
+			instantiate = trigger.Filters.Match(event.DataValue)
+		}
+
+		// Replayed events are explicitly requested and must not be
+		// considered as duplicates of the original events.
+		replay := event.OriginalEventId != nil
+
+		if instantiate && trigger != nil && trigger.Deduplication != nil &&
+			!replay {
+			unique, err := s.deduplicateEvent(conn, &job, event)
+			if err != nil {
+				return false, err
+			}
+
+			instantiate = unique
+		}
+
+		if instantiate && trigger != nil && trigger.Debounce != nil {
+			if err := s.debounceEvent(conn, &job, event); err != nil {
+				return false, err
+			}
+
+			// The job will be instantiated by the event worker once the
+			// debounce delay has elapsed.
+			instantiate = false
 		}
 
-		if filtersMatch {
-			_, err := s.InstantiateJob(conn, &job, event, nil, scope)
+		if instantiate {
+			_, err := s.InstantiateJob(conn, &job, event, nil, nil, scope)
 			if err != nil {
 				return false, fmt.Errorf("cannot instantiate job %q: %w",
-					event.JobId, err)
+					delivery.JobId, err)
 			}
 
 			jeCreated = true
 		}
 	}
 
-	// Mark the event as processed
-	event.Processed = true
+	// Mark the delivery as processed
+	delivery.Processed = true
+
+	if err := delivery.Update(conn); err != nil {
+		return false, fmt.Errorf("cannot update delivery of event %q: %w",
+			event.Id, err)
+	}
+
+	return jeCreated, nil
+}
+
+func (s *Service) deduplicateEvent(conn pg.Conn, job *eventline.Job, event *eventline.Event) (bool, error) {
+	dedup := job.Spec.Trigger.Deduplication
+
+	key, found := eventline.EventKey(dedup.Key, event.DataValue)
+	if !found {
+		// Events without key cannot be duplicates
+		return true, nil
+	}
+
+	now := time.Now().UTC()
+	window := time.Duration(dedup.Window) * time.Second
+
+	unique, err := eventline.InsertEventDeduplicationKey(conn, job.Id, key,
+		now, window)
+	if err != nil {
+		return false, fmt.Errorf("cannot insert deduplication key: %w", err)
+	}
+
+	if !unique {
+		s.Log.Info("ignoring duplicate event %q for job %q",
+			event.Id, job.Spec.Name)
+	}
+
+	return unique, nil
+}
+
+func (s *Service) debounceEvent(conn pg.Conn, job *eventline.Job, event *eventline.Event) error {
+	debounce := job.Spec.Trigger.Debounce
+
+	var key string
+	if len(debounce.Key) > 0 {
+		// Events without key are debounced together
+		key, _ = eventline.EventKey(debounce.Key, event.DataValue)
+	}
+
+	now := time.Now().UTC()
+	delay := time.Duration(debounce.Delay) * time.Second
+
+	de := eventline.DebouncedEvent{
+		JobId:         job.Id,
+		Key:           key,
+		ProjectId:     job.ProjectId,
+		EventId:       event.Id,
+		ScheduledTime: now.Add(delay),
+	}
+
+	if err := de.Upsert(conn); err != nil {
+		return fmt.Errorf("cannot upsert debounced event: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) ProcessDebouncedEvent(conn pg.Conn, de *eventline.DebouncedEvent) (bool, error) {
+	var jeCreated bool
+
+	scope := eventline.NewProjectScope(de.ProjectId)
+
+	var event eventline.Event
+	if err := event.Load(conn, de.EventId, scope); err != nil {
+		return false, fmt.Errorf("cannot load event %q: %w", de.EventId, err)
+	}
+
+	var job eventline.Job
+	if err := job.Load(conn, de.JobId, scope); err != nil {
+		return false, fmt.Errorf("cannot load job %q: %w", de.JobId, err)
+	}
+
+	// Filters and deduplication were applied when the event was processed
+	if !job.Disabled {
+		_, err := s.InstantiateJob(conn, &job, &event, nil, nil, scope)
+		if err != nil {
+			return false, fmt.Errorf("cannot instantiate job %q: %w",
+				de.JobId, err)
+		}
+
+		jeCreated = true
+	}
 
-	if err := event.Update(conn); err != nil {
-		return false, fmt.Errorf("cannot update event %q: %w", event.Id, err)
+	if err := de.Delete(conn); err != nil {
+		return false, fmt.Errorf("cannot delete debounced event: %w", err)
 	}
 
 	return jeCreated, nil
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/exograd/eventline/pkg/utils"
+	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+const gitCloneTimeout = 5 * time.Minute
+
+// The protocols which can be allowed for Git repositories. Remote helpers such
+// as "ext" are never allowed since they run arbitrary commands.
+var GitProtocolValues = []string{"file", "git", "http", "https", "ssh"}
+
+type InvalidGitRepositoryJobsError struct {
+	Messages []string
+}
+
+func (err InvalidGitRepositoryJobsError) Error() string {
+	return strings.Join(err.Messages, "\n")
+}
+
+type gitRepositoryJobFile struct {
+	Path string // relative to the root of the repository
+	Spec *eventline.JobSpec
+}
+
+func (s *Service) UpdateGitRepository(conn pg.Conn, nr *eventline.NewGitRepository, scope eventline.Scope) (*eventline.GitRepository, error) {
+	projectId := scope.(*eventline.ProjectScope).ProjectId
+
+	v := ejson.NewValidator()
+	protocol := utils.GitURIProtocol(nr.URI)
+	v.Check("uri", utils.StringsContain(s.Cfg.AllowedGitProtocols, protocol),
+		"forbidden_git_protocol", "git protocol %q is not allowed", protocol)
+	if err := v.Error(); err != nil {
+		return nil, err
+	}
+
+	var repo eventline.GitRepository
+	if err := repo.LoadForUpdate(conn, projectId); err != nil {
+		var unknownGitRepositoryErr *eventline.UnknownGitRepositoryError
+		if !errors.As(err, &unknownGitRepositoryErr) {
+			return nil, fmt.Errorf("cannot load git repository: %w", err)
+		}
+
+		repo.ProjectId = projectId
+	}
+
+	// The status of the last synchronization is meaningless if the
+	// repository changes.
+	if nr.URI != repo.URI || nr.Branch != repo.Branch ||
+		nr.Directory != repo.Directory {
+		repo.LastSyncTime = nil
+		repo.LastCommit = ""
+		repo.LastError = ""
+	}
+
+	repo.URI = nr.URI
+	repo.Branch = nr.Branch
+	repo.Directory = nr.Directory
+	repo.SyncInterval = nr.SyncInterval
+	repo.NextSyncTime = time.Now().UTC()
+
+	if err := repo.Upsert(conn); err != nil {
+		return nil, fmt.Errorf("cannot upsert git repository: %w", err)
+	}
+
+	return &repo, nil
+}
+
+func (s *Service) DeleteGitRepository(conn pg.Conn, scope eventline.Scope) error {
+	projectId := scope.(*eventline.ProjectScope).ProjectId
+
+	var repo eventline.GitRepository
+	if err := repo.LoadForUpdate(conn, projectId); err != nil {
+		return fmt.Errorf("cannot load git repository: %w", err)
+	}
+
+	if err := repo.Delete(conn); err != nil {
+		return fmt.Errorf("cannot delete git repository: %w", err)
+	}
+
+	return nil
+}
+
+// SyncGitRepository fetches the last commit of a repository and deploys the
+// jobs it contains. Errors caused by the content of the repository are not
+// returned but recorded in the repository so that they are visible to users.
+func (s *Service) SyncGitRepository(repo *eventline.GitRepository) (bool, error) {
+	dirPath, err := os.MkdirTemp("", "eventline-git-")
+	if err != nil {
+		return false, fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dirPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), gitCloneTimeout)
+	defer cancel()
+
+	var files []gitRepositoryJobFile
+
+	commit, syncErr := utils.GitClone(ctx, repo.URI, repo.Branch, dirPath,
+		s.Cfg.AllowedGitProtocols)
+	if syncErr == nil {
+		// Nothing to do if the repository has not changed since the last
+		// successful synchronization.
+		if commit == repo.LastCommit && repo.LastError == "" {
+			return false, nil
+		}
+
+		files, syncErr = loadGitRepositoryJobFiles(dirPath, repo.Directory)
+	}
+
+	var subscriptionsCreatedOrUpdated bool
+
+	err = s.Pg.WithTx(func(conn pg.Conn) error {
+		id1 := PgAdvisoryLockId1
+		id2 := PgAdvisoryLockId2JobDeployment
+
+		if err := pg.TakeAdvisoryTxLock(conn, id1, id2); err != nil {
+			return fmt.Errorf("cannot take advisory lock: %w", err)
+		}
+
+		// The binding may have been modified or deleted during the clone
+		var repo2 eventline.GitRepository
+		if err := repo2.LoadForUpdate(conn, repo.ProjectId); err != nil {
+			var unknownGitRepositoryErr *eventline.UnknownGitRepositoryError
+			if errors.As(err, &unknownGitRepositoryErr) {
+				return nil
+			}
+
+			return fmt.Errorf("cannot load git repository: %w", err)
+		}
+
+		if repo2.URI != repo.URI || repo2.Branch != repo.Branch ||
+			repo2.Directory != repo.Directory {
+			return nil
+		}
+
+		scope := eventline.NewProjectScope(repo.ProjectId)
+
+		if syncErr == nil {
+			var err error
+			subscriptionsCreatedOrUpdated, err =
+				s.deployGitRepositoryJobs(conn, files, commit, scope)
+			if err != nil {
+				var invalidJobsErr *InvalidGitRepositoryJobsError
+				if !errors.As(err, &invalidJobsErr) {
+					return err
+				}
+
+				syncErr = err
+			}
+		}
+
+		now := time.Now().UTC()
+		repo2.LastSyncTime = &now
+
+		if syncErr == nil {
+			repo2.LastCommit = commit
+			repo2.LastError = ""
+		} else {
+			repo2.LastError = syncErr.Error()
+		}
+
+		if err := repo2.Upsert(conn); err != nil {
+			return fmt.Errorf("cannot update git repository: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return subscriptionsCreatedOrUpdated, nil
+}
+
+func loadGitRepositoryJobFiles(rootPath, dirPath string) ([]gitRepositoryJobFile, error) {
+	filePaths, err := eventline.FindJobFiles(
+		[]string{filepath.Join(rootPath, dirPath)}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]gitRepositoryJobFile, len(filePaths))
+
+	for i, filePath := range filePaths {
+		relPath, err := filepath.Rel(rootPath, filePath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file path %q: %w", filePath, err)
+		}
+
+		spec, err := eventline.LoadJobFile(filePath, rootPath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load %q: %w", relPath, err)
+		}
+
+		files[i] = gitRepositoryJobFile{
+			Path: relPath,
+			Spec: spec,
+		}
+	}
+
+	return files, nil
+}
+
+// deployGitRepositoryJobs deploys jobs which are new or have changed and
+// deletes jobs previously deployed from the repository which are not part of
+// it anymore. Invalid job files are reported as a synchronization error and
+// prevent any modification.
+func (s *Service) deployGitRepositoryJobs(conn pg.Conn, files []gitRepositoryJobFile, commit string, scope eventline.Scope) (bool, error) {
+	var errMsgs []string
+
+	filePaths := make(map[string]string)
+
+	for _, file := range files {
+		spec := file.Spec
+
+		if filePath, found := filePaths[spec.Name]; found {
+			errMsgs = append(errMsgs, fmt.Sprintf("%s: job %q is already "+
+				"defined in %s", file.Path, spec.Name, filePath))
+			continue
+		}
+
+		filePaths[spec.Name] = file.Path
+
+		if err := s.ValidateJobSpec(conn, spec, scope); err != nil {
+			var verrs ejson.ValidationErrors
+			if !errors.As(err, &verrs) {
+				return false, fmt.Errorf("cannot validate job %q: %w",
+					spec.Name, err)
+			}
+
+			for _, verr := range verrs {
+				errMsgs = append(errMsgs,
+					fmt.Sprintf("%s: %v", file.Path, verr))
+			}
+		}
+	}
+
+	if len(errMsgs) > 0 {
+		return false, &InvalidGitRepositoryJobsError{Messages: errMsgs}
+	}
+
+	var jobs eventline.Jobs
+	if err := jobs.LoadAllForUpdate(conn, scope); err != nil {
+		return false, fmt.Errorf("cannot load jobs: %w", err)
+	}
+
+	jobTable := make(map[string]*eventline.Job)
+	for _, job := range jobs {
+		jobTable[job.Spec.Name] = job
+	}
+
+	origin := eventline.JobVersionOrigin{
+		Source:    eventline.JobVersionSourceGit,
+		GitCommit: commit,
+	}
+
+	var subscriptionsCreatedOrUpdated bool
+
+	for _, file := range files {
+		// Jobs which have not changed keep the commit they were last
+		// modified in.
+		if job, found := jobTable[file.Spec.Name]; found && job.GitCommit != "" {
+			equal, err := s.jobSpecsEqual(conn, job.Spec, file.Spec,
+				scope)
+			if err != nil {
+				return false, err
+			} else if equal {
+				continue
+			}
+		}
+
+		_, subscriptionCreatedOrUpdated, err := s.CreateOrUpdateJob(conn,
+			file.Spec, &origin, scope)
+		if err != nil {
+			return false, fmt.Errorf("cannot create or update job %q: %w",
+				file.Spec.Name, err)
+		}
+
+		if subscriptionCreatedOrUpdated {
+			subscriptionsCreatedOrUpdated = true
+		}
+	}
+
+	// Only delete jobs deployed from the repository; jobs deployed manually
+	// are left untouched.
+	for _, job := range jobs {
+		if _, found := filePaths[job.Spec.Name]; found || job.GitCommit == "" {
+			continue
+		}
+
+		if err := s.DeleteJob(conn, job, scope); err != nil {
+			return false, fmt.Errorf("cannot delete job %q: %w",
+				job.Spec.Name, err)
+		}
+	}
+
+	return subscriptionsCreatedOrUpdated, nil
+}
+
+// scheduleGitRepositorySyncForPush requests the synchronization of the
+// repository bound to a project when a GitHub push event matches it.
+func (s *Service) scheduleGitRepositorySyncForPush(conn pg.Conn, projectId uuid.UUID, organization, repository, branch string) (bool, error) {
+	var repo eventline.GitRepository
+	if err := repo.Load(conn, projectId); err != nil {
+		var unknownGitRepositoryErr *eventline.UnknownGitRepositoryError
+		if errors.As(err, &unknownGitRepositoryErr) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("cannot load git repository: %w", err)
+	}
+
+	org, name, ok := repo.GitHubRepository()
+	if !ok || !strings.EqualFold(org, organization) ||
+		!strings.EqualFold(name, repository) || repo.Branch != branch {
+		return false, nil
+	}
+
+	if err := eventline.ScheduleGitRepositorySync(conn, projectId); err != nil {
+		return false, fmt.Errorf("cannot schedule git repository "+
+			"synchronization: %w", err)
+	}
+
+	return true, nil
+}
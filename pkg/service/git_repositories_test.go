@@ -0,0 +1,179 @@
+package service
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.n16f.net/service/pkg/pg"
+)
+
+func TestSyncGitRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	project := createTestProject(t, "")
+	scope := eventline.NewProjectScope(project.Id)
+
+	dirPath := t.TempDir()
+
+	git := func(dirPath string, args ...string) string {
+		args = append([]string{"-c", "user.name=test",
+			"-c", "user.email=test@example.com"}, args...)
+
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dirPath
+
+		output, err := cmd.CombinedOutput()
+		require.NoError(err, string(output))
+
+		return strings.TrimSpace(string(output))
+	}
+
+	writeFile := func(name, content string) {
+		filePath := filepath.Join(dirPath, "work", name)
+		require.NoError(os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(os.WriteFile(filePath, []byte(content), 0644))
+	}
+
+	commit := func() string {
+		workPath := filepath.Join(dirPath, "work")
+		git(workPath, "add", "--all")
+		git(workPath, "commit", "--quiet", "-m", "update")
+		git(workPath, "push", "--quiet", "../repo.git", "main")
+		return git(workPath, "rev-parse", "HEAD")
+	}
+
+	repoPath := filepath.Join(dirPath, "repo.git")
+	git("", "init", "--quiet", "--bare", "--initial-branch", "main",
+		repoPath)
+	git("", "init", "--quiet", "--initial-branch", "main",
+		filepath.Join(dirPath, "work"))
+
+	loadJobs := func() map[string]*eventline.Job {
+		var jobs eventline.Jobs
+		err := testService.Pg.WithConn(func(conn pg.Conn) error {
+			return jobs.LoadAllForUpdate(conn, scope)
+		})
+		require.NoError(err)
+
+		table := make(map[string]*eventline.Job)
+		for _, job := range jobs {
+			table[job.Spec.Name] = job
+		}
+
+		return table
+	}
+
+	sync := func() *eventline.GitRepository {
+		var repo eventline.GitRepository
+		err := testService.Pg.WithConn(func(conn pg.Conn) error {
+			return repo.Load(conn, project.Id)
+		})
+		require.NoError(err)
+
+		_, err = testService.SyncGitRepository(&repo)
+		require.NoError(err)
+
+		err = testService.Pg.WithConn(func(conn pg.Conn) error {
+			return repo.Load(conn, project.Id)
+		})
+		require.NoError(err)
+
+		return &repo
+	}
+
+	// Initial synchronization
+	writeFile("README", "test\n")
+	writeFile("jobs/a.yaml", "name: a\nsteps:\n  - script:\n      path: a.sh\n")
+	writeFile("jobs/a.sh", "echo a\n")
+	writeFile("jobs/nested/b.yaml", "name: b\nsteps:\n  - code: echo b\n")
+	commit1 := commit()
+
+	err := testService.Pg.WithTx(func(conn pg.Conn) error {
+		newRepo := eventline.NewGitRepository{
+			URI:          repoPath,
+			Branch:       "main",
+			Directory:    "jobs",
+			SyncInterval: 60,
+		}
+
+		_, err := testService.UpdateGitRepository(conn, &newRepo, scope)
+		return err
+	})
+	require.NoError(err)
+
+	repo := sync()
+	assert.Equal(commit1, repo.LastCommit)
+	assert.Equal("", repo.LastError)
+
+	jobs := loadJobs()
+	if assert.Len(jobs, 2) {
+		assert.Equal(commit1, jobs["a"].GitCommit)
+		assert.Equal("echo a\n", jobs["a"].Spec.Steps[0].Script.Content)
+		assert.Equal(commit1, jobs["b"].GitCommit)
+	}
+
+	// Jobs deployed manually are not deleted
+	err = testService.Pg.WithTx(func(conn pg.Conn) error {
+		spec := eventline.JobSpec{
+			Name:  "manual",
+			Steps: eventline.Steps{{Code: "true"}},
+		}
+
+		origin := eventline.JobVersionOrigin{
+			Source: eventline.JobVersionSourceDeployment,
+		}
+
+		_, _, err := testService.CreateOrUpdateJob(conn, &spec, &origin,
+			scope)
+		return err
+	})
+	require.NoError(err)
+
+	// Modification and deletion
+	writeFile("jobs/a.sh", "echo a2\n")
+	require.NoError(os.Remove(filepath.Join(dirPath, "work/jobs/nested/b.yaml")))
+	commit2 := commit()
+
+	repo = sync()
+	assert.Equal(commit2, repo.LastCommit)
+	assert.Equal("", repo.LastError)
+
+	jobs = loadJobs()
+	if assert.Len(jobs, 2) {
+		assert.Equal(commit2, jobs["a"].GitCommit)
+		assert.Equal("echo a2\n", jobs["a"].Spec.Steps[0].Script.Content)
+		assert.Equal("", jobs["manual"].GitCommit)
+	}
+
+	// Invalid jobs do not modify the project
+	writeFile("jobs/c.yaml", "name: c\nsteps: []\n")
+	writeFile("jobs/d.yaml", "name: a\nsteps:\n  - code: echo d\n")
+	commit()
+
+	repo = sync()
+	assert.Equal(commit2, repo.LastCommit)
+	assert.Contains(repo.LastError, "jobs/d.yaml")
+
+	// Scripts cannot be read outside of the repository
+	require.NoError(os.Remove(filepath.Join(dirPath, "work/jobs/c.yaml")))
+	writeFile("jobs/d.yaml", "name: d\nsteps:\n  - script:\n      "+
+		"path: ../../../../../../../../etc/passwd\n")
+	commit()
+
+	repo = sync()
+	assert.Equal(commit2, repo.LastCommit)
+	assert.Contains(repo.LastError, "is not located in")
+
+	assert.Len(loadJobs(), 2)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/log"
+	"go.n16f.net/service/pkg/pg"
+)
+
+type GitRepositoryWorker struct {
+	Log     *log.Logger
+	Service *Service
+}
+
+func NewGitRepositoryWorker(s *Service) *GitRepositoryWorker {
+	return &GitRepositoryWorker{
+		Service: s,
+	}
+}
+
+func (gw *GitRepositoryWorker) Init(w *eventline.Worker) {
+	gw.Log = w.Log
+}
+
+func (gw *GitRepositoryWorker) Start() error {
+	return nil
+}
+
+func (gw *GitRepositoryWorker) Stop() {
+}
+
+func (gw *GitRepositoryWorker) ProcessJob() (bool, error) {
+	var repo *eventline.GitRepository
+
+	// Cloning a repository can take a long time, so we schedule the next
+	// synchronization and commit before doing anything else: we do not want
+	// to keep a transaction open during the clone.
+	err := gw.Service.Pg.WithTx(func(conn pg.Conn) error {
+		var err error
+		repo, err = eventline.LoadGitRepositoryForSync(conn)
+		if err != nil {
+			return fmt.Errorf("cannot load git repository: %w", err)
+		} else if repo == nil {
+			return nil
+		}
+
+		interval := time.Duration(repo.SyncInterval) * time.Second
+		repo.NextSyncTime = time.Now().UTC().Add(interval)
+
+		if err := repo.Upsert(conn); err != nil {
+			return fmt.Errorf("cannot update git repository: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	} else if repo == nil {
+		return false, nil
+	}
+
+	gw.Log.Info("synchronizing project %q with git repository %q",
+		repo.ProjectId, repo.URI)
+
+	subscriptionsCreatedOrUpdated, err := gw.Service.SyncGitRepository(repo)
+	if err != nil {
+		return false, fmt.Errorf("cannot synchronize git repository: %w", err)
+	}
+
+	if subscriptionsCreatedOrUpdated {
+		if w := gw.Service.FindWorker("subscription-worker"); w != nil {
+			w.WakeUp()
+		}
+	}
+
+	return true, nil
+}
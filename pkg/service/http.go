@@ -109,6 +109,73 @@ func (h *HTTPHandler) TimestampQueryParameter(name string) (*time.Time, error) {
 	return &t, nil
 }
 
+func (h *HTTPHandler) ParseEventPageOptions() (*eventline.EventPageOptions, error) {
+	var options eventline.EventPageOptions
+
+	replyError := func(format string, args ...interface{}) error {
+		err := fmt.Errorf(format, args...)
+		h.ReplyError(400, "invalid_query_parameter", "%v", err)
+		return err
+	}
+
+	options.Connector = h.QueryParameter("connector")
+	options.Name = h.QueryParameter("name")
+
+	switch s := h.QueryParameter("processed"); s {
+	case "":
+	case "true", "false":
+		processed := s == "true"
+		options.Processed = &processed
+	default:
+		return nil, replyError("invalid processed value %q", s)
+	}
+
+	parseTime := func(name string) (*time.Time, error) {
+		s := h.QueryParameter(name)
+		if s == "" {
+			return nil, nil
+		}
+
+		t, err := eventline.ParseParameterDatetime(s)
+		if err != nil {
+			return nil, replyError("invalid %s datetime %q", name, s)
+		}
+
+		return &t, nil
+	}
+
+	var err error
+
+	if options.StartTime, err = parseTime("start"); err != nil {
+		return nil, err
+	}
+
+	if options.EndTime, err = parseTime("end"); err != nil {
+		return nil, err
+	}
+
+	options.Failed = h.HasQueryParameter("failed")
+
+	for _, s := range h.Request.URL.Query()["filter"] {
+		if s == "" {
+			continue
+		}
+
+		var filter eventline.Filter
+		if err := filter.Parse(s); err != nil {
+			return nil, replyError("invalid filter %q: %v", s, err)
+		}
+
+		if err := filter.CheckSQLRegexps(); err != nil {
+			return nil, replyError("invalid filter %q: %v", s, err)
+		}
+
+		options.Filters = append(options.Filters, &filter)
+	}
+
+	return &options, nil
+}
+
 type HTTPRouteOptions struct {
 	Public  bool
 	Admin   bool
@@ -428,6 +495,18 @@ func (h *HTTPHandler) IdPathVariable(name string) (id uuid.UUID, err error) {
 	return
 }
 
+func (h *HTTPHandler) IntPathVariable(name string) (i int, err error) {
+	value := h.PathVariable(name)
+
+	i, err = strconv.Atoi(value)
+	if err != nil {
+		err = fmt.Errorf("invalid route variable: invalid integer: %w", err)
+		h.ReplyError(400, "invalid_route_variable", "%v", err)
+	}
+
+	return
+}
+
 func (h *HTTPHandler) SetSessionCookie(cookie *http.Cookie) {
 	header := h.ResponseWriter.Header()
 	header.Set("Set-Cookie", cookie.String())
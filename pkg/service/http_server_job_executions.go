@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
 	"go.n16f.net/service/pkg/pg"
 	"go.n16f.net/uuid"
 )
@@ -36,6 +37,33 @@ func (s *HTTPServer) LoadJobExecution(h *HTTPHandler, jeId uuid.UUID) (*eventlin
 	return &je, nil
 }
 
+func (s *HTTPServer) LoadMatrixExecution(h *HTTPHandler, matrixId uuid.UUID) (*eventline.MatrixExecution, error) {
+	scope := h.Context.ProjectScope()
+
+	var me eventline.MatrixExecution
+
+	err := s.Pg.WithConn(func(conn pg.Conn) error {
+		if err := me.Load(conn, matrixId, scope); err != nil {
+			return fmt.Errorf("cannot load matrix execution: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		var unknownMatrixExecutionErr *eventline.UnknownMatrixExecutionError
+
+		if errors.As(err, &unknownMatrixExecutionErr) {
+			h.ReplyError(404, "unknown_matrix_execution", "%v", err)
+		} else {
+			h.ReplyInternalError(500, "%v", err)
+		}
+
+		return nil, err
+	}
+
+	return &me, nil
+}
+
 func (s *HTTPServer) AbortJobExecution(h *HTTPHandler, jeId uuid.UUID) error {
 	scope := h.Context.ProjectScope()
 
@@ -57,17 +85,20 @@ func (s *HTTPServer) AbortJobExecution(h *HTTPHandler, jeId uuid.UUID) error {
 	return nil
 }
 
-func (s *HTTPServer) RestartJobExecution(h *HTTPHandler, jeId uuid.UUID) error {
+func (s *HTTPServer) RestartJobExecution(h *HTTPHandler, jeId uuid.UUID, input *eventline.JobExecutionInput) error {
 	scope := h.Context.ProjectScope()
 
-	if _, err := s.Service.RestartJobExecution(jeId, scope); err != nil {
+	if _, err := s.Service.RestartJobExecution(jeId, input, scope); err != nil {
 		var unknownJobExecutionErr *eventline.UnknownJobExecutionError
 		var jobExecutionNotFinishedErr *eventline.JobExecutionNotFinishedError
+		var validationErrors ejson.ValidationErrors
 
 		if errors.As(err, &unknownJobExecutionErr) {
 			h.ReplyError(404, "unknown_job_execution", "%v", err)
 		} else if errors.As(err, &jobExecutionNotFinishedErr) {
 			h.ReplyError(400, "job_execution_not_finished", "%v", err)
+		} else if errors.As(err, &validationErrors) {
+			h.ReplyValidationErrors(validationErrors)
 		} else {
 			h.ReplyInternalError(500, "cannot restart job execution: %v", err)
 		}
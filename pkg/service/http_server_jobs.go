@@ -177,13 +177,15 @@ func (s *HTTPServer) ExecuteJob(h *HTTPHandler, jobId uuid.UUID, input *eventlin
 	var jobExecution *eventline.JobExecution
 
 	err := s.Service.Pg.WithTx(func(conn pg.Conn) error {
-		var err error
-
-		jobExecution, err = s.Service.ExecuteJob(conn, jobId, input, scope)
+		jobExecutions, err := s.Service.ExecuteJob(conn, jobId, input, scope)
 		if err != nil {
 			return fmt.Errorf("cannot execute job: %w", err)
 		}
 
+		// For jobs with a matrix, we return the first execution; it
+		// references the other ones with its matrix id.
+		jobExecution = jobExecutions[0]
+
 		return nil
 	})
 	if err != nil {
@@ -203,3 +205,76 @@ func (s *HTTPServer) ExecuteJob(h *HTTPHandler, jobId uuid.UUID, input *eventlin
 
 	return jobExecution, nil
 }
+
+func (s *HTTPServer) LoadJobVersion(h *HTTPHandler, jobId uuid.UUID, version int) (*eventline.JobVersion, error) {
+	scope := h.Context.ProjectScope()
+
+	var jobVersion eventline.JobVersion
+
+	err := s.Pg.WithConn(func(conn pg.Conn) error {
+		if err := jobVersion.Load(conn, jobId, version, scope); err != nil {
+			return fmt.Errorf("cannot load job version: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		var unknownJobVersionErr *eventline.UnknownJobVersionError
+
+		if errors.As(err, &unknownJobVersionErr) {
+			h.ReplyError(404, "unknown_job_version", "%v", err)
+		} else {
+			h.ReplyInternalError(500, "%v", err)
+		}
+
+		return nil, err
+	}
+
+	return &jobVersion, nil
+}
+
+func (s *HTTPServer) RollbackJob(h *HTTPHandler, jobId uuid.UUID, version int) (*eventline.Job, error) {
+	scope := h.Context.ProjectScope()
+
+	var job *eventline.Job
+	var subscriptionCreatedOrUpdated bool
+
+	err := s.Service.Pg.WithTx(func(conn pg.Conn) error {
+		id1 := PgAdvisoryLockId1
+		id2 := PgAdvisoryLockId2JobDeployment
+
+		if err := pg.TakeAdvisoryTxLock(conn, id1, id2); err != nil {
+			return fmt.Errorf("cannot take advisory lock: %w", err)
+		}
+
+		var err error
+		job, subscriptionCreatedOrUpdated, err = s.Service.RollbackJob(conn,
+			jobId, version, h.Context.AccountId, scope)
+		return err
+	})
+	if err != nil {
+		var unknownJobErr *eventline.UnknownJobError
+		var unknownJobVersionErr *eventline.UnknownJobVersionError
+		var validationErrors ejson.ValidationErrors
+
+		if errors.As(err, &unknownJobErr) {
+			h.ReplyError(404, "unknown_job", "%v", err)
+		} else if errors.As(err, &unknownJobVersionErr) {
+			h.ReplyError(404, "unknown_job_version", "%v", err)
+		} else if errors.As(err, &validationErrors) {
+			h.ReplyValidationErrors(validationErrors)
+		} else {
+			h.ReplyInternalError(500, "cannot rollback job: %v", err)
+		}
+
+		return nil, err
+	}
+
+	if subscriptionCreatedOrUpdated {
+		if w := s.Service.FindWorker("subscription-worker"); w != nil {
+			w.WakeUp()
+		}
+	}
+
+	return job, nil
+}
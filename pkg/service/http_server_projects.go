@@ -100,6 +100,39 @@ func (s *HTTPServer) CreateProject(h *HTTPHandler, newProject *eventline.NewProj
 	return project, nil
 }
 
+func (s *HTTPServer) CloneProject(h *HTTPHandler, projectId uuid.UUID, data *eventline.ProjectCloningData) (*eventline.Project, error) {
+	var project *eventline.Project
+	var subscriptionsCreatedOrUpdated bool
+
+	err := s.Pg.WithTx(func(conn pg.Conn) (err error) {
+		project, subscriptionsCreatedOrUpdated, err =
+			s.Service.CloneProject(conn, projectId, data, h.Context.AccountId)
+		return
+	})
+	if err != nil {
+		var unknownProjectErr *eventline.UnknownProjectError
+		var duplicateProjectNameErr *DuplicateProjectNameError
+
+		if errors.As(err, &unknownProjectErr) {
+			h.ReplyError(404, "unknown_project", "%v", err)
+		} else if errors.As(err, &duplicateProjectNameErr) {
+			h.ReplyError(400, "duplicate_project_name", "%v", err)
+		} else {
+			h.ReplyInternalError(500, "cannot clone project: %v", err)
+		}
+
+		return nil, err
+	}
+
+	if subscriptionsCreatedOrUpdated {
+		if w := s.Service.FindWorker("subscription-worker"); w != nil {
+			w.WakeUp()
+		}
+	}
+
+	return project, nil
+}
+
 func (s *HTTPServer) UpdateProject(h *HTTPHandler, projectId uuid.UUID, newProject *eventline.NewProject) (*eventline.Project, error) {
 	var project eventline.Project
 
@@ -85,6 +85,7 @@ func (s *Service) CreateIdentity(newIdentity *eventline.NewIdentity, scope event
 
 func (s *Service) UpdateIdentity(identityId uuid.UUID, newIdentity *eventline.NewIdentity, scope eventline.Scope) (*eventline.Identity, error) {
 	var identity eventline.Identity
+	var becameReady bool
 
 	err := s.Pg.WithTx(func(conn pg.Conn) error {
 		if err := identity.LoadForUpdate(conn, identityId, scope); err != nil {
@@ -117,19 +118,48 @@ func (s *Service) UpdateIdentity(identityId uuid.UUID, newIdentity *eventline.Ne
 		identity.Type = newIdentity.Type
 		identity.Data = newIdentity.Data
 
+		// Pending identities which do not require an additional step, e.g.
+		// placeholders created when cloning a project, are ready once their
+		// data have been provided.
+		idef := eventline.GetConnectorDef(identity.Connector).
+			Identity(identity.Type)
+
+		if identity.Status == eventline.IdentityStatusPending &&
+			!idef.DeferredReadiness {
+			identity.Status = eventline.IdentityStatusReady
+			becameReady = true
+		}
+
 		if err := identity.Update(conn); err != nil {
 			return fmt.Errorf("cannot update identity: %w", err)
 		}
 
+		if becameReady {
+			err := eventline.ScheduleIdentitySubscriptions(conn, identity.Id)
+			if err != nil {
+				return fmt.Errorf("cannot schedule subscriptions: %w", err)
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	if becameReady {
+		s.wakeUpSubscriptionWorker()
+	}
+
 	return &identity, nil
 }
 
+func (s *Service) wakeUpSubscriptionWorker() {
+	if w := s.FindWorker("subscription-worker"); w != nil {
+		w.WakeUp()
+	}
+}
+
 func (s *Service) DeleteIdentity(identityId uuid.UUID, scope eventline.Scope) error {
 	return s.Pg.WithTx(func(conn pg.Conn) error {
 		var identity eventline.Identity
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/log"
+	"go.n16f.net/service/pkg/pg"
+)
+
+const (
+	// The delivery of a report is a single HTTP request to an external
+	// service. Reports are reserved for twice this duration while they are
+	// being delivered.
+	jobExecutionReportTimeout = 30 * time.Second
+
+	maxJobExecutionReportAttempts = 10
+)
+
+type JobExecutionReportWorker struct {
+	Log     *log.Logger
+	Service *Service
+}
+
+func NewJobExecutionReportWorker(s *Service) *JobExecutionReportWorker {
+	return &JobExecutionReportWorker{
+		Service: s,
+	}
+}
+
+func (rw *JobExecutionReportWorker) Init(w *eventline.Worker) {
+	rw.Log = w.Log
+}
+
+func (rw *JobExecutionReportWorker) Start() error {
+	return nil
+}
+
+func (rw *JobExecutionReportWorker) Stop() {
+}
+
+func (rw *JobExecutionReportWorker) ProcessJob() (bool, error) {
+	var report *eventline.QueuedJobExecutionReport
+
+	err := rw.Service.Pg.WithTx(func(conn pg.Conn) (err error) {
+		report, err = rw.reserveReport(conn)
+		return
+	})
+	if err != nil {
+		return false, err
+	} else if report == nil {
+		return false, nil
+	}
+
+	rw.Log.Info("processing report of job execution %q",
+		report.JobExecutionId)
+
+	deliveryErr := rw.Service.DeliverJobExecutionReport(report)
+
+	err = rw.Service.Pg.WithTx(func(conn pg.Conn) error {
+		return rw.storeDeliveryResult(conn, report, deliveryErr)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// reserveReport loads the next report to deliver and delays its next
+// delivery so that it is not delivered again while the external service is
+// being contacted.
+func (rw *JobExecutionReportWorker) reserveReport(conn pg.Conn) (*eventline.QueuedJobExecutionReport, error) {
+	report, err := eventline.LoadQueuedJobExecutionReportForDelivery(conn)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load job execution report: %w", err)
+	} else if report == nil {
+		return nil, nil
+	}
+
+	nextDeliveryTime := time.Now().UTC().Add(2 * jobExecutionReportTimeout)
+	report.NextDeliveryTime = nextDeliveryTime
+
+	if err := report.Update(conn); err != nil {
+		return nil, fmt.Errorf("cannot update job execution report %q: %w",
+			report.Id, err)
+	}
+
+	return report, nil
+}
+
+// storeDeliveryResult deletes a report once it has been delivered or after
+// too many attempts, or schedules a new attempt.
+func (rw *JobExecutionReportWorker) storeDeliveryResult(conn pg.Conn, report *eventline.QueuedJobExecutionReport, deliveryErr error) error {
+	report.NbAttempts++
+
+	if deliveryErr == nil {
+		if err := report.Delete(conn); err != nil {
+			return fmt.Errorf("cannot delete job execution report %q: %w",
+				report.Id, err)
+		}
+	} else if report.NbAttempts >= maxJobExecutionReportAttempts {
+		rw.Log.Error("cannot report job execution %q, giving up "+
+			"after %d attempts: %v", report.JobExecutionId,
+			report.NbAttempts, deliveryErr)
+
+		if err := report.Delete(conn); err != nil {
+			return fmt.Errorf("cannot delete job execution report %q: %w",
+				report.Id, err)
+		}
+	} else {
+		rw.Log.Error("cannot report job execution %q: %v",
+			report.JobExecutionId, deliveryErr)
+
+		now := time.Now().UTC()
+
+		var deliveryDelay int
+		switch {
+		case report.DeliveryDelay == 0:
+			deliveryDelay = 5
+		case report.DeliveryDelay < 40:
+			deliveryDelay = report.DeliveryDelay * 2
+		case report.DeliveryDelay >= 40:
+			deliveryDelay = 60
+		}
+
+		deliveryDelayDuration := time.Duration(deliveryDelay) * time.Second
+
+		report.DeliveryDelay = deliveryDelay
+		report.NextDeliveryTime = now.Add(deliveryDelayDuration)
+
+		if err := report.Update(conn); err != nil {
+			return fmt.Errorf("cannot update job execution report %q: %w",
+				report.Id, err)
+		}
+	}
+
+	return nil
+}
@@ -1,12 +1,14 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"path"
 	"time"
 
 	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
 	"go.n16f.net/service/pkg/pg"
 	"go.n16f.net/uuid"
 )
@@ -50,6 +52,16 @@ func (s *Service) StartJobExecution(conn pg.Conn, je *eventline.JobExecution, sc
 		return fmt.Errorf("cannot load execution context: %w", err)
 	}
 
+	// Evaluate templates in the job specification. The interpolated
+	// specification is only used by the runner, the job execution keeps the
+	// original one so that it can be restarted.
+	spec, err := je.JobSpec.Interpolate(ectx)
+	if err != nil {
+		return s.UpdateJobExecutionFailure(conn, je, "%v", err)
+	}
+
+	je.JobSpec = spec
+
 	// Load the project and its settings
 	projectId := scope.(*eventline.ProjectScope).ProjectId
 
@@ -63,6 +75,11 @@ func (s *Service) StartJobExecution(conn pg.Conn, je *eventline.JobExecution, sc
 		return fmt.Errorf("cannot load project settings: %w", err)
 	}
 
+	var projectVariables eventline.ProjectVariables
+	if err := projectVariables.LoadAll(conn, scope); err != nil {
+		return fmt.Errorf("cannot load project variables: %w", err)
+	}
+
 	// Create and start a runner
 	runnerData := eventline.RunnerData{
 		JobExecution:     je,
@@ -70,12 +87,17 @@ func (s *Service) StartJobExecution(conn pg.Conn, je *eventline.JobExecution, sc
 		ExecutionContext: ectx,
 		Project:          &project,
 		ProjectSettings:  &projectSettings,
+		ProjectVariables: projectVariables,
 	}
 
 	if _, err := s.StartRunner(&runnerData); err != nil {
 		return fmt.Errorf("cannot start runner: %w", err)
 	}
 
+	if err := s.QueueJobExecutionReport(conn, je); err != nil {
+		return fmt.Errorf("cannot queue job execution report: %w", err)
+	}
+
 	return nil
 }
 
@@ -136,7 +158,7 @@ func (s *Service) AbortJobExecution(jeId uuid.UUID, scope eventline.Scope) (*eve
 	return &je, nil
 }
 
-func (s *Service) RestartJobExecution(jeId uuid.UUID, scope eventline.Scope) (*eventline.JobExecution, error) {
+func (s *Service) RestartJobExecution(jeId uuid.UUID, input *eventline.JobExecutionInput, scope eventline.Scope) (*eventline.JobExecution, error) {
 	var je eventline.JobExecution
 
 	now := time.Now().UTC()
@@ -150,6 +172,18 @@ func (s *Service) RestartJobExecution(jeId uuid.UUID, scope eventline.Scope) (*e
 			return &eventline.JobExecutionNotFinishedError{Id: jeId}
 		}
 
+		if err := restartJobExecutionParameters(conn, &je, input); err != nil {
+			return err
+		}
+
+		// Identities referenced by identity parameters may have been
+		// deleted since the first execution.
+		err := checkIdentityParameters(conn, je.JobSpec.Parameters,
+			je.Parameters, scope)
+		if err != nil {
+			return err
+		}
+
 		je.Status = eventline.JobExecutionStatusCreated
 		je.StartTime = nil
 		je.EndTime = nil
@@ -162,7 +196,7 @@ func (s *Service) RestartJobExecution(jeId uuid.UUID, scope eventline.Scope) (*e
 		}
 
 		var ses eventline.StepExecutions
-		err := ses.LoadByJobExecutionIdForUpdate(conn, jeId)
+		err = ses.LoadByJobExecutionIdForUpdate(conn, jeId)
 		if err != nil {
 			return fmt.Errorf("cannot load step executions: %w", err)
 		}
@@ -194,6 +228,38 @@ func (s *Service) RestartJobExecution(jeId uuid.UUID, scope eventline.Scope) (*e
 	return &je, nil
 }
 
+// Secret parameter values are not reused when a job execution is restarted:
+// they must be provided again.
+func restartJobExecutionParameters(conn pg.Conn, je *eventline.JobExecution, input *eventline.JobExecutionInput) error {
+	params := je.JobSpec.Parameters
+
+	var inputValues map[string]interface{}
+	if input != nil {
+		inputValues = input.Parameters
+	}
+
+	if len(params.SecretNames()) == 0 && len(inputValues) == 0 {
+		return nil
+	}
+
+	if je.Parameters == nil {
+		je.Parameters = make(map[string]interface{})
+	}
+
+	v := ejson.NewValidator()
+	params.CheckRestartValues(v, "parameters", je.Parameters, inputValues)
+
+	if err := v.Error(); err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	if err := je.UpdateParameters(conn); err != nil {
+		return fmt.Errorf("cannot update job execution parameters: %w", err)
+	}
+
+	return nil
+}
+
 func (s *Service) UpdateJobExecutionFailure(conn pg.Conn, je *eventline.JobExecution, format string, args ...interface{}) error {
 	var ses eventline.StepExecutions
 
@@ -232,7 +298,7 @@ func (s *Service) UpdateJobExecutionFailure(conn pg.Conn, je *eventline.JobExecu
 func (s *Service) handleJobExecutionTermination(jeId uuid.UUID) error {
 	now := time.Now().UTC()
 
-	return s.Pg.WithTx(func(conn pg.Conn) error {
+	err := s.Pg.WithTx(func(conn pg.Conn) error {
 		var je eventline.JobExecution
 		if err := je.LoadForUpdateNoScope(conn, jeId); err != nil {
 			return fmt.Errorf("cannot load job execution: %w", err)
@@ -256,8 +322,126 @@ func (s *Service) handleJobExecutionTermination(jeId uuid.UUID) error {
 			return fmt.Errorf("cannot send notification: %w", err)
 		}
 
+		// Executions aborted before being started were never reported
+		if je.StartTime != nil {
+			if err := s.QueueJobExecutionReport(conn, &je); err != nil {
+				return fmt.Errorf("cannot queue job execution report: %w",
+					err)
+			}
+		}
+
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	s.wakeUpJobExecutionReportWorker()
+
+	return nil
+}
+
+// QueueJobExecutionReport queues the report of the status of a job
+// execution to the connector of the trigger of the job if reporting was
+// enabled. Only job executions created for an event are reported. Reports are
+// delivered by the job execution report worker so that external services are
+// never contacted in a transaction.
+func (s *Service) QueueJobExecutionReport(conn pg.Conn, je *eventline.JobExecution) error {
+	trigger := je.JobSpec.Trigger
+	if trigger == nil || !trigger.Report || je.EventId == nil {
+		return nil
+	}
+
+	report := eventline.QueuedJobExecutionReport{
+		Id:               uuid.MustGenerate(uuid.V7),
+		JobExecutionId:   je.Id,
+		NextDeliveryTime: time.Now().UTC(),
+	}
+
+	return report.Insert(conn)
+}
+
+// DeliverJobExecutionReport reports the current status of a job execution to
+// the connector of the trigger of the job. No database connection is held
+// while the external service is contacted.
+func (s *Service) DeliverJobExecutionReport(r *eventline.QueuedJobExecutionReport) error {
+	var connector eventline.ReportingConnector
+	var report *eventline.JobExecutionReport
+
+	err := s.Pg.WithConn(func(conn pg.Conn) (err error) {
+		connector, report, err = s.loadJobExecutionReport(conn, r)
+		return
+	})
+	if err != nil {
+		return err
+	} else if report == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		jobExecutionReportTimeout)
+	defer cancel()
+
+	return connector.ReportJobExecution(ctx, report)
+}
+
+func (s *Service) loadJobExecutionReport(conn pg.Conn, r *eventline.QueuedJobExecutionReport) (eventline.ReportingConnector, *eventline.JobExecutionReport, error) {
+	var je eventline.JobExecution
+	err := je.Load(conn, r.JobExecutionId, eventline.NewGlobalScope())
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot load job execution: %w", err)
+	}
+
+	trigger := je.JobSpec.Trigger
+	if trigger == nil || je.EventId == nil {
+		return nil, nil, nil
+	}
+
+	connector, found := eventline.FindConnector(trigger.Event.Connector)
+	if !found {
+		return nil, nil, fmt.Errorf("unknown connector %q",
+			trigger.Event.Connector)
+	}
+
+	reportingConnector, ok := connector.(eventline.ReportingConnector)
+	if !ok {
+		return nil, nil, fmt.Errorf("connector %q does not support reporting",
+			trigger.Event.Connector)
+	}
+
+	scope := eventline.NewProjectScope(je.ProjectId)
+
+	var event eventline.Event
+	if err := event.Load(conn, *je.EventId, scope); err != nil {
+		return nil, nil, fmt.Errorf("cannot load event: %w", err)
+	}
+
+	var identity eventline.Identity
+	if err := identity.LoadByName(conn, trigger.Identity, scope); err != nil {
+		return nil, nil, fmt.Errorf("cannot load identity: %w", err)
+	}
+
+	report := eventline.JobExecutionReport{
+		JobExecution:    &je,
+		Event:           &event,
+		Identity:        &identity,
+		JobExecutionURI: s.jobExecutionURI(&je),
+	}
+
+	return reportingConnector, &report, nil
+}
+
+func (s *Service) wakeUpJobExecutionReportWorker() {
+	if w := s.FindWorker("job-execution-report-worker"); w != nil {
+		w.WakeUp()
+	}
+}
+
+func (s *Service) jobExecutionURI(je *eventline.JobExecution) string {
+	jePath := path.Join("/job_executions", "id", je.Id.String())
+	jeURI := s.WebHTTPServerURI.ResolveReference(&url.URL{Path: jePath})
+
+	return jeURI.String()
 }
 
 func (s *Service) SendJobExecutionNotification(conn pg.Conn, je *eventline.JobExecution) error {
@@ -301,9 +485,6 @@ func (s *Service) SendJobExecutionNotification(conn pg.Conn, je *eventline.JobEx
 		return nil
 	}
 
-	jePath := path.Join("/job_executions", "id", je.Id.String())
-	jeURI := s.WebHTTPServerURI.ResolveReference(&url.URL{Path: jePath})
-
 	var subjectStatusPart string
 	switch je.Status {
 	case eventline.JobExecutionStatusAborted:
@@ -322,7 +503,7 @@ func (s *Service) SendJobExecutionNotification(conn pg.Conn, je *eventline.JobEx
 		JobExecutionURI string
 	}{
 		JobExecution:    je,
-		JobExecutionURI: jeURI.String(),
+		JobExecutionURI: s.jobExecutionURI(je),
 	}
 
 	scope := eventline.NewProjectScope(je.ProjectId)
@@ -77,5 +77,9 @@ func (js *JobScheduler) ProcessJob() (bool, error) {
 		return false, err
 	}
 
+	if processed {
+		js.Service.wakeUpJobExecutionReportWorker()
+	}
+
 	return processed, nil
 }
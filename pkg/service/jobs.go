@@ -16,7 +16,8 @@ import (
 type JobSpecValidator struct {
 	JobSpec *eventline.JobSpec
 
-	Identities map[string]*eventline.Identity
+	Identities    map[string]*eventline.Identity
+	StepTemplates map[string]*eventline.StepTemplate
 
 	Service   *Service
 	Validator *ejson.Validator
@@ -28,8 +29,12 @@ func (s *Service) ValidateJobSpec(conn pg.Conn, spec *eventline.JobSpec, scope e
 	validator := ejson.NewValidator()
 	spec.ValidateJSON(validator)
 
+	identityNames := spec.IdentityNames()
+	identityNames = append(identityNames,
+		spec.Parameters.DefaultIdentityNames()...)
+
 	var identities eventline.Identities
-	err := identities.LoadByNamesForUpdate(conn, spec.IdentityNames(), scope)
+	err := identities.LoadByNamesForUpdate(conn, identityNames, scope)
 	if err != nil {
 		return fmt.Errorf("cannot load identities: %w", err)
 	}
@@ -39,10 +44,16 @@ func (s *Service) ValidateJobSpec(conn pg.Conn, spec *eventline.JobSpec, scope e
 		identityTable[i.Name] = i
 	}
 
+	stepTemplateTable, err := s.loadStepTemplates(conn, spec, scope)
+	if err != nil {
+		return err
+	}
+
 	v := JobSpecValidator{
 		JobSpec: spec,
 
-		Identities: identityTable,
+		Identities:    identityTable,
+		StepTemplates: stepTemplateTable,
 
 		Service:   s,
 		Validator: validator,
@@ -65,6 +76,20 @@ func (v *JobSpecValidator) checkJobSpec() error {
 		}
 	}
 
+	v.Validator.WithChild("parameters", func() {
+		for idx, p := range v.JobSpec.Parameters {
+			if p.Type != eventline.ParameterTypeIdentity {
+				continue
+			}
+
+			if name, ok := p.Default.(string); ok && name != "" {
+				v.Validator.WithChild(idx, func() {
+					v.checkIdentityName("default", name)
+				})
+			}
+		}
+	})
+
 	if hasMandatoryParams && v.JobSpec.Trigger != nil {
 		v.Validator.AddError("trigger",
 			"invalid_trigger_with_mandatory_parameters",
@@ -94,9 +119,47 @@ func (v *JobSpecValidator) checkJobSpec() error {
 		}
 	})
 
+	// Templates
+	v.JobSpec.CheckInterpolationReferences(v.Validator)
+
+	// Steps
+	v.Validator.WithChild("steps", func() {
+		for idx, step := range v.JobSpec.Steps {
+			if step.Template != nil {
+				v.Validator.WithChild(idx, func() {
+					v.checkStepTemplateRef(step)
+				})
+			}
+		}
+	})
+
 	return nil
 }
 
+func (v *JobSpecValidator) checkStepTemplateRef(step *eventline.Step) {
+	name := step.Template.Name
+
+	template, found := v.StepTemplates[name]
+	if !found {
+		v.Validator.AddError("template", "unknown_step_template",
+			"unknown step template %q", name)
+		return
+	}
+
+	steps, err := template.Spec.Expand(step)
+	if err != nil {
+		v.Validator.AddError("template", "invalid_step_template_reference",
+			"cannot expand step template %q: %v", name, err)
+		return
+	}
+
+	v.Validator.WithChild("template", func() {
+		v.Validator.WithChild("steps", func() {
+			v.JobSpec.CheckStepsInterpolation(v.Validator, steps)
+		})
+	})
+}
+
 func (v *JobSpecValidator) checkTrigger(trigger *eventline.Trigger) {
 	cname := trigger.Event.Connector
 
@@ -136,13 +199,78 @@ func (v *JobSpecValidator) checkIdentityName(token interface{}, name string) {
 	}
 }
 
-func (s *Service) CreateOrUpdateJob(conn pg.Conn, spec *eventline.JobSpec, scope eventline.Scope) (*eventline.Job, bool, error) {
+func (s *Service) loadStepTemplates(conn pg.Conn, spec *eventline.JobSpec, scope eventline.Scope) (map[string]*eventline.StepTemplate, error) {
+	var templates eventline.StepTemplates
+	err := templates.LoadByNames(conn, spec.StepTemplateNames(), scope)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load step templates: %w", err)
+	}
+
+	table := make(map[string]*eventline.StepTemplate)
+	for _, t := range templates {
+		table[t.Spec.Name] = t
+	}
+
+	return table, nil
+}
+
+// ExpandJobSpec replaces references to step templates by the steps of these
+// templates. Jobs are stored expanded when they are deployed, so that later
+// modifications of templates do not affect existing jobs, their versions and
+// their executions.
+func (s *Service) ExpandJobSpec(conn pg.Conn, spec *eventline.JobSpec, scope eventline.Scope) (*eventline.JobSpec, error) {
+	templates, err := s.loadStepTemplates(conn, spec, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return spec.ExpandStepTemplates(templates)
+}
+
+// applyJobSpecDefaults sets the default values stored in job specifications
+// which are not provided by the user.
+func applyJobSpecDefaults(spec *eventline.JobSpec) {
 	if spec.Runner == nil {
 		spec.Runner = &eventline.JobRunner{
 			Name:       "local",
 			Parameters: &rlocal.RunnerParameters{},
 		}
 	}
+}
+
+// jobSpecsEqual compares a stored job specification with a new one, applying
+// default values to both and expanding step templates in the new one so that
+// specifications which would produce the same job are considered equal.
+func (s *Service) jobSpecsEqual(conn pg.Conn, spec1, spec2 *eventline.JobSpec, scope eventline.Scope) (bool, error) {
+	templates, err := s.loadStepTemplates(conn, spec2, scope)
+	if err != nil {
+		return false, err
+	}
+
+	// Specifications which cannot be expanded are invalid and are reported
+	// when they are validated.
+	expandedSpec2, err := spec2.ExpandStepTemplates(templates)
+	if err != nil {
+		return false, nil
+	}
+
+	s1 := *spec1
+	applyJobSpecDefaults(&s1)
+
+	s2 := *expandedSpec2
+	applyJobSpecDefaults(&s2)
+
+	return s1.Equal(&s2)
+}
+
+func (s *Service) CreateOrUpdateJob(conn pg.Conn, spec *eventline.JobSpec, origin *eventline.JobVersionOrigin, scope eventline.Scope) (*eventline.Job, bool, error) {
+	spec, err := s.ExpandJobSpec(conn, spec, scope)
+	if err != nil {
+		return nil, false,
+			fmt.Errorf("cannot expand job specification: %w", err)
+	}
+
+	applyJobSpecDefaults(spec)
 
 	runnerAllowed := len(s.Cfg.AllowedRunners) == 0 ||
 		utils.StringsContain(s.Cfg.AllowedRunners, spec.Runner.Name)
@@ -161,6 +289,7 @@ func (s *Service) CreateOrUpdateJob(conn pg.Conn, spec *eventline.JobSpec, scope
 		CreationTime: now,
 		UpdateTime:   now,
 		Spec:         spec,
+		GitCommit:    origin.GitCommit,
 	}
 
 	id, err := job.Upsert(conn)
@@ -170,6 +299,20 @@ func (s *Service) CreateOrUpdateJob(conn pg.Conn, spec *eventline.JobSpec, scope
 
 	job.Id = *id
 
+	// Versioning
+	if err := s.createJobVersion(conn, &job, origin, scope); err != nil {
+		return nil, false, fmt.Errorf("cannot create job version: %w", err)
+	}
+
+	// Pending debounced events would otherwise still be executed once their
+	// delay has elapsed.
+	if spec.Trigger == nil || spec.Trigger.Debounce == nil {
+		if err := eventline.DeleteJobDebouncedEvents(conn, job.Id); err != nil {
+			return nil, false,
+				fmt.Errorf("cannot delete debounced events: %w", err)
+		}
+	}
+
 	// Subscription handling
 	subscription := new(eventline.Subscription)
 	err = subscription.LoadByJobForUpdate(conn, job.Id, scope)
@@ -183,14 +326,16 @@ func (s *Service) CreateOrUpdateJob(conn pg.Conn, spec *eventline.JobSpec, scope
 		subscription = nil
 	}
 
-	// If there is a trigger, check if it has changed
+	// If there is a trigger, check if it has changed. Triggers receiving
+	// events shared by another project do not have any subscription.
+	trigger := spec.SubscriptionTrigger()
 	triggerChanged := false
 
-	if subscription == nil && spec.Trigger != nil {
+	if subscription == nil && trigger != nil {
 		triggerChanged = true
-	} else if subscription != nil && spec.Trigger != nil {
+	} else if subscription != nil && trigger != nil {
 		oldSubParams := subscription.Parameters
-		newSubParams := spec.Trigger.Parameters
+		newSubParams := trigger.Parameters
 
 		subParamsEqual :=
 			eventline.SubscriptionParametersEqual(oldSubParams,
@@ -208,14 +353,14 @@ func (s *Service) CreateOrUpdateJob(conn pg.Conn, spec *eventline.JobSpec, scope
 			oldIdentityName = oldIdentity.Name
 		}
 
-		newIdentityName := spec.Trigger.Identity
+		newIdentityName := trigger.Identity
 
 		triggerChanged = !subParamsEqual || oldIdentityName != newIdentityName
 	}
 
 	var subscriptionCreatedOrUpdated bool
 
-	if subscription != nil && (spec.Trigger == nil || triggerChanged) {
+	if subscription != nil && (trigger == nil || triggerChanged) {
 		err := s.TerminateSubscription(conn, subscription, false, scope)
 		if err != nil {
 			return nil, false,
@@ -223,7 +368,7 @@ func (s *Service) CreateOrUpdateJob(conn pg.Conn, spec *eventline.JobSpec, scope
 		}
 	}
 
-	if spec.Trigger != nil && triggerChanged {
+	if trigger != nil && triggerChanged {
 		if _, err := s.CreateSubscription(conn, &job, scope); err != nil {
 			return nil, false,
 				fmt.Errorf("cannot create subscription: %w", err)
@@ -235,8 +380,86 @@ func (s *Service) CreateOrUpdateJob(conn pg.Conn, spec *eventline.JobSpec, scope
 	return &job, subscriptionCreatedOrUpdated, nil
 }
 
+func (s *Service) createJobVersion(conn pg.Conn, job *eventline.Job, origin *eventline.JobVersionOrigin, scope eventline.Scope) error {
+	// Deploying a job without modifying it does not create a new version
+	var lastVersion eventline.JobVersion
+	err := lastVersion.LoadLast(conn, job.Id, scope)
+	if err == nil {
+		same, err := lastVersion.SameSpec(job.Spec)
+		if err != nil {
+			return err
+		} else if same {
+			return nil
+		}
+	} else {
+		var unknownJobVersionErr *eventline.UnknownJobVersionError
+		if !errors.As(err, &unknownJobVersionErr) {
+			return fmt.Errorf("cannot load last job version: %w", err)
+		}
+	}
+
+	version := eventline.JobVersion{
+		Id:           uuid.MustGenerate(uuid.V7),
+		ProjectId:    job.ProjectId,
+		JobId:        job.Id,
+		CreationTime: time.Now().UTC(),
+		Source:       origin.Source,
+		AccountId:    origin.AccountId,
+		Spec:         job.Spec,
+		GitCommit:    origin.GitCommit,
+	}
+
+	if err := version.Insert(conn); err != nil {
+		return fmt.Errorf("cannot insert job version: %w", err)
+	}
+
+	var projectSettings eventline.ProjectSettings
+	if err := projectSettings.Load(conn, job.ProjectId); err != nil {
+		return fmt.Errorf("cannot load project settings: %w", err)
+	}
+
+	if n := projectSettings.JobVersionRetention; n > 0 {
+		_, err := eventline.DeleteOldJobVersions(conn, job.Id, n, scope)
+		if err != nil {
+			return fmt.Errorf("cannot delete old job versions: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// RollbackJob deploys the specification of a previous version of a job. The
+// specification is validated again since resources it refers to may have
+// changed, and the rollback creates a new version.
+func (s *Service) RollbackJob(conn pg.Conn, jobId uuid.UUID, version int, accountId *uuid.UUID, scope eventline.Scope) (*eventline.Job, bool, error) {
+	var job eventline.Job
+	if err := job.LoadForUpdate(conn, jobId, scope); err != nil {
+		return nil, false, fmt.Errorf("cannot load job: %w", err)
+	}
+
+	var jobVersion eventline.JobVersion
+	if err := jobVersion.Load(conn, jobId, version, scope); err != nil {
+		return nil, false, fmt.Errorf("cannot load job version: %w", err)
+	}
+
+	// The job may have been renamed since the version was created
+	spec := jobVersion.Spec
+	spec.Name = job.Spec.Name
+
+	if err := s.ValidateJobSpec(conn, spec, scope); err != nil {
+		return nil, false, fmt.Errorf("invalid job specification: %w", err)
+	}
+
+	origin := eventline.JobVersionOrigin{
+		Source:    eventline.JobVersionSourceRollback,
+		AccountId: accountId,
+	}
+
+	return s.CreateOrUpdateJob(conn, spec, &origin, scope)
+}
+
 func (s *Service) DeleteJob(conn pg.Conn, job *eventline.Job, scope eventline.Scope) error {
-	if job.Spec.Trigger != nil {
+	if job.Spec.SubscriptionTrigger() != nil {
 		var subscription eventline.Subscription
 		err := subscription.LoadByJobForUpdate(conn, job.Id, scope)
 		if err != nil {
@@ -273,21 +496,81 @@ func (s *Service) RenameJob(conn pg.Conn, jobId uuid.UUID, data *eventline.JobRe
 	return &job, nil
 }
 
-func (s *Service) InstantiateJob(conn pg.Conn, job *eventline.Job, event *eventline.Event, params map[string]interface{}, scope eventline.Scope) (*eventline.JobExecution, error) {
+// InstantiateJob creates the executions of a job. Executions are scheduled
+// immediately unless a scheduled time is provided.
+func (s *Service) InstantiateJob(conn pg.Conn, job *eventline.Job, event *eventline.Event, params map[string]interface{}, scheduledTime *time.Time, scope eventline.Scope) (eventline.JobExecutions, error) {
+	spec := job.Spec
+
+	if spec.Matrix == nil {
+		je, err := s.instantiateJob(conn, job, spec, event, params,
+			scheduledTime, nil, nil, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		return eventline.JobExecutions{je}, nil
+	}
+
+	// If the job has a matrix, we create one execution per combination; each
+	// combination is merged into the parameters of the execution.
+	matrixId := uuid.MustGenerate(uuid.V7)
+
+	combinations := spec.Matrix.Combinations()
+	jes := make(eventline.JobExecutions, len(combinations))
+
+	for i, combination := range combinations {
+		jeParams := make(map[string]interface{})
+		for name, value := range params {
+			jeParams[name] = value
+		}
+		for name, value := range combination {
+			jeParams[name] = value
+		}
+
+		je, err := s.instantiateJob(conn, job, spec, event, jeParams,
+			scheduledTime, &matrixId, combination, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		jes[i] = je
+	}
+
+	return jes, nil
+}
+
+func (s *Service) instantiateJob(conn pg.Conn, job *eventline.Job, spec *eventline.JobSpec, event *eventline.Event, params map[string]interface{}, scheduledTime *time.Time, matrixId *uuid.UUID, matrixCombination map[string]interface{}, scope eventline.Scope) (*eventline.JobExecution, error) {
 	now := time.Now().UTC()
 
 	projectId := scope.(*eventline.ProjectScope).ProjectId
 
+	var jobVersionId *uuid.UUID
+
+	var jobVersion eventline.JobVersion
+	if err := jobVersion.LoadLast(conn, job.Id, scope); err == nil {
+		jobVersionId = &jobVersion.Id
+	} else {
+		var unknownJobVersionErr *eventline.UnknownJobVersionError
+		if !errors.As(err, &unknownJobVersionErr) {
+			return nil, fmt.Errorf("cannot load last job version: %w", err)
+		}
+	}
+
 	// Job
 	jobExecution := eventline.JobExecution{
 		Id:           uuid.MustGenerate(uuid.V7),
 		ProjectId:    projectId,
 		JobId:        job.Id,
-		JobSpec:      job.Spec,
+		JobSpec:      spec,
 		Parameters:   params,
 		CreationTime: now,
 		UpdateTime:   now,
 		Status:       eventline.JobExecutionStatusCreated,
+
+		MatrixId:          matrixId,
+		MatrixCombination: matrixCombination,
+
+		JobVersionId: jobVersionId,
 	}
 
 	if event == nil {
@@ -297,13 +580,17 @@ func (s *Service) InstantiateJob(conn pg.Conn, job *eventline.Job, event *eventl
 		jobExecution.ScheduledTime = event.EventTime
 	}
 
+	if scheduledTime != nil && scheduledTime.After(now) {
+		jobExecution.ScheduledTime = scheduledTime.UTC()
+	}
+
 	if err := jobExecution.Insert(conn); err != nil {
 		return nil, fmt.Errorf("cannot insert job execution: %w", err)
 	}
 
 	// Steps
-	stepExecutions := make(eventline.StepExecutions, len(job.Spec.Steps))
-	for i := range job.Spec.Steps {
+	stepExecutions := make(eventline.StepExecutions, len(spec.Steps))
+	for i := range spec.Steps {
 		stepExecution := eventline.StepExecution{
 			Id:             uuid.MustGenerate(uuid.V7),
 			ProjectId:      projectId,
@@ -324,6 +611,50 @@ func (s *Service) InstantiateJob(conn pg.Conn, job *eventline.Job, event *eventl
 	return &jobExecution, nil
 }
 
+func checkIdentityParameters(conn pg.Conn, params eventline.Parameters, values map[string]interface{}, scope eventline.Scope) error {
+	names := params.IdentityNames(values)
+	if len(names) == 0 {
+		return nil
+	}
+
+	var identities eventline.Identities
+	if err := identities.LoadByNames(conn, names, scope); err != nil {
+		return fmt.Errorf("cannot load identities: %w", err)
+	}
+
+	v := ejson.NewValidator()
+
+	v.WithChild("parameters", func() {
+		for _, p := range params {
+			if p.Type != eventline.ParameterTypeIdentity {
+				continue
+			}
+
+			name, ok := values[p.Name].(string)
+			if !ok {
+				continue
+			}
+
+			found := false
+			for _, identity := range identities {
+				if identity.Name == name {
+					found = true
+					break
+				}
+			}
+
+			v.Check(p.Name, found, "unknown_identity",
+				"unknown identity %q", name)
+		}
+	})
+
+	if err := v.Error(); err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	return nil
+}
+
 func (s *Service) EnableJob(conn pg.Conn, jobId uuid.UUID, scope eventline.Scope) (*eventline.Job, error) {
 	var job eventline.Job
 
@@ -370,7 +701,7 @@ func (s *Service) DisableJob(conn pg.Conn, jobId uuid.UUID, scope eventline.Scop
 	return &job, nil
 }
 
-func (s *Service) ExecuteJob(conn pg.Conn, jobId uuid.UUID, input *eventline.JobExecutionInput, scope eventline.Scope) (*eventline.JobExecution, error) {
+func (s *Service) ExecuteJob(conn pg.Conn, jobId uuid.UUID, input *eventline.JobExecutionInput, scope eventline.Scope) (eventline.JobExecutions, error) {
 	var job eventline.Job
 	if err := job.Load(conn, jobId, scope); err != nil {
 		return nil, fmt.Errorf("cannot load job: %w", err)
@@ -382,5 +713,12 @@ func (s *Service) ExecuteJob(conn pg.Conn, jobId uuid.UUID, input *eventline.Job
 		return nil, fmt.Errorf("invalid parameters: %w", err)
 	}
 
-	return s.InstantiateJob(conn, &job, nil, input.Parameters, scope)
+	err := checkIdentityParameters(conn, job.Spec.Parameters,
+		input.Parameters, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.InstantiateJob(conn, &job, nil, input.Parameters,
+		input.ScheduledTime, scope)
 }
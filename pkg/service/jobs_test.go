@@ -168,6 +168,22 @@ steps:
 		assertError(1, "/steps/2", "missing_step_content")
 	}
 
+	// Default values of identity parameters
+	data = `
+---
+name: "foo"
+parameters:
+  - name: "a"
+    type: "identity"
+    default: "` + identity.Name + `"
+  - name: "b"
+    type: "identity"
+    default: "does-not-exist"
+`
+	if assertInvalid(data, 1) {
+		assertError(0, "/parameters/1/default", "unknown_identity")
+	}
+
 	// Simple oneshot trigger
 	data = `
 ---
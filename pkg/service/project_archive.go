@@ -0,0 +1,540 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/exograd/eventline/pkg/cryptoutils"
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+type ProjectImportConflictError struct {
+	Objects []string
+}
+
+func (err ProjectImportConflictError) Error() string {
+	return fmt.Sprintf("objects already exist in the project: %s",
+		strings.Join(err.Objects, ", "))
+}
+
+// ExportProject returns an archive containing the content of a project.
+// Identity data and the values of secret project variables are only included
+// if a passphrase is provided.
+func (s *Service) ExportProject(conn pg.Conn, options *eventline.ProjectExportOptions, scope eventline.Scope) (*eventline.ProjectArchive, error) {
+	projectId := scope.(*eventline.ProjectScope).ProjectId
+
+	var project eventline.Project
+	if err := project.Load(conn, projectId); err != nil {
+		return nil, fmt.Errorf("cannot load project: %w", err)
+	}
+
+	archive := eventline.ProjectArchive{
+		Version:                     eventline.ProjectArchiveVersion,
+		CreationTime:                time.Now().UTC(),
+		ProjectName:                 project.Name,
+		ProjectSettings:             &eventline.ProjectSettings{},
+		ProjectNotificationSettings: &eventline.ProjectNotificationSettings{},
+		Jobs:                        eventline.JobSpecs{},
+		Identities:                  eventline.ProjectArchiveIdentities{},
+	}
+
+	if err := archive.ProjectSettings.Load(conn, projectId); err != nil {
+		return nil, fmt.Errorf("cannot load project settings: %w", err)
+	}
+
+	err := archive.ProjectNotificationSettings.Load(conn, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load project notification "+
+			"settings: %w", err)
+	}
+
+	var jobs eventline.Jobs
+	if err := jobs.LoadAllForUpdate(conn, scope); err != nil {
+		return nil, fmt.Errorf("cannot load jobs: %w", err)
+	}
+
+	for _, job := range jobs {
+		archive.Jobs = append(archive.Jobs, job.Spec)
+	}
+
+	var templates eventline.StepTemplates
+	if err := templates.LoadAll(conn, scope); err != nil {
+		return nil, fmt.Errorf("cannot load step templates: %w", err)
+	}
+
+	for _, template := range templates {
+		archive.StepTemplates = append(archive.StepTemplates, template.Spec)
+	}
+
+	var key *cryptoutils.AES256Key
+	if options.Passphrase != "" {
+		key2, err := archive.InitSecrets(options.Passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("cannot initialize secrets: %w", err)
+		}
+
+		key = &key2
+	}
+
+	var identities eventline.Identities
+	if err := identities.LoadAllForUpdate(conn, scope); err != nil {
+		return nil, fmt.Errorf("cannot load identities: %w", err)
+	}
+
+	for _, identity := range identities {
+		ai, err := eventline.NewProjectArchiveIdentity(identity, key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot export identity %q: %w",
+				identity.Name, err)
+		}
+
+		archive.Identities = append(archive.Identities, ai)
+	}
+
+	var variables eventline.ProjectVariables
+	if err := variables.LoadAll(conn, scope); err != nil {
+		return nil, fmt.Errorf("cannot load project variables: %w", err)
+	}
+
+	for _, variable := range variables {
+		av, err := eventline.NewProjectArchiveVariable(variable, key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot export project variable %q: %w",
+				variable.Name, err)
+		}
+
+		archive.Variables = append(archive.Variables, av)
+	}
+
+	return &archive, nil
+}
+
+// ImportProject imports the content of an archive in a project and returns
+// the list of changes applied. Project settings and project variables are
+// only imported if importSettings is true. Identities and secret project variables whose data
+// are not available, either because the archive does not contain them or
+// because no passphrase was provided, are never created.
+func (s *Service) ImportProject(conn pg.Conn, options *eventline.ProjectImportOptions, importSettings bool, accountId *uuid.UUID, scope eventline.Scope) (*eventline.ProjectSyncPlan, bool, error) {
+	archive := options.Archive
+	projectId := scope.(*eventline.ProjectScope).ProjectId
+
+	mode := options.OnConflict
+	if mode == "" {
+		mode = eventline.ProjectImportConflictModeFail
+	}
+
+	plan := eventline.ProjectSyncPlan{
+		Changes: eventline.ProjectSyncChanges{},
+	}
+
+	var key *cryptoutils.AES256Key
+	if archive.HasSecrets() && options.Passphrase != "" {
+		key2, err := archive.SecretKey(options.Passphrase)
+		if err != nil {
+			return nil, false, err
+		}
+
+		key = &key2
+	}
+
+	// Current state
+	var jobs eventline.Jobs
+	if err := jobs.LoadAllForUpdate(conn, scope); err != nil {
+		return nil, false, fmt.Errorf("cannot load jobs: %w", err)
+	}
+
+	jobTable := make(map[string]*eventline.Job)
+	for _, job := range jobs {
+		jobTable[job.Spec.Name] = job
+	}
+
+	var identities eventline.Identities
+	if err := identities.LoadAllForUpdate(conn, scope); err != nil {
+		return nil, false, fmt.Errorf("cannot load identities: %w", err)
+	}
+
+	identityTable := make(map[string]*eventline.Identity)
+	for _, identity := range identities {
+		identityTable[identity.Name] = identity
+	}
+
+	var templates eventline.StepTemplates
+	if err := templates.LoadAll(conn, scope); err != nil {
+		return nil, false, fmt.Errorf("cannot load step templates: %w", err)
+	}
+
+	templateTable := make(map[string]*eventline.StepTemplate)
+	for _, template := range templates {
+		templateTable[template.Spec.Name] = template
+	}
+
+	var variables eventline.ProjectVariables
+	if err := variables.LoadAll(conn, scope); err != nil {
+		return nil, false, fmt.Errorf("cannot load project variables: %w",
+			err)
+	}
+
+	variableTable := make(map[string]*eventline.ProjectVariable)
+	for _, variable := range variables {
+		variableTable[variable.Name] = variable
+	}
+
+	// Conflicts
+	if mode == eventline.ProjectImportConflictModeFail {
+		var conflicts []string
+
+		for _, spec := range archive.StepTemplates {
+			if _, found := templateTable[spec.Name]; found {
+				conflicts = append(conflicts,
+					fmt.Sprintf("step template %q", spec.Name))
+			}
+		}
+
+		for _, av := range archive.Variables {
+			if _, found := variableTable[av.Name]; found && importSettings {
+				conflicts = append(conflicts,
+					fmt.Sprintf("project variable %q", av.Name))
+			}
+		}
+
+		for _, ai := range archive.Identities {
+			if _, found := identityTable[ai.Name]; found {
+				conflicts = append(conflicts,
+					fmt.Sprintf("identity %q", ai.Name))
+			}
+		}
+
+		for _, spec := range archive.Jobs {
+			if _, found := jobTable[spec.Name]; found {
+				conflicts = append(conflicts, fmt.Sprintf("job %q", spec.Name))
+			}
+		}
+
+		if len(conflicts) > 0 {
+			return nil, false, &ProjectImportConflictError{Objects: conflicts}
+		}
+	}
+
+	var subscriptionsCreatedOrUpdated bool
+
+	// Identities
+	var validationErrors ejson.ValidationErrors
+
+	for i, ai := range archive.Identities {
+		pointer := ejson.NewPointer("archive", "identities", i)
+
+		data, err := ai.Data(key)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid identity %q: %w",
+				ai.Name, err)
+		}
+
+		identity, found := identityTable[ai.Name]
+		if found && (identity.Connector != ai.Connector ||
+			identity.Type != ai.Type) {
+			validationErrors = append(validationErrors,
+				&ejson.ValidationError{
+					Pointer: pointer,
+					Code:    "identity_type_mismatch",
+					Message: fmt.Sprintf("identity %q is a %s/%s identity",
+						ai.Name, identity.Connector, identity.Type),
+				})
+			continue
+		}
+
+		skip := found && mode == eventline.ProjectImportConflictModeSkip
+		if data == nil || skip {
+			plan.AddChange(eventline.ProjectSyncOperationSkip,
+				eventline.ProjectSyncObjectTypeIdentity, ai.Name)
+			continue
+		}
+
+		now := time.Now().UTC()
+
+		if !found {
+			identity = &eventline.Identity{
+				Id:           uuid.MustGenerate(uuid.V7),
+				ProjectId:    &projectId,
+				Name:         ai.Name,
+				CreationTime: now,
+				Connector:    ai.Connector,
+				Type:         ai.Type,
+			}
+		}
+
+		// Identities which do not require an additional step are ready once
+		// their data are available; others are only ready if the initial
+		// authorization was completed in the exported project.
+		idef := eventline.GetConnectorDef(ai.Connector).Identity(ai.Type)
+
+		identity.Status = eventline.IdentityStatusReady
+		if idef.DeferredReadiness &&
+			ai.Status != eventline.IdentityStatusReady {
+			identity.Status = eventline.IdentityStatusPending
+		}
+
+		identity.ErrorMessage = ""
+		identity.UpdateTime = now
+		identity.Data = data
+		identity.RefreshTime = nil
+
+		if identity.Refreshable() &&
+			identity.Status == eventline.IdentityStatusReady {
+			identityData := data.(eventline.RefreshableOAuth2IdentityData)
+			refreshTime := identityData.RefreshTime()
+			identity.RefreshTime = &refreshTime
+		}
+
+		if found {
+			if err := identity.Update(conn); err != nil {
+				return nil, false, fmt.Errorf("cannot update identity %q: %w",
+					identity.Name, err)
+			}
+
+			// Subscriptions are postponed while identities are pending
+			if identity.Status == eventline.IdentityStatusReady {
+				err := eventline.ScheduleIdentitySubscriptions(conn,
+					identity.Id)
+				if err != nil {
+					return nil, false, fmt.Errorf("cannot schedule "+
+						"subscriptions: %w", err)
+				}
+
+				subscriptionsCreatedOrUpdated = true
+			}
+
+			plan.AddChange(eventline.ProjectSyncOperationUpdate,
+				eventline.ProjectSyncObjectTypeIdentity, ai.Name)
+		} else {
+			if err := identity.Insert(conn); err != nil {
+				return nil, false, fmt.Errorf("cannot insert identity %q: %w",
+					identity.Name, err)
+			}
+
+			plan.AddChange(eventline.ProjectSyncOperationCreate,
+				eventline.ProjectSyncObjectTypeIdentity, ai.Name)
+		}
+	}
+
+	// Project variables
+	for _, av := range archive.Variables {
+		value, available, err := av.ProjectVariableValue(key)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid project variable %q: %w",
+				av.Name, err)
+		}
+
+		variable, found := variableTable[av.Name]
+
+		skip := !importSettings ||
+			(found && mode == eventline.ProjectImportConflictModeSkip)
+		if !available || skip {
+			plan.AddChange(eventline.ProjectSyncOperationSkip,
+				eventline.ProjectSyncObjectTypeProjectVariable, av.Name)
+			continue
+		}
+
+		if found && variable.Secret == av.Secret && variable.Value == value {
+			continue
+		}
+
+		nv := eventline.NewProjectVariable{
+			Name:   av.Name,
+			Value:  value,
+			Secret: av.Secret,
+		}
+
+		if _, err := s.SetProjectVariable(conn, &nv, scope); err != nil {
+			return nil, false, err
+		}
+
+		op := eventline.ProjectSyncOperationCreate
+		if found {
+			op = eventline.ProjectSyncOperationUpdate
+		}
+
+		plan.AddChange(op, eventline.ProjectSyncObjectTypeProjectVariable,
+			av.Name)
+	}
+
+	// Step templates must be imported before jobs since jobs can refer to
+	// them.
+	for i, spec := range archive.StepTemplates {
+		pointer := ejson.NewPointer("archive", "step_templates", i)
+
+		template, found := templateTable[spec.Name]
+		if found {
+			if mode == eventline.ProjectImportConflictModeSkip {
+				plan.AddChange(eventline.ProjectSyncOperationSkip,
+					eventline.ProjectSyncObjectTypeStepTemplate, spec.Name)
+				continue
+			}
+
+			equal, err := template.Spec.Equal(spec)
+			if err != nil {
+				return nil, false, err
+			} else if equal {
+				continue
+			}
+		}
+
+		if _, err := s.CreateOrUpdateStepTemplate(conn, spec,
+			scope); err != nil {
+			var verrs ejson.ValidationErrors
+			if !errors.As(err, &verrs) {
+				return nil, false, fmt.Errorf("cannot create or update step "+
+					"template %q: %w", spec.Name, err)
+			}
+
+			for _, verr := range verrs {
+				verr.Pointer.Prepend(pointer...)
+			}
+
+			validationErrors = append(validationErrors, verrs...)
+			continue
+		}
+
+		op := eventline.ProjectSyncOperationCreate
+		if found {
+			op = eventline.ProjectSyncOperationUpdate
+		}
+
+		plan.AddChange(op, eventline.ProjectSyncObjectTypeStepTemplate,
+			spec.Name)
+	}
+
+	// Jobs are validated once identities and step templates have been
+	// imported since they can refer to them.
+	var specsToDeploy eventline.JobSpecs
+
+	for i, spec := range archive.Jobs {
+		pointer := ejson.NewPointer("archive", "jobs", i)
+
+		job, found := jobTable[spec.Name]
+		if found {
+			if mode == eventline.ProjectImportConflictModeSkip {
+				plan.AddChange(eventline.ProjectSyncOperationSkip,
+					eventline.ProjectSyncObjectTypeJob, spec.Name)
+				continue
+			}
+
+			equal, err := s.jobSpecsEqual(conn, job.Spec, spec, scope)
+			if err != nil {
+				return nil, false, err
+			} else if equal {
+				continue
+			}
+		}
+
+		if err := s.ValidateJobSpec(conn, spec, scope); err != nil {
+			var verrs ejson.ValidationErrors
+			if !errors.As(err, &verrs) {
+				return nil, false, fmt.Errorf("invalid job specification "+
+					"%d: %w", i+1, err)
+			}
+
+			for _, verr := range verrs {
+				verr.Pointer.Prepend(pointer...)
+			}
+
+			validationErrors = append(validationErrors, verrs...)
+			continue
+		}
+
+		op := eventline.ProjectSyncOperationCreate
+		if found {
+			op = eventline.ProjectSyncOperationUpdate
+		}
+
+		plan.AddChange(op, eventline.ProjectSyncObjectTypeJob, spec.Name)
+		specsToDeploy = append(specsToDeploy, spec)
+	}
+
+	if validationErrors != nil {
+		return nil, false, fmt.Errorf("invalid archive: %w", validationErrors)
+	}
+
+	origin := eventline.JobVersionOrigin{
+		Source:    eventline.JobVersionSourceDeployment,
+		AccountId: accountId,
+	}
+
+	for _, spec := range specsToDeploy {
+		_, subscriptionCreatedOrUpdated, err := s.CreateOrUpdateJob(conn,
+			spec, &origin, scope)
+		if err != nil {
+			return nil, false, fmt.Errorf("cannot create or update job %q: %w",
+				spec.Name, err)
+		}
+
+		if subscriptionCreatedOrUpdated {
+			subscriptionsCreatedOrUpdated = true
+		}
+	}
+
+	// Settings
+	if err := s.importProjectSettings(conn, archive, &plan, importSettings,
+		projectId); err != nil {
+		return nil, false, err
+	}
+
+	return &plan, subscriptionsCreatedOrUpdated, nil
+}
+
+func (s *Service) importProjectSettings(conn pg.Conn, archive *eventline.ProjectArchive, plan *eventline.ProjectSyncPlan, importSettings bool, projectId uuid.UUID) error {
+	if !importSettings {
+		plan.AddChange(eventline.ProjectSyncOperationSkip,
+			eventline.ProjectSyncObjectTypeProjectSettings, "")
+		plan.AddChange(eventline.ProjectSyncOperationSkip,
+			eventline.ProjectSyncObjectTypeProjectNotificationSettings, "")
+		return nil
+	}
+
+	var settings eventline.ProjectSettings
+	if err := settings.Load(conn, projectId); err != nil {
+		return fmt.Errorf("cannot load project settings: %w", err)
+	}
+
+	newSettings := *archive.ProjectSettings
+	newSettings.Id = projectId
+
+	if newSettings != settings {
+		if err := newSettings.Update(conn); err != nil {
+			return fmt.Errorf("cannot update project settings: %w", err)
+		}
+
+		plan.AddChange(eventline.ProjectSyncOperationUpdate,
+			eventline.ProjectSyncObjectTypeProjectSettings, "")
+	}
+
+	if archive.ProjectNotificationSettings == nil {
+		return nil
+	}
+
+	var notificationSettings eventline.ProjectNotificationSettings
+	if err := notificationSettings.Load(conn, projectId); err != nil {
+		return fmt.Errorf("cannot load project notification settings: %w",
+			err)
+	}
+
+	newNotificationSettings := *archive.ProjectNotificationSettings
+	newNotificationSettings.Id = projectId
+
+	if !reflect.DeepEqual(newNotificationSettings, notificationSettings) {
+		if err := newNotificationSettings.Update(conn); err != nil {
+			return fmt.Errorf("cannot update project notification "+
+				"settings: %w", err)
+		}
+
+		plan.AddChange(eventline.ProjectSyncOperationUpdate,
+			eventline.ProjectSyncObjectTypeProjectNotificationSettings, "")
+	}
+
+	return nil
+}
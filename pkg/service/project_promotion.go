@@ -0,0 +1,318 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+// CloneProject creates a new project containing the jobs, step templates,
+// variables and settings of an existing project. Identities are created as
+// placeholders: they have the same name and type as in the source project but
+// no data, and stay pending until they are updated. Subscriptions using them
+// are only processed once they are ready.
+func (s *Service) CloneProject(conn pg.Conn, sourceProjectId uuid.UUID, data *eventline.ProjectCloningData, accountId *uuid.UUID) (*eventline.Project, bool, error) {
+	var sourceProject eventline.Project
+	if err := sourceProject.Load(conn, sourceProjectId); err != nil {
+		return nil, false, fmt.Errorf("cannot load project: %w", err)
+	}
+
+	sourceScope := eventline.NewProjectScope(sourceProjectId)
+
+	newProject := eventline.NewProject{Name: data.Name}
+
+	project, err := s.createProject(conn, &newProject, accountId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	scope := eventline.NewProjectScope(project.Id)
+
+	// Settings
+	var settings eventline.ProjectSettings
+	if err := settings.Load(conn, sourceProjectId); err != nil {
+		return nil, false, fmt.Errorf("cannot load project settings: %w", err)
+	}
+
+	settings.Id = project.Id
+
+	if err := settings.Update(conn); err != nil {
+		return nil, false, fmt.Errorf("cannot update project settings: %w", err)
+	}
+
+	var notificationSettings eventline.ProjectNotificationSettings
+	if err := notificationSettings.Load(conn, sourceProjectId); err != nil {
+		return nil, false, fmt.Errorf("cannot load project notification "+
+			"settings: %w", err)
+	}
+
+	notificationSettings.Id = project.Id
+
+	if err := notificationSettings.Update(conn); err != nil {
+		return nil, false, fmt.Errorf("cannot update project notification "+
+			"settings: %w", err)
+	}
+
+	// Variables
+	var variables eventline.ProjectVariables
+	if err := variables.LoadAll(conn, sourceScope); err != nil {
+		return nil, false, fmt.Errorf("cannot load project variables: %w", err)
+	}
+
+	for _, variable := range variables {
+		nv := eventline.NewProjectVariable{
+			Name:   variable.Name,
+			Value:  variable.Value,
+			Secret: variable.Secret,
+		}
+
+		if _, err := s.SetProjectVariable(conn, &nv, scope); err != nil {
+			return nil, false, fmt.Errorf("cannot create project variable "+
+				"%q: %w", variable.Name, err)
+		}
+	}
+
+	// Identities
+	var identities eventline.Identities
+	if err := identities.LoadAllForUpdate(conn, sourceScope); err != nil {
+		return nil, false, fmt.Errorf("cannot load identities: %w", err)
+	}
+
+	for _, sourceIdentity := range identities {
+		idef := eventline.GetConnectorDef(sourceIdentity.Connector).
+			Identity(sourceIdentity.Type)
+
+		identityData, err := idef.DecodeData([]byte("{}"))
+		if err != nil {
+			return nil, false, fmt.Errorf("cannot create data for identity "+
+				"%q: %w", sourceIdentity.Name, err)
+		}
+
+		now := time.Now().UTC()
+
+		identity := eventline.Identity{
+			Id:           uuid.MustGenerate(uuid.V7),
+			ProjectId:    &project.Id,
+			Name:         sourceIdentity.Name,
+			Status:       eventline.IdentityStatusPending,
+			CreationTime: now,
+			UpdateTime:   now,
+			Connector:    sourceIdentity.Connector,
+			Type:         sourceIdentity.Type,
+			Data:         identityData,
+		}
+
+		if err := identity.Insert(conn); err != nil {
+			return nil, false, fmt.Errorf("cannot insert identity %q: %w",
+				identity.Name, err)
+		}
+	}
+
+	// Step templates must be created before jobs referencing them.
+	var templates eventline.StepTemplates
+	if err := templates.LoadAll(conn, sourceScope); err != nil {
+		return nil, false, fmt.Errorf("cannot load step templates: %w", err)
+	}
+
+	for _, template := range templates {
+		_, err := s.CreateOrUpdateStepTemplate(conn, template.Spec, scope)
+		if err != nil {
+			return nil, false, fmt.Errorf("cannot create step template "+
+				"%q: %w", template.Spec.Name, err)
+		}
+	}
+
+	// Jobs are not validated again: they were valid in the source project,
+	// and identities they use are pending until they are configured. The
+	// subscriptions of triggers using these identities are postponed until
+	// then (see processInactiveSubscription).
+	var jobs eventline.Jobs
+	if err := jobs.LoadAllForUpdate(conn, sourceScope); err != nil {
+		return nil, false, fmt.Errorf("cannot load jobs: %w", err)
+	}
+
+	origin := eventline.JobVersionOrigin{
+		Source:    eventline.JobVersionSourceDeployment,
+		AccountId: accountId,
+	}
+
+	var subscriptionsCreatedOrUpdated bool
+
+	for _, job := range jobs {
+		_, subscriptionCreatedOrUpdated, err := s.CreateOrUpdateJob(conn,
+			job.Spec, &origin, scope)
+		if err != nil {
+			return nil, false, fmt.Errorf("cannot create job %q: %w",
+				job.Spec.Name, err)
+		}
+
+		if subscriptionCreatedOrUpdated {
+			subscriptionsCreatedOrUpdated = true
+		}
+	}
+
+	return project, subscriptionsCreatedOrUpdated, nil
+}
+
+// PromoteJobs copies jobs from a project to the current project, applying
+// the overrides of the promotion mapping. If no job name is provided, all
+// jobs of the source project are promoted.
+func (s *Service) PromoteJobs(conn pg.Conn, data *eventline.JobPromotionData, accountId *uuid.UUID, scope eventline.Scope) (*eventline.ProjectSyncPlan, bool, error) {
+	projectId := scope.(*eventline.ProjectScope).ProjectId
+
+	if data.SourceProjectId == projectId {
+		err := ejson.ValidationErrors{
+			&ejson.ValidationError{
+				Pointer: ejson.NewPointer("source_project_id"),
+				Code:    "invalid_source_project",
+				Message: "jobs cannot be promoted to their own project",
+			},
+		}
+
+		return nil, false, err
+	}
+
+	var sourceProject eventline.Project
+	if err := sourceProject.Load(conn, data.SourceProjectId); err != nil {
+		return nil, false, fmt.Errorf("cannot load project: %w", err)
+	}
+
+	sourceScope := eventline.NewProjectScope(data.SourceProjectId)
+
+	var sourceJobs eventline.Jobs
+	if err := sourceJobs.LoadAllForUpdate(conn, sourceScope); err != nil {
+		return nil, false, fmt.Errorf("cannot load jobs: %w", err)
+	}
+
+	sourceJobTable := make(map[string]*eventline.Job)
+	for _, job := range sourceJobs {
+		sourceJobTable[job.Spec.Name] = job
+	}
+
+	var validationErrors ejson.ValidationErrors
+
+	var specs eventline.JobSpecs
+
+	if len(data.JobNames) == 0 {
+		for _, job := range sourceJobs {
+			specs = append(specs, job.Spec)
+		}
+	} else {
+		for i, name := range data.JobNames {
+			job, found := sourceJobTable[name]
+			if !found {
+				validationErrors = append(validationErrors,
+					&ejson.ValidationError{
+						Pointer: ejson.NewPointer("jobs", i),
+						Code:    "unknown_job",
+						Message: fmt.Sprintf("unknown job %q in project %q",
+							name, sourceProject.Name),
+					})
+				continue
+			}
+
+			specs = append(specs, job.Spec)
+		}
+	}
+
+	if validationErrors != nil {
+		return nil, false, validationErrors
+	}
+
+	// Current state
+	var jobs eventline.Jobs
+	if err := jobs.LoadAllForUpdate(conn, scope); err != nil {
+		return nil, false, fmt.Errorf("cannot load jobs: %w", err)
+	}
+
+	jobTable := make(map[string]*eventline.Job)
+	for _, job := range jobs {
+		jobTable[job.Spec.Name] = job
+	}
+
+	plan := eventline.ProjectSyncPlan{
+		Changes: eventline.ProjectSyncChanges{},
+	}
+
+	var specsToDeploy eventline.JobSpecs
+
+	for _, spec := range specs {
+		pointer := ejson.NewPointer("jobs", spec.Name)
+
+		if data.Mapping != nil {
+			if err := data.Mapping.Apply(spec); err != nil {
+				validationErrors = append(validationErrors,
+					&ejson.ValidationError{
+						Pointer: pointer,
+						Code:    "invalid_overrides",
+						Message: err.Error(),
+					})
+				continue
+			}
+		}
+
+		job, found := jobTable[spec.Name]
+		if found {
+			equal, err := s.jobSpecsEqual(conn, job.Spec, spec, scope)
+			if err != nil {
+				return nil, false, err
+			} else if equal {
+				continue
+			}
+		}
+
+		if err := s.ValidateJobSpec(conn, spec, scope); err != nil {
+			var verrs ejson.ValidationErrors
+			if !errors.As(err, &verrs) {
+				return nil, false, fmt.Errorf("invalid job %q: %w",
+					spec.Name, err)
+			}
+
+			for _, verr := range verrs {
+				verr.Pointer.Prepend(pointer...)
+			}
+
+			validationErrors = append(validationErrors, verrs...)
+			continue
+		}
+
+		op := eventline.ProjectSyncOperationCreate
+		if found {
+			op = eventline.ProjectSyncOperationUpdate
+		}
+
+		plan.AddChange(op, eventline.ProjectSyncObjectTypeJob, spec.Name)
+		specsToDeploy = append(specsToDeploy, spec)
+	}
+
+	if validationErrors != nil {
+		return nil, false, fmt.Errorf("invalid jobs: %w", validationErrors)
+	}
+
+	origin := eventline.JobVersionOrigin{
+		Source:    eventline.JobVersionSourceDeployment,
+		AccountId: accountId,
+	}
+
+	var subscriptionsCreatedOrUpdated bool
+
+	for _, spec := range specsToDeploy {
+		_, subscriptionCreatedOrUpdated, err := s.CreateOrUpdateJob(conn,
+			spec, &origin, scope)
+		if err != nil {
+			return nil, false, fmt.Errorf("cannot create or update job %q: %w",
+				spec.Name, err)
+		}
+
+		if subscriptionCreatedOrUpdated {
+			subscriptionsCreatedOrUpdated = true
+		}
+	}
+
+	return &plan, subscriptionsCreatedOrUpdated, nil
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/exograd/eventline/pkg/utils"
+	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+type projectSyncState struct {
+	Spec *eventline.ProjectSyncSpec
+	Plan *eventline.ProjectSyncPlan
+
+	Jobs       map[string]*eventline.Job
+	Identities map[string]*eventline.Identity
+
+	JobsToDelete       eventline.Jobs
+	JobSpecsToDeploy   eventline.JobSpecs
+	IdentitiesToDelete eventline.Identities
+	ProjectSettings    *eventline.ProjectSettings
+}
+
+// SyncProject computes the list of changes required for the project to match
+// the specification, and applies them unless dryRun is true. It must be
+// called in a transaction holding the job deployment advisory lock so that
+// the plan cannot change before being applied.
+func (s *Service) SyncProject(conn pg.Conn, spec *eventline.ProjectSyncSpec, dryRun bool, accountId *uuid.UUID, scope eventline.Scope) (*eventline.ProjectSyncPlan, bool, error) {
+	state := projectSyncState{
+		Spec: spec,
+		Plan: &eventline.ProjectSyncPlan{
+			Changes: eventline.ProjectSyncChanges{},
+		},
+	}
+
+	if err := s.loadProjectSyncState(conn, &state, scope); err != nil {
+		return nil, false, err
+	}
+
+	if err := s.validateProjectSyncSpec(conn, &state, scope); err != nil {
+		return nil, false, err
+	}
+
+	if err := s.planProjectSync(conn, &state, scope); err != nil {
+		return nil, false, err
+	}
+
+	if dryRun {
+		return state.Plan, false, nil
+	}
+
+	subscriptionsCreatedOrUpdated, err := s.applyProjectSync(conn, &state,
+		accountId, scope)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return state.Plan, subscriptionsCreatedOrUpdated, nil
+}
+
+func (s *Service) loadProjectSyncState(conn pg.Conn, state *projectSyncState, scope eventline.Scope) error {
+	var jobs eventline.Jobs
+	if err := jobs.LoadAllForUpdate(conn, scope); err != nil {
+		return fmt.Errorf("cannot load jobs: %w", err)
+	}
+
+	state.Jobs = make(map[string]*eventline.Job)
+	for _, job := range jobs {
+		state.Jobs[job.Spec.Name] = job
+	}
+
+	if state.Spec.Identities != nil {
+		var identities eventline.Identities
+		if err := identities.LoadAllForUpdate(conn, scope); err != nil {
+			return fmt.Errorf("cannot load identities: %w", err)
+		}
+
+		state.Identities = make(map[string]*eventline.Identity)
+		for _, identity := range identities {
+			state.Identities[identity.Name] = identity
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) validateProjectSyncSpec(conn pg.Conn, state *projectSyncState, scope eventline.Scope) error {
+	spec := state.Spec
+
+	var validationErrors ejson.ValidationErrors
+
+	addError := func(pointer ejson.Pointer, code, format string, args ...interface{}) {
+		validationErrors = append(validationErrors, &ejson.ValidationError{
+			Pointer: pointer,
+			Code:    code,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	// Identities cannot be created from their metadata, they must already
+	// exist.
+	for i, is := range spec.Identities {
+		pointer := ejson.NewPointer("identities", i)
+
+		identity, found := state.Identities[is.Name]
+		if !found {
+			addError(pointer.Child("name"), "unknown_identity",
+				"unknown identity %q", is.Name)
+			continue
+		}
+
+		if identity.Connector != is.Connector || identity.Type != is.Type {
+			addError(pointer, "identity_type_mismatch",
+				"identity %q is a %s/%s identity", is.Name,
+				identity.Connector, identity.Type)
+		}
+	}
+
+	identityNames := spec.IdentityNames()
+
+	// Subscriptions are terminated asynchronously and the identity they use
+	// is required to unsubscribe, so an identity used by the trigger of a job
+	// deleted in the same synchronization can only be deleted by a later
+	// synchronization, once the subscription has been terminated.
+	if identityNames != nil {
+		for _, name := range utils.SortedKeys(state.Identities) {
+			if _, found := identityNames[name]; found {
+				continue
+			}
+
+			identity := state.Identities[name]
+
+			used, err := identity.IsUsedBySubscription(conn)
+			if err != nil {
+				return fmt.Errorf("cannot check identity usage: %w", err)
+			} else if used {
+				addError(ejson.NewPointer("identities"), "identity_in_use",
+					"identity %q is still used by a subscription; it can "+
+						"be deleted once the subscription has been "+
+						"terminated", name)
+			}
+		}
+	}
+
+	for i, jobSpec := range spec.Jobs {
+		pointer := ejson.NewPointer("jobs", i)
+
+		if err := s.ValidateJobSpec(conn, jobSpec, scope); err != nil {
+			var verrs ejson.ValidationErrors
+
+			if !errors.As(err, &verrs) {
+				return fmt.Errorf("invalid job specification %d: %w",
+					i+1, err)
+			}
+
+			for _, verr := range verrs {
+				verr.Pointer.Prepend(pointer...)
+			}
+
+			validationErrors = append(validationErrors, verrs...)
+		}
+
+		// Jobs cannot reference identities which are about to be deleted
+		if identityNames != nil {
+			for _, name := range jobSpec.IdentityNames() {
+				if _, found := identityNames[name]; !found {
+					addError(pointer, "unsynchronized_identity",
+						"identity %q is not part of the synchronized "+
+							"identities", name)
+				}
+			}
+		}
+	}
+
+	if validationErrors != nil {
+		return fmt.Errorf("invalid project specification: %w",
+			validationErrors)
+	}
+
+	return nil
+}
+
+func (s *Service) planProjectSync(conn pg.Conn, state *projectSyncState, scope eventline.Scope) error {
+	spec := state.Spec
+	plan := state.Plan
+
+	// Jobs
+	jobNames := make(map[string]struct{})
+
+	for _, jobSpec := range spec.Jobs {
+		jobNames[jobSpec.Name] = struct{}{}
+
+		job, found := state.Jobs[jobSpec.Name]
+		if !found {
+			plan.AddChange(eventline.ProjectSyncOperationCreate,
+				eventline.ProjectSyncObjectTypeJob, jobSpec.Name)
+			state.JobSpecsToDeploy = append(state.JobSpecsToDeploy, jobSpec)
+			continue
+		}
+
+		equal, err := s.jobSpecsEqual(conn, job.Spec, jobSpec, scope)
+		if err != nil {
+			return err
+		}
+
+		if !equal {
+			plan.AddChange(eventline.ProjectSyncOperationUpdate,
+				eventline.ProjectSyncObjectTypeJob, jobSpec.Name)
+			state.JobSpecsToDeploy = append(state.JobSpecsToDeploy, jobSpec)
+		}
+	}
+
+	for _, name := range utils.SortedKeys(state.Jobs) {
+		if _, found := jobNames[name]; !found {
+			plan.AddChange(eventline.ProjectSyncOperationDelete,
+				eventline.ProjectSyncObjectTypeJob, name)
+			state.JobsToDelete = append(state.JobsToDelete, state.Jobs[name])
+		}
+	}
+
+	// Identities
+	if identityNames := spec.IdentityNames(); identityNames != nil {
+		for _, name := range utils.SortedKeys(state.Identities) {
+			if _, found := identityNames[name]; found {
+				continue
+			}
+
+			identity := state.Identities[name]
+
+			plan.AddChange(eventline.ProjectSyncOperationDelete,
+				eventline.ProjectSyncObjectTypeIdentity, name)
+			state.IdentitiesToDelete = append(state.IdentitiesToDelete,
+				identity)
+		}
+	}
+
+	// Project settings
+	if spec.ProjectSettings != nil {
+		projectId := scope.(*eventline.ProjectScope).ProjectId
+
+		var settings eventline.ProjectSettings
+		if err := settings.Load(conn, projectId); err != nil {
+			return fmt.Errorf("cannot load project settings: %w", err)
+		}
+
+		newSettings := spec.ProjectSettings.Apply(&settings)
+
+		if newSettings != settings {
+			plan.AddChange(eventline.ProjectSyncOperationUpdate,
+				eventline.ProjectSyncObjectTypeProjectSettings, "")
+			state.ProjectSettings = &newSettings
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) applyProjectSync(conn pg.Conn, state *projectSyncState, accountId *uuid.UUID, scope eventline.Scope) (bool, error) {
+	var subscriptionsCreatedOrUpdated bool
+
+	for _, job := range state.JobsToDelete {
+		if err := s.DeleteJob(conn, job, scope); err != nil {
+			return false, fmt.Errorf("cannot delete job %q: %w",
+				job.Spec.Name, err)
+		}
+	}
+
+	origin := eventline.JobVersionOrigin{
+		Source:    eventline.JobVersionSourceDeployment,
+		AccountId: accountId,
+	}
+
+	for _, spec := range state.JobSpecsToDeploy {
+		_, subscriptionCreatedOrUpdated, err := s.CreateOrUpdateJob(conn,
+			spec, &origin, scope)
+		if err != nil {
+			return false, fmt.Errorf("cannot create or update job %q: %w",
+				spec.Name, err)
+		}
+
+		if subscriptionCreatedOrUpdated {
+			subscriptionsCreatedOrUpdated = true
+		}
+	}
+
+	for _, identity := range state.IdentitiesToDelete {
+		if err := identity.Delete(conn); err != nil {
+			return false, fmt.Errorf("cannot delete identity %q: %w",
+				identity.Name, err)
+		}
+	}
+
+	if state.ProjectSettings != nil {
+		if err := state.ProjectSettings.Update(conn); err != nil {
+			return false, fmt.Errorf("cannot update project settings: %w",
+				err)
+		}
+	}
+
+	return subscriptionsCreatedOrUpdated, nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.n16f.net/service/pkg/pg"
+)
+
+func TestSyncProject(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	project := createTestProject(t, "")
+	scope := eventline.NewProjectScope(project.Id)
+
+	sync := func() *eventline.ProjectSyncPlan {
+		var jobSpec eventline.JobSpec
+		require.NoError(jobSpec.ParseYAML([]byte(`
+name: "build"
+parameters:
+  - name: "version"
+    type: "string"
+    default: "20"
+environment:
+  VERSION: "${{ .parameters.version }}"
+steps:
+  - command:
+      name: "make"
+`)))
+
+		spec := eventline.ProjectSyncSpec{
+			Jobs: eventline.JobSpecs{&jobSpec},
+		}
+
+		var plan *eventline.ProjectSyncPlan
+		err := testService.Pg.WithTx(func(conn pg.Conn) (err error) {
+			plan, _, err = testService.SyncProject(conn, &spec, false, nil,
+				scope)
+			return
+		})
+		require.NoError(err)
+
+		return plan
+	}
+
+	plan := sync()
+	if assert.Len(plan.Changes, 1) {
+		assert.Equal(eventline.ProjectSyncOperationCreate,
+			plan.Changes[0].Operation)
+	}
+
+	// Synchronizing the same specification again must not change anything,
+	// even though the stored job specification contains default values.
+	plan = sync()
+	assert.Empty(plan.Changes)
+}
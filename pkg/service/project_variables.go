@@ -0,0 +1,89 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/service/pkg/pg"
+)
+
+// ProjectVariableUpdate is used in the project configuration page to modify
+// existing variables. The value is not modified if it is not set, so that
+// secret values do not have to be sent back to the browser.
+type ProjectVariableUpdate struct {
+	Value  *string `json:"value,omitempty"`
+	Delete bool    `json:"delete,omitempty"`
+}
+
+func (s *Service) SetProjectVariable(conn pg.Conn, nv *eventline.NewProjectVariable, scope eventline.Scope) (*eventline.ProjectVariable, error) {
+	projectId := scope.(*eventline.ProjectScope).ProjectId
+
+	now := time.Now().UTC()
+
+	variable := eventline.ProjectVariable{
+		ProjectId:    projectId,
+		Name:         nv.Name,
+		CreationTime: now,
+		UpdateTime:   now,
+		Secret:       nv.Secret,
+		Value:        nv.Value,
+	}
+
+	if err := variable.Upsert(conn); err != nil {
+		return nil, fmt.Errorf("cannot upsert project variable: %w", err)
+	}
+
+	return &variable, nil
+}
+
+func (s *Service) DeleteProjectVariable(conn pg.Conn, name string, scope eventline.Scope) error {
+	var variable eventline.ProjectVariable
+	if err := variable.LoadForUpdate(conn, name, scope); err != nil {
+		return fmt.Errorf("cannot load project variable: %w", err)
+	}
+
+	if err := variable.Delete(conn); err != nil {
+		return fmt.Errorf("cannot delete project variable: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) updateProjectVariables(conn pg.Conn, updates map[string]*ProjectVariableUpdate, nv *eventline.NewProjectVariable, scope eventline.Scope) error {
+	for name, update := range updates {
+		var variable eventline.ProjectVariable
+		if err := variable.LoadForUpdate(conn, name, scope); err != nil {
+			return fmt.Errorf("cannot load project variable: %w", err)
+		}
+
+		if update.Delete {
+			if err := variable.Delete(conn); err != nil {
+				return fmt.Errorf("cannot delete project variable %q: %w",
+					name, err)
+			}
+
+			continue
+		}
+
+		if update.Value == nil || *update.Value == variable.Value {
+			continue
+		}
+
+		variable.Value = *update.Value
+		variable.UpdateTime = time.Now().UTC()
+
+		if err := variable.Upsert(conn); err != nil {
+			return fmt.Errorf("cannot update project variable %q: %w",
+				name, err)
+		}
+	}
+
+	if nv != nil && nv.Name != "" {
+		if _, err := s.SetProjectVariable(conn, nv, scope); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
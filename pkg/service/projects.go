@@ -15,12 +15,23 @@ type ProjectConfiguration struct {
 	Project                     *eventline.NewProject                  `json:"project"`
 	ProjectSettings             *eventline.ProjectSettings             `json:"project_settings"`
 	ProjectNotificationSettings *eventline.ProjectNotificationSettings `json:"project_notification_settings"`
+	GitRepository               *eventline.NewGitRepository            `json:"git_repository,omitempty"` // no uri = no repository
+	ProjectVariables            map[string]*ProjectVariableUpdate      `json:"project_variables,omitempty"`
+	NewProjectVariable          *eventline.NewProjectVariable          `json:"new_project_variable,omitempty"` // no name = no new variable
 }
 
 func (cfg *ProjectConfiguration) ValidateJSON(v *ejson.Validator) {
 	v.CheckObject("project", cfg.Project)
 	v.CheckObject("project_settings", cfg.ProjectSettings)
 	v.CheckObject("project_notification_settings", cfg.ProjectNotificationSettings)
+
+	if cfg.GitRepository != nil && cfg.GitRepository.URI != "" {
+		v.CheckObject("git_repository", cfg.GitRepository)
+	}
+
+	if cfg.NewProjectVariable != nil && cfg.NewProjectVariable.Name != "" {
+		v.CheckObject("new_project_variable", cfg.NewProjectVariable)
+	}
 }
 
 type DuplicateProjectNameError struct {
@@ -248,6 +259,32 @@ func (s *Service) UpdateProjectConfiguration(projectId uuid.UUID, cfg *ProjectCo
 				"settings: %w", err)
 		}
 
+		scope := eventline.NewProjectScope(projectId)
+
+		err := s.updateProjectVariables(conn, cfg.ProjectVariables,
+			cfg.NewProjectVariable, scope)
+		if err != nil {
+			return err
+		}
+
+		if cfg.GitRepository != nil {
+			if cfg.GitRepository.URI == "" {
+				err := s.DeleteGitRepository(conn, scope)
+				if err != nil {
+					var unknownGitRepositoryErr *eventline.UnknownGitRepositoryError
+					if !errors.As(err, &unknownGitRepositoryErr) {
+						return err
+					}
+				}
+			} else {
+				_, err := s.UpdateGitRepository(conn, cfg.GitRepository,
+					scope)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
 		return nil
 	})
 }
@@ -355,6 +355,8 @@ func (s *Service) initWorkers() {
 	init("job-execution-gc", NewJobExecutionGC(s), nil)
 	init("job-execution-watcher", NewJobExecutionWatcher(s), nil)
 	init("notification-worker", NewNotificationWorker(s), nil)
+	init("job-execution-report-worker", NewJobExecutionReportWorker(s), nil)
+	init("git-repository-worker", NewGitRepositoryWorker(s), nil)
 	if s.Cfg.SessionRetention > 0 {
 		init("session-gc", NewSessionGC(s), nil)
 	}
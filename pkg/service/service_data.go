@@ -1,10 +1,14 @@
 package service
 
 import (
+	camqp "github.com/exograd/eventline/pkg/connectors/amqp"
 	cdockerhub "github.com/exograd/eventline/pkg/connectors/dockerhub"
 	ceventline "github.com/exograd/eventline/pkg/connectors/eventline"
 	cgeneric "github.com/exograd/eventline/pkg/connectors/generic"
 	cgithub "github.com/exograd/eventline/pkg/connectors/github"
+	cgitlab "github.com/exograd/eventline/pkg/connectors/gitlab"
+	ckafka "github.com/exograd/eventline/pkg/connectors/kafka"
+	cnats "github.com/exograd/eventline/pkg/connectors/nats"
 	cpostgresql "github.com/exograd/eventline/pkg/connectors/postgresql"
 	ctime "github.com/exograd/eventline/pkg/connectors/time"
 	"github.com/exograd/eventline/pkg/eventline"
@@ -25,10 +29,14 @@ type ServiceData struct {
 }
 
 var Connectors = []eventline.Connector{
+	camqp.NewConnector(),
 	cdockerhub.NewConnector(),
 	ceventline.NewConnector(),
 	cgeneric.NewConnector(),
 	cgithub.NewConnector(),
+	cgitlab.NewConnector(),
+	ckafka.NewConnector(),
+	cnats.NewConnector(),
 	cpostgresql.NewConnector(),
 	ctime.NewConnector(),
 }
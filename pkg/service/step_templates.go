@@ -0,0 +1,87 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"go.n16f.net/ejson"
+	"go.n16f.net/service/pkg/pg"
+	"go.n16f.net/uuid"
+)
+
+type StepTemplateInUseError struct {
+	Name string
+}
+
+func (err StepTemplateInUseError) Error() string {
+	return fmt.Sprintf("step template %q is currently being used", err.Name)
+}
+
+// CreateOrUpdateStepTemplate stores a step template and checks that all jobs
+// using it can still be expanded with their arguments. Deployed jobs contain
+// the expanded steps and are not modified, but they must remain deployable
+// with the new template.
+func (s *Service) CreateOrUpdateStepTemplate(conn pg.Conn, spec *eventline.StepTemplateSpec, scope eventline.Scope) (*eventline.StepTemplate, error) {
+	projectId := scope.(*eventline.ProjectScope).ProjectId
+
+	now := time.Now().UTC()
+
+	template := eventline.StepTemplate{
+		Id:           uuid.MustGenerate(uuid.V7),
+		ProjectId:    projectId,
+		CreationTime: now,
+		UpdateTime:   now,
+		Spec:         spec,
+	}
+
+	id, err := template.Upsert(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	template.Id = *id
+
+	var jobs eventline.Jobs
+	if err := jobs.LoadByStepTemplateName(conn, spec.Name, scope); err != nil {
+		return nil, fmt.Errorf("cannot load jobs: %w", err)
+	}
+
+	// Errors in dependent jobs are reported as errors of the template
+	// itself since they are caused by the new template specification.
+	var validationErrors ejson.ValidationErrors
+
+	for _, job := range jobs {
+		if err := spec.CheckJobSpec(job.Spec); err != nil {
+			validationErrors = append(validationErrors,
+				&ejson.ValidationError{
+					Pointer: ejson.Pointer{},
+					Code:    "invalid_dependent_job",
+					Message: fmt.Sprintf("job %q: %v", job.Spec.Name, err),
+				})
+		}
+	}
+
+	if validationErrors != nil {
+		return nil, fmt.Errorf("invalid dependent jobs: %w", validationErrors)
+	}
+
+	return &template, nil
+}
+
+func (s *Service) DeleteStepTemplate(conn pg.Conn, name string, scope eventline.Scope) error {
+	var template eventline.StepTemplate
+
+	if err := template.LoadByNameForUpdate(conn, name, scope); err != nil {
+		return fmt.Errorf("cannot load step template: %w", err)
+	}
+
+	used, err := template.IsUsed(conn, scope)
+	if err != nil {
+		return fmt.Errorf("cannot check step template usage: %w", err)
+	} else if used {
+		return &StepTemplateInUseError{Name: name}
+	}
+
+	return template.Delete(conn, scope)
+}
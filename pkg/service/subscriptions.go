@@ -98,6 +98,18 @@ func (s *Service) ProcessSubscription(conn pg.Conn, sctx *eventline.Subscription
 }
 
 func (s *Service) processInactiveSubscription(conn pg.Conn, sctx *eventline.SubscriptionContext) error {
+	// Identities are pending until they are configured, e.g. placeholders
+	// created when a project is cloned. The subscription stays inactive and
+	// is scheduled again once the identity is ready.
+	identity := sctx.Identity
+	if identity != nil && identity.Status == eventline.IdentityStatusPending {
+		s.Log.Info("postponing subscription %q until identity %q is ready",
+			sctx.Subscription.Id, identity.Name)
+
+		sctx.Subscription.NextUpdateTime = nil
+		return nil
+	}
+
 	c := eventline.GetConnector(sctx.Subscription.Connector)
 
 	if c2, ok := c.(eventline.SubscribableConnector); ok {
@@ -90,6 +90,32 @@ func IdentityConnectorSelect(name string) *web.Select {
 	}
 }
 
+func EventConnectorSelect(name string) *web.Select {
+	options := []web.SelectOption{{Name: "", Label: "All connectors"}}
+
+	for cname, c := range eventline.Connectors {
+		cdef := c.Definition()
+
+		if len(cdef.Events) == 0 {
+			continue
+		}
+
+		options = append(options, web.SelectOption{
+			Name:  cname,
+			Label: cname,
+		})
+	}
+
+	sort.Slice(options[1:], func(i, j int) bool {
+		return options[i+1].Label < options[j+1].Label
+	})
+
+	return &web.Select{
+		Name:    name,
+		Options: options,
+	}
+}
+
 func IdentityTypeSelect(cdef *eventline.ConnectorDef, name string) *web.Select {
 	options := make([]web.SelectOption, len(cdef.Identities))
 
@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	"fmt"
+	"net/url"
 
 	"github.com/exograd/eventline/pkg/eventline"
 	"github.com/exograd/eventline/pkg/utils"
@@ -11,11 +12,21 @@ import (
 	"go.n16f.net/uuid"
 )
 
+// The query parameters of the event search form, kept in pagination links.
+var eventSearchParameters = []string{
+	"connector", "name", "job", "processed", "start", "end", "filter",
+	"failed",
+}
+
 func (s *WebHTTPServer) setupEventRoutes() {
 	s.route("/events", "GET",
 		s.hEventsGET,
 		HTTPRouteOptions{Project: true})
 
+	s.route("/events/replay", "POST",
+		s.hEventsReplayPOST,
+		HTTPRouteOptions{Project: true})
+
 	s.route("/events/id/{id}", "GET",
 		s.hEventsIdGET,
 		HTTPRouteOptions{Project: true})
@@ -36,11 +47,39 @@ func (s *WebHTTPServer) hEventsGET(h *HTTPHandler) {
 		cursor.Order = eventline.OrderDesc
 	}
 
+	pageOptions, err := h.ParseEventPageOptions()
+	if err != nil {
+		return
+	}
+
+	jobName := h.QueryParameter("job")
+
 	var page *eventline.Page
 	var jobNames map[uuid.UUID]string
 
 	err = s.Pg.WithConn(func(conn pg.Conn) (err error) {
-		page, err = eventline.LoadEventPage(conn, cursor, scope)
+		if jobName != "" {
+			var job eventline.Job
+			err = job.LoadByName(conn, jobName, scope)
+			if err != nil {
+				var unknownJobNameErr *eventline.UnknownJobNameError
+
+				if errors.As(err, &unknownJobNameErr) {
+					// An unknown job cannot have any event
+					page = eventline.Events{}.Page(cursor)
+					err = nil
+				} else {
+					err = fmt.Errorf("cannot load job: %w", err)
+				}
+
+				return
+			}
+
+			pageOptions.JobId = &job.Id
+		}
+
+		page, err = eventline.LoadEventPage(conn, *pageOptions, cursor,
+			scope)
 		if err != nil {
 			err = fmt.Errorf("cannot load events: %w", err)
 			return
@@ -49,7 +88,7 @@ func (s *WebHTTPServer) hEventsGET(h *HTTPHandler) {
 		var jobIds []uuid.UUID
 		for _, element := range page.Elements {
 			event := element.(*eventline.Event)
-			jobIds = append(jobIds, event.JobId)
+			jobIds = append(jobIds, event.JobIds...)
 		}
 
 		jobNames, err = eventline.LoadJobNamesById(conn, jobIds)
@@ -65,12 +104,44 @@ func (s *WebHTTPServer) hEventsGET(h *HTTPHandler) {
 		return
 	}
 
+	searchQuery := make(url.Values)
+	for _, name := range eventSearchParameters {
+		for _, value := range h.Request.URL.Query()[name] {
+			if value != "" {
+				searchQuery.Add(name, value)
+			}
+		}
+	}
+
+	page.Query = searchQuery
+
+	connectorSelect := EventConnectorSelect("connector")
+	connectorSelect.SelectedOption = pageOptions.Connector
+
+	processedSelect := &web.Select{
+		Name: "processed",
+		Options: []web.SelectOption{
+			{Name: "", Label: "All events"},
+			{Name: "true", Label: "Processed"},
+			{Name: "false", Label: "Pending"},
+		},
+		SelectedOption: h.QueryParameter("processed"),
+	}
+
 	bodyData := struct {
-		Page     *eventline.Page
-		JobNames map[uuid.UUID]string
+		Page            *eventline.Page
+		JobNames        map[uuid.UUID]string
+		Search          url.Values
+		Searching       bool
+		ConnectorSelect *web.Select
+		ProcessedSelect *web.Select
 	}{
-		Page:     page,
-		JobNames: jobNames,
+		Page:            page,
+		JobNames:        jobNames,
+		Search:          searchQuery,
+		Searching:       len(searchQuery) > 0,
+		ConnectorSelect: connectorSelect,
+		ProcessedSelect: processedSelect,
 	}
 
 	h.ReplyView(200, &web.View{
@@ -90,7 +161,7 @@ func (s *WebHTTPServer) hEventsIdGET(h *HTTPHandler) {
 	}
 
 	var event eventline.Event
-	var job *eventline.Job
+	var jobNames map[uuid.UUID]string
 	var jobExecutions eventline.JobExecutions
 
 	err = s.Pg.WithConn(func(conn pg.Conn) error {
@@ -98,12 +169,14 @@ func (s *WebHTTPServer) hEventsIdGET(h *HTTPHandler) {
 			return fmt.Errorf("cannot load event: %w", err)
 		}
 
-		job = new(eventline.Job)
-		if err := job.Load(conn, event.JobId, scope); err != nil {
-			return fmt.Errorf("cannot load job: %w", err)
+		var err error
+		jobNames, err = eventline.LoadJobNamesById(conn, event.JobIds)
+		if err != nil {
+			return fmt.Errorf("cannot load job names: %w", err)
 		}
 
-		if err := jobExecutions.LoadByEvent(conn, eventId); err != nil {
+		err = jobExecutions.LoadByEvent(conn, eventId, scope)
+		if err != nil {
 			return fmt.Errorf("cannot load job executions: %w", err)
 		}
 
@@ -130,12 +203,12 @@ func (s *WebHTTPServer) hEventsIdGET(h *HTTPHandler) {
 	bodyData := struct {
 		Event         *eventline.Event
 		EventData     string
-		Job           *eventline.Job
+		JobNames      map[uuid.UUID]string
 		JobExecutions eventline.JobExecutions
 	}{
 		Event:         &event,
 		EventData:     string(edata),
-		Job:           job,
+		JobNames:      jobNames,
 		JobExecutions: jobExecutions,
 	}
 
@@ -147,6 +220,32 @@ func (s *WebHTTPServer) hEventsIdGET(h *HTTPHandler) {
 	})
 }
 
+func (s *WebHTTPServer) hEventsReplayPOST(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	var selection eventline.EventReplaySelection
+	if err := h.JSONRequestData(&selection); err != nil {
+		return
+	}
+
+	dryRun := h.HasQueryParameter("dry-run")
+
+	result, err := s.Service.ReplayEvents(&selection, dryRun, scope)
+	if err != nil {
+		var tooManyEventsErr *eventline.TooManyEventsError
+
+		if errors.As(err, &tooManyEventsErr) {
+			h.ReplyError(400, "too_many_events", "%v", err)
+		} else {
+			h.ReplyInternalError(500, "cannot replay events: %v", err)
+		}
+
+		return
+	}
+
+	h.ReplyJSON(200, result)
+}
+
 func (s *WebHTTPServer) hEventsIdReplayPOST(h *HTTPHandler) {
 	scope := h.Context.ProjectScope()
 
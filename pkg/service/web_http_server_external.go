@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	cgithub "github.com/exograd/eventline/pkg/connectors/github"
+	cgitlab "github.com/exograd/eventline/pkg/connectors/gitlab"
 	"github.com/exograd/eventline/pkg/eventline"
 	"github.com/google/go-github/v45/github"
 )
@@ -27,6 +28,10 @@ func (s *WebHTTPServer) setupExternalRoutes() {
 	s.route("/ext/connectors/github/hooks/", "POST",
 		s.hExtConnectorsGithubHooksPOST,
 		HTTPRouteOptions{Public: true})
+
+	s.route("/ext/connectors/gitlab/hooks/", "POST",
+		s.hExtConnectorsGitlabHooksPOST,
+		HTTPRouteOptions{Public: true})
 }
 
 func (s *WebHTTPServer) hExtConnectorsGithubHooksPOST(h *HTTPHandler) {
@@ -49,3 +54,24 @@ func (s *WebHTTPServer) hExtConnectorsGithubHooksPOST(h *HTTPHandler) {
 
 	h.ReplyEmpty(204)
 }
+
+func (s *WebHTTPServer) hExtConnectorsGitlabHooksPOST(h *HTTPHandler) {
+	if eventId := h.Request.Header.Get("X-Gitlab-Event-UUID"); eventId != "" {
+		h.Log.Data["gitlab_event_uuid"] = eventId
+	}
+
+	target := strings.TrimPrefix(h.Request.URL.Path,
+		"/ext/connectors/gitlab/hooks/")
+
+	var params cgitlab.Parameters
+	params.ParseTarget(target)
+
+	c := eventline.GetConnector("gitlab")
+	c2 := c.(*cgitlab.Connector)
+
+	if err := c2.ProcessWebhookRequest(h.Request, &params); err != nil {
+		h.Log.Error("cannot process request: %v", err)
+	}
+
+	h.ReplyEmpty(204)
+}
@@ -90,6 +90,7 @@ func (s *WebHTTPServer) hJobExecutionsIdContentGET(h *HTTPHandler) {
 	var stepExecutions eventline.StepExecutions
 	var stepExecutionOutputs []template.HTML
 	var event *eventline.Event
+	var matrixExecution *eventline.MatrixExecution
 
 	err = s.Pg.WithConn(func(conn pg.Conn) error {
 		if err := jobExecution.Load(conn, jeId, scope); err != nil {
@@ -128,6 +129,14 @@ func (s *WebHTTPServer) hJobExecutionsIdContentGET(h *HTTPHandler) {
 			}
 		}
 
+		if matrixId := jobExecution.MatrixId; matrixId != nil {
+			matrixExecution = new(eventline.MatrixExecution)
+			err := matrixExecution.Load(conn, *matrixId, scope)
+			if err != nil {
+				return fmt.Errorf("cannot load matrix execution: %w", err)
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -147,11 +156,13 @@ func (s *WebHTTPServer) hJobExecutionsIdContentGET(h *HTTPHandler) {
 		StepExecutions       eventline.StepExecutions
 		StepExecutionOutputs []template.HTML
 		Event                *eventline.Event
+		MatrixExecution      *eventline.MatrixExecution
 	}{
 		JobExecution:         &jobExecution,
 		StepExecutions:       stepExecutions,
 		StepExecutionOutputs: stepExecutionOutputs,
 		Event:                event,
+		MatrixExecution:      matrixExecution,
 	}
 
 	content := s.NewTemplate("job_execution_view_content.html", contentData)
@@ -178,7 +189,17 @@ func (s *WebHTTPServer) hJobExecutionsIdRestartPOST(h *HTTPHandler) {
 		return
 	}
 
-	if err := s.RestartJobExecution(h, jeId); err != nil {
+	// The request body is optional; it is only used to provide the values
+	// of secret parameters.
+	var input *eventline.JobExecutionInput
+	if h.Request.ContentLength != 0 {
+		input = &eventline.JobExecutionInput{}
+		if err := h.JSONRequestData(input); err != nil {
+			return
+		}
+	}
+
+	if err := s.RestartJobExecution(h, jeId, input); err != nil {
 		return
 	}
 
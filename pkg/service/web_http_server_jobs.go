@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/exograd/eventline/pkg/eventline"
 	"github.com/exograd/eventline/pkg/utils"
@@ -64,6 +65,18 @@ func (s *WebHTTPServer) setupJobRoutes() {
 		s.hJobsIdDefinitionGET,
 		HTTPRouteOptions{Project: true})
 
+	s.route("/jobs/id/{id}/versions", "GET",
+		s.hJobsIdVersionsGET,
+		HTTPRouteOptions{Project: true})
+
+	s.route("/jobs/id/{id}/versions/{version}", "GET",
+		s.hJobsIdVersionsVersionGET,
+		HTTPRouteOptions{Project: true})
+
+	s.route("/jobs/id/{id}/versions/{version}/rollback", "POST",
+		s.hJobsIdVersionsVersionRollbackPOST,
+		HTTPRouteOptions{Project: true})
+
 	s.route("/jobs/id/{id}/metrics", "GET",
 		s.hJobsIdMetricsGET,
 		HTTPRouteOptions{Project: true})
@@ -395,10 +408,25 @@ func (s *WebHTTPServer) hJobsIdExecuteGET(h *HTTPHandler) {
 		return
 	}
 
+	scope := h.Context.ProjectScope()
+
+	var identityNames []string
+
+	err = s.Pg.WithConn(func(conn pg.Conn) (err error) {
+		identityNames, err = eventline.LoadIdentityNames(conn, scope)
+		return
+	})
+	if err != nil {
+		h.ReplyInternalError(500, "cannot load identity names: %v", err)
+		return
+	}
+
 	bodyData := struct {
-		Job *eventline.Job
+		Job           *eventline.Job
+		IdentityNames []string
 	}{
-		Job: job,
+		Job:           job,
+		IdentityNames: identityNames,
 	}
 
 	breadcrumb := jobBreadcrumb(job)
@@ -476,6 +504,169 @@ func (s *WebHTTPServer) hJobsIdDefinitionGET(h *HTTPHandler) {
 	})
 }
 
+func (s *WebHTTPServer) hJobsIdVersionsGET(h *HTTPHandler) {
+	scope := h.Context.ProjectScope()
+
+	jobId, err := h.IdPathVariable("id")
+	if err != nil {
+		return
+	}
+
+	cursor, err := h.ParseCursor(eventline.JobVersionSorts)
+	if err != nil {
+		return
+	}
+	if cursor.Order == "" {
+		cursor.Order = eventline.OrderDesc
+	}
+
+	job, err := s.LoadJob(h, jobId)
+	if err != nil {
+		return
+	}
+
+	var page *eventline.Page
+
+	err = s.Pg.WithConn(func(conn pg.Conn) (err error) {
+		page, err = eventline.LoadJobVersionPage(conn, jobId, cursor, scope)
+		if err != nil {
+			err = fmt.Errorf("cannot load job versions: %w", err)
+		}
+		return
+	})
+	if err != nil {
+		h.ReplyInternalError(500, "%v", err)
+		return
+	}
+
+	bodyData := struct {
+		Job  *eventline.Job
+		Page *eventline.Page
+	}{
+		Job:  job,
+		Page: page,
+	}
+
+	breadcrumb := jobBreadcrumb(job)
+	breadcrumb.AddEntry(&web.BreadcrumbEntry{Label: "Versions"})
+
+	h.ReplyView(200, &web.View{
+		Title:      "Job versions",
+		Menu:       NewMainMenu("jobs"),
+		Breadcrumb: breadcrumb,
+		Tabs:       jobTabs(job, "versions"),
+		Body:       s.NewTemplate("job_versions.html", bodyData),
+	})
+}
+
+func (s *WebHTTPServer) hJobsIdVersionsVersionGET(h *HTTPHandler) {
+	jobId, err := h.IdPathVariable("id")
+	if err != nil {
+		return
+	}
+
+	version, err := h.IntPathVariable("version")
+	if err != nil {
+		return
+	}
+
+	job, err := s.LoadJob(h, jobId)
+	if err != nil {
+		return
+	}
+
+	jobVersion, err := s.LoadJobVersion(h, jobId, version)
+	if err != nil {
+		return
+	}
+
+	// By default, the version is compared to the previous one
+	compareVersion := version - 1
+
+	if h.HasQueryParameter("compare") {
+		value := h.QueryParameter("compare")
+
+		compareVersion, err = strconv.Atoi(value)
+		if err != nil || compareVersion < 0 {
+			h.ReplyError(400, "invalid_query_parameter",
+				"invalid compare parameter %q", value)
+			return
+		}
+	}
+
+	var compareJobVersion *eventline.JobVersion
+	var compareSpecData []byte
+
+	if compareVersion > 0 {
+		compareJobVersion, err = s.LoadJobVersion(h, jobId, compareVersion)
+		if err != nil {
+			return
+		}
+
+		compareSpecData, err = utils.YAMLEncode(compareJobVersion.Spec)
+		if err != nil {
+			h.ReplyInternalError(500, "cannot encode job specification "+
+				"data: %v", err)
+			return
+		}
+	}
+
+	specData, err := utils.YAMLEncode(jobVersion.Spec)
+	if err != nil {
+		h.ReplyInternalError(500, "cannot encode job specification data: %v",
+			err)
+		return
+	}
+
+	bodyData := struct {
+		Job               *eventline.Job
+		JobVersion        *eventline.JobVersion
+		CompareJobVersion *eventline.JobVersion
+		Diff              []utils.DiffLine
+	}{
+		Job:               job,
+		JobVersion:        jobVersion,
+		CompareJobVersion: compareJobVersion,
+		Diff: utils.DiffLines(string(compareSpecData),
+			string(specData)),
+	}
+
+	breadcrumb := jobBreadcrumb(job)
+	breadcrumb.AddEntry(&web.BreadcrumbEntry{
+		Label: "Versions",
+		URI:   "/jobs/id/" + job.Id.String() + "/versions",
+	})
+	breadcrumb.AddEntry(&web.BreadcrumbEntry{
+		Label: "Version " + strconv.Itoa(version),
+	})
+
+	h.ReplyView(200, &web.View{
+		Title:      "Job version",
+		Menu:       NewMainMenu("jobs"),
+		Breadcrumb: breadcrumb,
+		Tabs:       jobTabs(job, "versions"),
+		Body:       s.NewTemplate("job_version.html", bodyData),
+	})
+}
+
+func (s *WebHTTPServer) hJobsIdVersionsVersionRollbackPOST(h *HTTPHandler) {
+	jobId, err := h.IdPathVariable("id")
+	if err != nil {
+		return
+	}
+
+	version, err := h.IntPathVariable("version")
+	if err != nil {
+		return
+	}
+
+	if _, err := s.RollbackJob(h, jobId, version); err != nil {
+		return
+	}
+
+	h.ReplyJSONLocation(200, "/jobs/id/"+jobId.String()+"/versions", nil)
+}
+
 func (s *WebHTTPServer) hJobsIdMetricsGET(h *HTTPHandler) {
 	jobId, err := h.IdPathVariable("id")
 	if err != nil {
@@ -644,6 +835,13 @@ func jobTabs(job *eventline.Job, selectedTab string) *web.Tabs {
 		URI:   baseURI + "/definition",
 	})
 
+	tabs.AddTab(&web.Tab{
+		Id:    "versions",
+		Icon:  "history",
+		Label: "Versions",
+		URI:   baseURI + "/versions",
+	})
+
 	tabs.AddTab(&web.Tab{
 		Id:    "metrics",
 		Icon:  "chart-timeline-variant",
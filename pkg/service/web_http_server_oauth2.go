@@ -104,6 +104,13 @@ func (s *WebHTTPServer) processOAuth2Request(h *HTTPHandler) {
 			return fmt.Errorf("cannot update identity %q: %w", identityId, err)
 		}
 
+		if identity.Status == eventline.IdentityStatusReady {
+			err := eventline.ScheduleIdentitySubscriptions(conn, identity.Id)
+			if err != nil {
+				return fmt.Errorf("cannot schedule subscriptions: %w", err)
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -111,6 +118,8 @@ func (s *WebHTTPServer) processOAuth2Request(h *HTTPHandler) {
 		return
 	}
 
+	s.Service.wakeUpSubscriptionWorker()
+
 	// Redirect the client
 	h.ReplyRedirect(303, "/identities")
 }
@@ -154,6 +154,8 @@ func (s *WebHTTPServer) hProjectsIdConfigurationGET(h *HTTPHandler) {
 	var project eventline.Project
 	var projectSettings eventline.ProjectSettings
 	var projectNotificationSettings eventline.ProjectNotificationSettings
+	var gitRepository *eventline.GitRepository
+	var projectVariables eventline.ProjectVariables
 
 	err = s.Pg.WithConn(func(conn pg.Conn) error {
 		if err := project.Load(conn, projectId); err != nil {
@@ -170,6 +172,21 @@ func (s *WebHTTPServer) hProjectsIdConfigurationGET(h *HTTPHandler) {
 				err)
 		}
 
+		var repo eventline.GitRepository
+		if err := repo.Load(conn, projectId); err == nil {
+			gitRepository = &repo
+		} else {
+			var unknownGitRepositoryErr *eventline.UnknownGitRepositoryError
+			if !errors.As(err, &unknownGitRepositoryErr) {
+				return fmt.Errorf("cannot load git repository: %w", err)
+			}
+		}
+
+		scope := eventline.NewProjectScope(projectId)
+		if err := projectVariables.LoadAll(conn, scope); err != nil {
+			return fmt.Errorf("cannot load project variables: %w", err)
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -191,10 +208,14 @@ func (s *WebHTTPServer) hProjectsIdConfigurationGET(h *HTTPHandler) {
 		Project                     *eventline.Project
 		ProjectSettings             *eventline.ProjectSettings
 		ProjectNotificationSettings *eventline.ProjectNotificationSettings
+		GitRepository               *eventline.GitRepository
+		ProjectVariables            eventline.ProjectVariables
 	}{
 		Project:                     &project,
 		ProjectSettings:             &projectSettings,
 		ProjectNotificationSettings: &projectNotificationSettings,
+		GitRepository:               gitRepository,
+		ProjectVariables:            projectVariables,
 	}
 
 	h.ReplyView(200, &web.View{
@@ -249,6 +270,12 @@ func (s *WebHTTPServer) hProjectsIdConfigurationPOST(h *HTTPHandler) {
 		return
 	}
 
+	if cfg.GitRepository != nil && cfg.GitRepository.URI != "" {
+		if w := s.Service.FindWorker("git-repository-worker"); w != nil {
+			w.WakeUp()
+		}
+	}
+
 	h.ReplyJSONLocation(200, "/projects", nil)
 }
 
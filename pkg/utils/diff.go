@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"strings"
+)
+
+type DiffOp string
+
+const (
+	DiffOpEqual  DiffOp = "equal"
+	DiffOpInsert DiffOp = "insert"
+	DiffOpDelete DiffOp = "delete"
+)
+
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// DiffLines computes a line based diff between two texts using the longest
+// common subsequence. It is quadratic in the number of lines, which is fine
+// for small documents such as job specifications.
+func DiffLines(s1, s2 string) []DiffLine {
+	lines1 := splitLines(s1)
+	lines2 := splitLines(s2)
+
+	n1 := len(lines1)
+	n2 := len(lines2)
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// lines1[i:] and lines2[j:].
+	lcs := make([][]int, n1+1)
+	for i := range lcs {
+		lcs[i] = make([]int, n2+1)
+	}
+
+	for i := n1 - 1; i >= 0; i-- {
+		for j := n2 - 1; j >= 0; j-- {
+			if lines1[i] == lines2[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []DiffLine
+
+	i, j := 0, 0
+	for i < n1 && j < n2 {
+		switch {
+		case lines1[i] == lines2[j]:
+			diff = append(diff, DiffLine{Op: DiffOpEqual, Text: lines1[i]})
+			i++
+			j++
+
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Op: DiffOpDelete, Text: lines1[i]})
+			i++
+
+		default:
+			diff = append(diff, DiffLine{Op: DiffOpInsert, Text: lines2[j]})
+			j++
+		}
+	}
+
+	for ; i < n1; i++ {
+		diff = append(diff, DiffLine{Op: DiffOpDelete, Text: lines1[i]})
+	}
+
+	for ; j < n2; j++ {
+		diff = append(diff, DiffLine{Op: DiffOpInsert, Text: lines2[j]})
+	}
+
+	return diff
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Empty(DiffLines("", ""))
+
+	assert.Equal([]DiffLine{
+		{DiffOpInsert, "a"},
+		{DiffOpInsert, "b"},
+	}, DiffLines("", "a\nb\n"))
+
+	assert.Equal([]DiffLine{
+		{DiffOpEqual, "a"},
+		{DiffOpDelete, "b"},
+		{DiffOpInsert, "x"},
+		{DiffOpEqual, "c"},
+		{DiffOpInsert, "d"},
+	}, DiffLines("a\nb\nc\n", "a\nx\nc\nd\n"))
+}
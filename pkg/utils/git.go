@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GitURIProtocol returns the protocol git uses for a repository URI: the
+// scheme of URLs, the name of the remote helper for "<helper>::<address>"
+// URIs, "ssh" for scp-like addresses such as "git@github.com:org/repo.git"
+// and "file" for local paths.
+func GitURIProtocol(uri string) string {
+	if helper, _, found := strings.Cut(uri, "::"); found &&
+		!strings.ContainsAny(helper, ":/") {
+		return strings.ToLower(helper)
+	}
+
+	if scheme, _, found := strings.Cut(uri, "://"); found {
+		return strings.ToLower(scheme)
+	}
+
+	// Git uses the same rule: an address is scp-like if there is a colon
+	// before the first slash.
+	colon := strings.Index(uri, ":")
+	slash := strings.Index(uri, "/")
+	if colon > 0 && (slash == -1 || colon < slash) {
+		return "ssh"
+	}
+
+	return "file"
+}
+
+// GitClone clones a single branch of a Git repository in a directory which
+// must not exist or be empty, and returns the hash of the last commit of the
+// branch. If protocols is not nil, git is only allowed to use these
+// protocols, including for submodules and redirections.
+func GitClone(ctx context.Context, uri, branch, dirPath string, protocols []string) (string, error) {
+	var env []string
+	if protocols != nil {
+		env = append(env, "GIT_ALLOW_PROTOCOL="+strings.Join(protocols, ":"))
+	}
+
+	_, err := runGitEnv(ctx, "", env, "clone", "--quiet", "--depth", "1",
+		"--single-branch", "--branch", branch, "--", uri, dirPath)
+	if err != nil {
+		return "", err
+	}
+
+	commit, err := runGit(ctx, dirPath, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(commit), nil
+}
+
+func runGit(ctx context.Context, dirPath string, args ...string) (string, error) {
+	return runGitEnv(ctx, dirPath, nil, args...)
+}
+
+func runGitEnv(ctx context.Context, dirPath string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dirPath
+
+	// Never ask for credentials, the command would block forever
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Env = append(cmd.Env, env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return "", fmt.Errorf("git %s: %w", args[0], err)
+		}
+
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+	}
+
+	return stdout.String(), nil
+}
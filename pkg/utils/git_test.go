@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ctx := context.Background()
+	dirPath := t.TempDir()
+
+	git := func(dirPath string, args ...string) string {
+		args = append([]string{"-c", "user.name=test",
+			"-c", "user.email=test@example.com"}, args...)
+
+		output, err := runGit(ctx, dirPath, args...)
+		require.NoError(err)
+
+		return output
+	}
+
+	// Bare repository with a single commit on the main branch
+	repoPath := filepath.Join(dirPath, "repo.git")
+	git("", "init", "--quiet", "--bare", "--initial-branch", "main",
+		repoPath)
+
+	workPath := filepath.Join(dirPath, "work")
+	git("", "init", "--quiet", "--initial-branch", "main", workPath)
+
+	err := os.WriteFile(filepath.Join(workPath, "job.yaml"),
+		[]byte("name: test\n"), 0644)
+	require.NoError(err)
+
+	git(workPath, "add", "job.yaml")
+	git(workPath, "commit", "--quiet", "-m", "initial commit")
+	git(workPath, "push", "--quiet", repoPath, "main")
+
+	expectedCommit := git(workPath, "rev-parse", "HEAD")
+
+	// Clone
+	clonePath := filepath.Join(dirPath, "clone")
+
+	commit, err := GitClone(ctx, repoPath, "main", clonePath, nil)
+	require.NoError(err)
+	assert.Equal(expectedCommit[:40], commit)
+	assert.FileExists(filepath.Join(clonePath, "job.yaml"))
+
+	// Unknown branch
+	_, err = GitClone(ctx, repoPath, "unknown", filepath.Join(dirPath, "x"),
+		nil)
+	assert.Error(err)
+
+	// Forbidden protocol
+	_, err = GitClone(ctx, repoPath, "main", filepath.Join(dirPath, "y"),
+		[]string{"https", "ssh"})
+	assert.Error(err)
+}
+
+func TestGitURIProtocol(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("https", GitURIProtocol("https://github.com/org/repo.git"))
+	assert.Equal("ssh", GitURIProtocol("ssh://git@github.com/org/repo.git"))
+	assert.Equal("ssh", GitURIProtocol("git@github.com:org/repo.git"))
+	assert.Equal("file", GitURIProtocol("/var/lib/repo.git"))
+	assert.Equal("file", GitURIProtocol("./repo:1"))
+	assert.Equal("file", GitURIProtocol("file:///var/lib/repo.git"))
+	assert.Equal("ext", GitURIProtocol("ext::sh -c touch% /tmp/x"))
+}